@@ -0,0 +1,115 @@
+package gographql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// SessionAffinity configures sticky routing for gateways running several
+// replicas: the affinity header or cookie set by the gateway is captured
+// into the AffinitySession of the request context, and replayed on every
+// later request made with that context, subscriptions included.
+type SessionAffinity struct {
+	// Header is the response header carrying the affinity token. It is
+	// sent back on the same header.
+	Header string
+	// Cookie is the name of the affinity cookie, such as AWSALB or
+	// INGRESSCOOKIE.
+	Cookie string
+}
+
+// WithSessionAffinity enables sticky routing within affinity sessions.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithSessionAffinity(gographql.SessionAffinity{
+//	    Cookie: "INGRESSCOOKIE",
+//	}))
+//	ctx = gographql.ContextWithAffinitySession(ctx)
+//	client.Run(ctx, login, nil)   // cookie captured
+//	client.Run(ctx, query, &resp) // routed to the same replica
+func WithSessionAffinity(cfg SessionAffinity) ClientOption {
+	return func(client *Client) {
+		client.affinity = &cfg
+	}
+}
+
+// AffinitySession holds the latest affinity token and cookie seen within
+// a logical session. It is safe for concurrent use.
+type AffinitySession struct {
+	mu     sync.Mutex
+	token  string
+	cookie string
+}
+
+// Token returns the latest captured affinity header value.
+func (s *AffinitySession) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// Cookie returns the latest captured affinity cookie value.
+func (s *AffinitySession) Cookie() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cookie
+}
+
+// Reset forgets the captured values, so the next request may be routed
+// to any replica.
+func (s *AffinitySession) Reset() {
+	s.mu.Lock()
+	s.token, s.cookie = "", ""
+	s.mu.Unlock()
+}
+
+type affinitySessionKey struct{}
+
+// ContextWithAffinitySession returns a copy of ctx carrying a new, empty
+// AffinitySession.
+func ContextWithAffinitySession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, affinitySessionKey{}, &AffinitySession{})
+}
+
+// AffinitySessionFromContext returns the session carried by ctx.
+func AffinitySessionFromContext(ctx context.Context) (*AffinitySession, bool) {
+	s, ok := ctx.Value(affinitySessionKey{}).(*AffinitySession)
+	return s, ok
+}
+
+func (cfg *SessionAffinity) attach(ctx context.Context, r *http.Request) {
+	session, ok := AffinitySessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	session.mu.Lock()
+	token, cookie := session.token, session.cookie
+	session.mu.Unlock()
+	if cfg.Header != "" && token != "" {
+		r.Header.Set(cfg.Header, token)
+	}
+	if cfg.Cookie != "" && cookie != "" {
+		r.AddCookie(&http.Cookie{Name: cfg.Cookie, Value: cookie})
+	}
+}
+
+func (cfg *SessionAffinity) capture(ctx context.Context, res *http.Response) {
+	session, ok := AffinitySessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if cfg.Header != "" {
+		if token := res.Header.Get(cfg.Header); token != "" {
+			session.token = token
+		}
+	}
+	if cfg.Cookie != "" {
+		for _, cookie := range res.Cookies() {
+			if cookie.Name == cfg.Cookie && cookie.Value != "" {
+				session.cookie = cookie.Value
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSessionAffinity(t *testing.T) {
+	is := is.New(t)
+	var cookies, tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, _ := r.Cookie("INGRESSCOOKIE")
+		if cookie == nil {
+			cookies = append(cookies, "")
+			http.SetCookie(w, &http.Cookie{Name: "INGRESSCOOKIE", Value: "replica-2"})
+			w.Header().Set("X-Route", "r2")
+		} else {
+			cookies = append(cookies, cookie.Value)
+		}
+		tokens = append(tokens, r.Header.Get("X-Route"))
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithSessionAffinity(SessionAffinity{Header: "X-Route", Cookie: "INGRESSCOOKIE"}))
+	session := ContextWithAffinitySession(ctx)
+	is.NoErr(client.Run(session, NewRequest("{ ok }"), nil))
+	is.NoErr(client.Run(session, NewRequest("{ ok }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("{ ok }"), nil)) // no session
+
+	s, ok := AffinitySessionFromContext(session)
+	is.True(ok)
+	is.Equal(s.Cookie(), "replica-2")
+	is.Equal(s.Token(), "r2")
+	s.Reset()
+	is.NoErr(client.Run(session, NewRequest("{ ok }"), nil))
+
+	is.Equal(cookies, []string{"", "replica-2", "", ""})
+	is.Equal(tokens, []string{"", "r2", "", ""})
+}
@@ -0,0 +1,223 @@
+package gographql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AppSyncAuth returns the authorization headers of an AWS AppSync request
+// to endpoint with body. It is called when connecting, with the endpoint
+// ending in /connect, and for every subscription.
+type AppSyncAuth func(ctx context.Context, endpoint string, body []byte) (map[string]string, error)
+
+// AWSCredentials are the credentials used to sign AppSync requests in the
+// IAM auth mode.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// AppSyncAPIKey authorizes AppSync subscriptions with an API key.
+func AppSyncAPIKey(key string) AppSyncAuth {
+	return func(ctx context.Context, endpoint string, body []byte) (map[string]string, error) {
+		host, err := appSyncHost(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"host": host, "x-api-key": key}, nil
+	}
+}
+
+// AppSyncToken authorizes AppSync subscriptions with the token returned
+// by token, such as a Cognito user pools or OpenID Connect JWT.
+func AppSyncToken(token func(ctx context.Context) (string, error)) AppSyncAuth {
+	return func(ctx context.Context, endpoint string, body []byte) (map[string]string, error) {
+		host, err := appSyncHost(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		t, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"host": host, "Authorization": t}, nil
+	}
+}
+
+// AppSyncIAM authorizes AppSync subscriptions with AWS Signature Version 4
+// signatures made with the credentials returned by creds, which are
+// called for every signature so they can be refreshed.
+func AppSyncIAM(region string, creds func(ctx context.Context) (AWSCredentials, error)) AppSyncAuth {
+	return func(ctx context.Context, endpoint string, body []byte) (map[string]string, error) {
+		c, err := creds(ctx)
+		if err != nil {
+			return nil, err
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		headers := map[string]string{
+			"accept":           "application/json, text/javascript",
+			"content-encoding": "amz-1.0",
+			"content-type":     "application/json; charset=UTF-8",
+			"host":             u.Host,
+		}
+		if c.SessionToken != "" {
+			headers["x-amz-security-token"] = c.SessionToken
+		}
+		signV4(headers, u, body, region, "appsync", c, time.Now())
+		return headers, nil
+	}
+}
+
+// WithAppSync makes Subscribe speak the AWS AppSync realtime protocol,
+// authorizing connections and subscriptions with auth. The realtime
+// endpoint is derived from the client endpoint: the appsync-realtime-api
+// host for AppSync hosts, and the /realtime path for custom domains.
+//
+//	client := gographql.NewClient("https://xxx.appsync-api.eu-west-1.amazonaws.com/graphql",
+//	    gographql.WithAppSync(gographql.AppSyncAPIKey(apiKey)))
+//	payloads, errs, err := client.Subscribe(ctx, req)
+func WithAppSync(auth AppSyncAuth) ClientOption {
+	return func(client *Client) {
+		client.appSync = auth
+	}
+}
+
+// appSyncProtocol is the AppSync realtime protocol, announced as
+// graphql-ws but differing from subscriptions-transport-ws in its
+// handshake and start payload.
+var appSyncProtocol = &subProtocol{subscribe: "start", next: "data", stop: "stop"}
+
+// appSyncRealtimeURL returns the realtime endpoint of the AppSync API at
+// endpoint.
+func appSyncRealtimeURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "ws"
+	} else {
+		u.Scheme = "wss"
+	}
+	if strings.Contains(u.Host, ".appsync-api.") {
+		u.Host = strings.Replace(u.Host, ".appsync-api.", ".appsync-realtime-api.", 1)
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/realtime"
+	}
+	return u.String(), nil
+}
+
+// appSyncConnectURL adds the authorization of the connection to the
+// realtime URL, as AppSync expects it in the query string.
+func (c *Client) appSyncConnectURL(ctx context.Context, req *Request, realtimeURL string) (string, error) {
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	headers, err := c.appSync(ctx, strings.TrimSuffix(endpoint, "/")+"/connect", []byte("{}"))
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(realtimeURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("header", base64.StdEncoding.EncodeToString(b))
+	q.Set("payload", base64.StdEncoding.EncodeToString([]byte("{}")))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// appSyncStart returns the payload of the start message of req, whose
+// GraphQL request is data.
+func (c *Client) appSyncStart(ctx context.Context, req *Request, data []byte) (json.RawMessage, error) {
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := c.appSync(ctx, endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{
+		"data":       string(data),
+		"extensions": map[string]interface{}{"authorization": headers},
+	}
+	return json.Marshal(payload)
+}
+
+func appSyncHost(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", errors.New("appsync: endpoint has no host")
+	}
+	return u.Host, nil
+}
+
+// signV4 adds the x-amz-date and Authorization headers signing a POST
+// request to u with body and the other headers, which must have lower
+// case names.
+func signV4(headers map[string]string, u *url.URL, body []byte, region, service string, creds AWSCredentials, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	headers["x-amz-date"] = amzDate
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical.WriteString("POST\n" + path + "\n" + u.RawQuery + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + sha256Hex(body))
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical.String()))
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	headers["Authorization"] = fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature)
+}
+
+func sha256Hex(b []byte) string {
+	return hexSHA256(defaultCrypto, b)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := defaultCrypto.HMACSHA256(key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
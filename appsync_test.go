@@ -0,0 +1,96 @@
+package gographql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestAppSyncSubscribe(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{SubscriptionsTransportWS}, func(r *http.Request, conn *wsConn) {
+		is.Equal(r.URL.Path, "/graphql/realtime")
+		is.Equal(r.URL.Query().Get("payload"), "e30=")
+		b, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("header"))
+		is.NoErr(err)
+		var header map[string]string
+		is.NoErr(json.Unmarshal(b, &header))
+		is.Equal(header["x-api-key"], "da2-key")
+		is.Equal(header["host"], r.Host)
+
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(msg.Type, "connection_init")
+		is.Equal(len(msg.Payload), 0)
+		writeWSMessage(conn, `{"type":"connection_ack","payload":{"connectionTimeoutMs":300000}}`)
+		writeWSMessage(conn, `{"type":"ka"}`)
+		msg, err = readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(msg.Type, "start")
+		var start struct {
+			Data       string
+			Extensions struct {
+				Authorization map[string]string
+			}
+		}
+		is.NoErr(json.Unmarshal(msg.Payload, &start))
+		is.Equal(start.Data, `{"query":"subscription { onCreate { id } }"}`)
+		is.Equal(start.Extensions.Authorization["x-api-key"], "da2-key")
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"start_ack"}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"data","payload":{"data":{"onCreate":{"id":"1"}}}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"error","payload":{"errors":[{"errorType":"Unauthorized","message":"expired"}]}}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL+"/graphql", WithAppSync(AppSyncAPIKey("da2-key")))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { onCreate { id } }"))
+	is.NoErr(err)
+	p := <-payloads
+	is.Equal(string(p.Data), `{"onCreate":{"id":"1"}}`)
+	_, ok := <-payloads
+	is.True(!ok)
+	is.Equal((<-errs).Error(), "graphql: expired")
+}
+
+func TestAppSyncRealtimeURL(t *testing.T) {
+	is := is.New(t)
+	u, err := appSyncRealtimeURL("https://abc.appsync-api.eu-west-1.amazonaws.com/graphql")
+	is.NoErr(err)
+	is.Equal(u, "wss://abc.appsync-realtime-api.eu-west-1.amazonaws.com/graphql")
+	u, err = appSyncRealtimeURL("https://api.example.com/graphql")
+	is.NoErr(err)
+	is.Equal(u, "wss://api.example.com/graphql/realtime")
+}
+
+func TestSignV4(t *testing.T) {
+	is := is.New(t)
+	// post-vanilla from the AWS Signature Version 4 test suite
+	u, _ := url.Parse("https://example.amazonaws.com/")
+	headers := map[string]string{"host": "example.amazonaws.com"}
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(headers, u, nil, "us-east-1", "service", creds, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	is.Equal(headers["x-amz-date"], "20150830T123600Z")
+	is.Equal(headers["Authorization"], "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b")
+}
+
+func TestAppSyncIAM(t *testing.T) {
+	is := is.New(t)
+	auth := AppSyncIAM("eu-west-1", func(ctx context.Context) (AWSCredentials, error) {
+		return AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, nil
+	})
+	headers, err := auth(context.Background(), "https://abc.appsync-api.eu-west-1.amazonaws.com/graphql/connect", []byte("{}"))
+	is.NoErr(err)
+	is.Equal(headers["host"], "abc.appsync-api.eu-west-1.amazonaws.com")
+	is.Equal(headers["x-amz-security-token"], "session")
+	is.True(headers["x-amz-date"] != "")
+	is.True(len(headers["Authorization"]) > 0)
+}
@@ -0,0 +1,94 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Apollo Automatic Persisted Queries error messages and codes.
+const (
+	persistedQueryNotFound     = "PersistedQueryNotFound"
+	persistedQueryNotSupported = "PersistedQueryNotSupported"
+)
+
+// persistedQueries is the state of Automatic Persisted Queries.
+type persistedQueries struct {
+	// unsupported is set once the server said it does not support them.
+	unsupported atomic.Bool
+}
+
+// WithAutomaticPersistedQueries enables Apollo Automatic Persisted
+// Queries with the JSON transport: requests carry the SHA-256 hash of the
+// query instead of the query itself, which is only sent when the server
+// does not know the hash yet. If the server does not support persisted
+// queries, the client goes back to sending queries.
+func WithAutomaticPersistedQueries() ClientOption {
+	return func(client *Client) {
+		client.apq = &persistedQueries{}
+	}
+}
+
+// runPersisted sends req by hash, and again with the query if the server
+// does not know it.
+func (c *Client) runPersisted(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	body := struct {
+		Query      string                 `json:"query,omitempty"`
+		Variables  map[string]interface{} `json:"variables"`
+		Extensions map[string]interface{} `json:"extensions"`
+	}{
+		Variables: req.vars,
+		Extensions: map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hexSHA256(c.crypto, []byte(req.q))},
+		},
+	}
+	buf := getBodyBuffer()
+	defer buf.release()
+	if err := c.encodeBody(&buf.Buffer, body); err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	err := c.postJSON(ctx, req, buf, resp, meta)
+	switch persistedQueryError(err) {
+	case persistedQueryNotFound:
+	case persistedQueryNotSupported:
+		c.apq.unsupported.Store(true)
+	default:
+		if c.fallBack(ctx, req, transportAPQ, 0, err) {
+			return c.postQuery(ctx, req, resp, meta)
+		}
+		if err == nil {
+			c.transportWorked(ctx, req, transportAPQ)
+		}
+		return err
+	}
+	if c.debug(ctx) {
+		c.log.Debugf("persisted query not found, sending query: %s", req.q)
+	}
+	c.emit(Event{Type: EventRetry, Err: err})
+	body.Query = req.q
+	// a new buffer, as the first request body may still be read
+	withQuery := getBodyBuffer()
+	defer withQuery.release()
+	if err := c.encodeBody(&withQuery.Buffer, body); err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	return c.postJSON(ctx, req, withQuery, resp, meta)
+}
+
+// persistedQueryError returns the persisted query error reported in err,
+// if any.
+func persistedQueryError(err error) string {
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		return ""
+	}
+	for _, e := range gqlErrs {
+		switch {
+		case e.Message == persistedQueryNotFound || e.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND":
+			return persistedQueryNotFound
+		case e.Message == persistedQueryNotSupported || e.Extensions["code"] == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return persistedQueryNotSupported
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package gographql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type apqBody struct {
+	Query      string
+	Extensions struct {
+		PersistedQuery struct {
+			SHA256Hash string `json:"sha256Hash"`
+		}
+	}
+}
+
+func TestAutomaticPersistedQueries(t *testing.T) {
+	is := is.New(t)
+	known := map[string]string{}
+	var sent []apqBody
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body apqBody
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		sent = append(sent, body)
+		hash := body.Extensions.PersistedQuery.SHA256Hash
+		if body.Query != "" {
+			known[hash] = body.Query
+		}
+		if _, ok := known[hash]; !ok {
+			io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"a":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithAutomaticPersistedQueries())
+	var resp struct{ A string }
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), &resp))
+	is.Equal(resp.A, "ok")
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), &resp))
+
+	sum := sha256.Sum256([]byte("query { a }"))
+	is.Equal(len(sent), 3)
+	is.Equal(sent[0].Query, "") // hash only
+	is.Equal(sent[0].Extensions.PersistedQuery.SHA256Hash, hex.EncodeToString(sum[:]))
+	is.Equal(sent[1].Query, "query { a }") // retried with the query
+	is.Equal(sent[2].Query, "")            // known from now on
+}
+
+func TestAutomaticPersistedQueriesNotSupported(t *testing.T) {
+	is := is.New(t)
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body apqBody
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		queries = append(queries, body.Query)
+		if body.Query == "" {
+			io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotSupported"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"a":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithAutomaticPersistedQueries())
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), nil))
+	is.Equal(queries, []string{"", "query { a }", "query { a }"})
+}
@@ -0,0 +1,212 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BatchError reports the operations of a batch that failed.
+type BatchError struct {
+	// Errors holds the error of each operation in request order, nil for
+	// the operations that succeeded.
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	var msgs []string
+	for i, err := range e.Errors {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("operation %d: %v", i, err))
+		}
+	}
+	return "batch: " + strings.Join(msgs, "; ")
+}
+
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// RunBatch sends reqs as a JSON array in a single HTTP request, as
+// supported by Apollo Server and GraphQL Yoga, and decodes the data of
+// each operation into the response at the same index of resps, which may
+// be nil. The HTTP request carries the headers of every request, which
+// must not set a header to different values, failing with
+// ErrHeaderConflict. The failed operations are reported by a *BatchError;
+// other errors concern the whole batch.
+//
+// The batch goes through the client like a single request, counted by its
+// metrics and journal and limited by the in-flight limit. Each operation
+// is checked by the kill switch and sent by ID when it is a trusted
+// document, and its data goes through the field transforms. The cache,
+// GET and Automatic Persisted Queries do not apply to batches.
+//
+// When Probe found that the server does not support batching, the
+// operations are sent one by one instead, and their failures are still
+// reported by a *BatchError.
+func (c *Client) RunBatch(ctx context.Context, reqs []*Request, resps []interface{}) error {
+	if len(resps) != len(reqs) {
+		return fmt.Errorf("batch: %d requests but %d responses", len(reqs), len(resps))
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	merged := NewRequest("")
+	headerFrom := make(map[string]int)
+	clones := make([]*Request, len(reqs))
+	for i, req := range reqs {
+		req = req.Clone()
+		if len(req.files) > 0 {
+			return fmt.Errorf("batch: operation %d: %w", i, ErrSendFilesPostField)
+		}
+		if err := mergeHeader(merged.Header, headerFrom, req.Header, i); err != nil {
+			return fmt.Errorf("batch: %w", err)
+		}
+		clones[i] = req
+	}
+	if c.lacks(func(caps *Capabilities) bool { return caps.Batching }) {
+		return c.runUnbatched(ctx, clones, resps)
+	}
+	batchErr := &BatchError{Errors: make([]error, len(reqs))}
+	failed := false
+	// sent maps the operations of the HTTP request to their index in reqs
+	var sent []int
+	for i, req := range clones {
+		op, err := c.batchOperation(ctx, req, resps[i])
+		if err != nil {
+			batchErr.Errors[i] = err
+			failed = true
+			continue
+		}
+		if op != nil {
+			merged.batch = append(merged.batch, op)
+			sent = append(sent, i)
+		}
+	}
+	if len(sent) > 0 {
+		var results []struct {
+			Data       json.RawMessage        `json:"data"`
+			Errors     GraphQLErrors          `json:"errors"`
+			Extensions map[string]interface{} `json:"extensions"`
+		}
+		if err := c.run(ctx, merged, &results, nil); err != nil {
+			return err
+		}
+		if len(results) != len(sent) {
+			return fmt.Errorf("%w: %d results for %d operations", ErrDecodingResponse, len(results), len(sent))
+		}
+		for j, result := range results {
+			i := sent[j]
+			if err := c.decodeBatchResult(ctx, clones[i], result.Data, result.Errors, result.Extensions, resps[i]); err != nil {
+				batchErr.Errors[i] = err
+				failed = true
+			}
+		}
+	}
+	if failed {
+		return batchErr
+	}
+	return nil
+}
+
+// runUnbatched sends each of reqs as its own request, for servers that
+// do not support batching.
+func (c *Client) runUnbatched(ctx context.Context, reqs []*Request, resps []interface{}) error {
+	batchErr := &BatchError{Errors: make([]error, len(reqs))}
+	failed := false
+	for i, req := range reqs {
+		if err := c.run(ctx, req, resps[i], nil); err != nil {
+			batchErr.Errors[i] = err
+			failed = true
+		}
+	}
+	if failed {
+		return batchErr
+	}
+	return nil
+}
+
+// batchOperation returns the body of req within a batch, or nil when the
+// kill switch answered it, from the cache into resp or with an error.
+func (c *Client) batchOperation(ctx context.Context, req *Request, resp interface{}) (map[string]interface{}, error) {
+	kind, name := operationInfo(req.q)
+	if c.killSwitch != nil {
+		op := &Operation{Type: kind, Name: name}
+		if c.labeler != nil {
+			op.Labels = c.labeler(ctx, req)
+		}
+		if killed, err := c.killed(ctx, req, op, resp); killed {
+			return nil, err
+		}
+	}
+	body := map[string]interface{}{"variables": req.vars}
+	if c.trusted != nil && !c.useMultipartForm {
+		id, ok := c.trusted.lookup(req.q)
+		switch {
+		case ok && c.trusted.PersistedQuery:
+			body["extensions"] = map[string]interface{}{
+				"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": id},
+			}
+			return body, nil
+		case ok:
+			body["documentId"] = id
+			return body, nil
+		case !c.trusted.AllowUnknown:
+			return nil, ErrUntrustedDocument
+		}
+	}
+	body["query"] = req.q
+	if name != "" {
+		body["operationName"] = name
+	}
+	return body, nil
+}
+
+// decodeBatchResult decodes the data of the result of req within a batch
+// into resp, and returns its GraphQL errors.
+func (c *Client) decodeBatchResult(ctx context.Context, req *Request, data json.RawMessage, errs GraphQLErrors, extensions map[string]interface{}, resp interface{}) error {
+	if c.warnings != nil {
+		errs = c.warnings.filter(ctx, req, errs, extensions)
+	}
+	hasData := len(data) > 0 && string(data) != "null"
+	if len(c.transforms) > 0 && hasData {
+		var err error
+		if data, err = c.applyTransforms(ctx, data); err != nil {
+			return err
+		}
+	}
+	if resp != nil && len(data) > 0 {
+		if err := c.decodeData(data, resp); err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if c.drift != nil && hasData {
+		c.detectDrift(ctx, req, data)
+	}
+	return nil
+}
+
+// postBatch sends the operations of the batch req as a JSON array, whose
+// results are decoded into resp.
+func (c *Client) postBatch(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	body := getBodyBuffer()
+	defer body.release()
+	if err := json.NewEncoder(body).Encode(req.batch); err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	if c.debug(ctx) {
+		c.log.Debugf("batch of %d operations: %s", len(req.batch), body.String())
+	}
+	return c.post(ctx, req, body, "application/json; charset=utf-8", resp, meta)
+}
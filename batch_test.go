@@ -0,0 +1,121 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRunBatch(t *testing.T) {
+	is := is.New(t)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		is.Equal(r.Header.Get("X-First"), "1")
+		is.Equal(r.Header.Get("X-Second"), "2")
+		var ops []struct {
+			Query         string
+			Variables     map[string]interface{}
+			OperationName string
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&ops))
+		is.Equal(len(ops), 3)
+		is.Equal(ops[0].OperationName, "User")
+		is.Equal(ops[0].Variables["id"], "1")
+		io.WriteString(w, `[
+			{"data":{"user":{"name":"Mat"}}},
+			{"data":null,"errors":[{"message":"forbidden"}]},
+			{"data":{"count":3}}
+		]`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	user := NewRequest(`query User($id: ID!) { user(id: $id) { name } }`)
+	user.Var("id", "1")
+	user.SetHeader("X-First", "1")
+	secret := NewRequest(`{ secret }`)
+	secret.SetHeader("X-Second", "2")
+	var userResp struct{ User struct{ Name string } }
+	var countResp struct{ Count int }
+	err := client.RunBatch(ctx, []*Request{user, secret, NewRequest(`{ count }`)}, []interface{}{&userResp, nil, &countResp})
+	is.Equal(calls, 1)
+	is.Equal(userResp.User.Name, "Mat")
+	is.Equal(countResp.Count, 3)
+
+	var batchErr *BatchError
+	is.True(errors.As(err, &batchErr))
+	is.NoErr(batchErr.Errors[0])
+	is.Equal(batchErr.Errors[1].Error(), "graphql: forbidden")
+	is.NoErr(batchErr.Errors[2])
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+
+	err = client.RunBatch(ctx, []*Request{user}, nil)
+	is.True(err != nil)
+}
+
+func TestRunBatchHeaderConflict(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("conflicting batch was sent")
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	alice := NewRequest(`{ me { name } }`)
+	alice.SetHeader("Authorization", "Bearer alice")
+	bob := NewRequest(`{ me { name } }`)
+	bob.SetHeader("Authorization", "Bearer bob")
+	err := client.RunBatch(ctx, []*Request{alice, bob}, []interface{}{nil, nil})
+	is.True(errors.Is(err, ErrHeaderConflict))
+}
+
+func TestRunBatchClientFeatures(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ops []map[string]interface{}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&ops))
+		is.Equal(len(ops), 1) // the disabled operation is not sent
+		is.Equal(ops[0]["operationName"], "Name")
+		io.WriteString(w, `[{"data":{"name":"mat"}}]`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	kill := NewKillSwitch(nil)
+	kill.Disable("Secret", OperationDisabled)
+	observed := 0
+	client := NewClient(srv.URL,
+		WithKillSwitch(kill),
+		WithMetrics(metricsFunc(func(ctx context.Context, op *Operation, duration time.Duration, err error) {
+			observed++
+		})),
+		WithFieldTransform("name", func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error) {
+			return value.(string) + "!", nil
+		}),
+	)
+	var resp struct{ Name string }
+	err := client.RunBatch(ctx, []*Request{
+		NewRequest(`query Name { name }`),
+		NewRequest(`query Secret { secret }`),
+	}, []interface{}{&resp, nil})
+	var batchErr *BatchError
+	is.True(errors.As(err, &batchErr))
+	is.NoErr(batchErr.Errors[0])
+	is.True(errors.Is(batchErr.Errors[1], ErrOperationDisabled))
+	is.Equal(resp.Name, "mat!")
+	is.Equal(observed, 1)
+}
@@ -0,0 +1,168 @@
+package gographql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BulkUpsert inserts or upserts many rows in chunks, one mutation per
+// chunk. Hasura and PostGraphile both execute a mutation request in a
+// single transaction, so every chunk is applied completely or not at all.
+type BulkUpsert struct {
+	// Builder names the entity and the API style.
+	Builder CRUDBuilder
+	// ChunkSize is the number of rows per mutation, 100 by default.
+	ChunkSize int
+	// Constraint turns inserts into upserts on conflict with the named
+	// constraint (Hasura on_conflict.constraint). For PostGraphile, any
+	// Constraint or UpdateColumns uses the upsert<Entity> mutation of
+	// postgraphile-upsert-plugin.
+	Constraint string
+	// UpdateColumns are the columns updated on conflict.
+	UpdateColumns []string
+	// ContinueOnError keeps sending the remaining chunks after a chunk
+	// fails, instead of stopping at the first failure.
+	ContinueOnError bool
+}
+
+// BulkChunkError reports a failed chunk.
+type BulkChunkError struct {
+	// Offset is the index of the first row of the chunk.
+	Offset int
+	// Count is the number of rows in the chunk.
+	Count int
+	Err   error
+}
+
+func (e *BulkChunkError) Error() string {
+	return fmt.Sprintf("rows %d-%d: %v", e.Offset, e.Offset+e.Count-1, e.Err)
+}
+
+func (e *BulkChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BulkError is returned by RunBulkUpsert when one or more chunks failed.
+type BulkError struct {
+	// Failures lists the failed chunks in row order.
+	Failures []*BulkChunkError
+	// Remaining is the number of rows never sent because the run stopped
+	// at the first failure.
+	Remaining int
+}
+
+func (e *BulkError) Error() string {
+	msgs := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		msgs[i] = f.Error()
+	}
+	msg := "bulk upsert failed: " + strings.Join(msgs, "; ")
+	if e.Remaining > 0 {
+		msg += fmt.Sprintf(" (%d rows not sent)", e.Remaining)
+	}
+	return msg
+}
+
+// Unwrap returns the chunk errors.
+func (e *BulkError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// RunBulkUpsert sends rows, a slice of structs annotated as described for
+// CRUDBuilder, in chunks and returns the number of rows written. On
+// failure the returned error is a *BulkError identifying the failed row
+// ranges.
+func (c *Client) RunBulkUpsert(ctx context.Context, u BulkUpsert, rows interface{}) (int, error) {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return 0, fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+	size := u.ChunkSize
+	if size <= 0 {
+		size = 100
+	}
+	written := 0
+	bulkErr := &BulkError{}
+	for offset := 0; offset < rv.Len(); offset += size {
+		end := min(offset+size, rv.Len())
+		n, err := c.runBulkChunk(ctx, u, rv.Slice(offset, end))
+		if err != nil {
+			bulkErr.Failures = append(bulkErr.Failures, &BulkChunkError{Offset: offset, Count: end - offset, Err: err})
+			if !u.ContinueOnError || ctx.Err() != nil {
+				bulkErr.Remaining = rv.Len() - end
+				break
+			}
+			continue
+		}
+		written += n
+	}
+	if len(bulkErr.Failures) > 0 {
+		return written, bulkErr
+	}
+	return written, nil
+}
+
+func (c *Client) runBulkChunk(ctx context.Context, u BulkUpsert, chunk reflect.Value) (int, error) {
+	objects := make([]interface{}, chunk.Len())
+	for i := range objects {
+		m, err := parseCRUDStruct(chunk.Index(i).Interface())
+		if err != nil {
+			return 0, err
+		}
+		objects[i] = m.values(false)
+	}
+	entity := u.Builder.Entity
+	if u.Builder.Style == PostGraphile {
+		return c.runPostGraphileChunk(ctx, u, objects)
+	}
+	onConflict := ""
+	if u.Constraint != "" {
+		if _, err := gqlName(u.Constraint); err != nil {
+			return 0, err
+		}
+		columns, err := gqlFields(u.UpdateColumns)
+		if err != nil {
+			return 0, err
+		}
+		onConflict = fmt.Sprintf(", on_conflict: {constraint: %s, update_columns: [%s]}", u.Constraint, columns)
+	}
+	req := NewRequest(fmt.Sprintf("mutation ($objects: [%s_insert_input!]!) { insert_%s(objects: $objects%s) { affected_rows } }",
+		entity, entity, onConflict))
+	req.Var("objects", objects)
+	var resp map[string]struct {
+		AffectedRows int `json:"affected_rows"`
+	}
+	if err := c.Run(ctx, req, &resp); err != nil {
+		return 0, err
+	}
+	return resp["insert_"+entity].AffectedRows, nil
+}
+
+// runPostGraphileChunk sends one aliased create (or upsert) mutation per
+// row in a single document.
+func (c *Client) runPostGraphileChunk(ctx context.Context, u BulkUpsert, objects []interface{}) (int, error) {
+	entity := u.Builder.Entity
+	payload := lowerFirst(entity)
+	verb := "create"
+	if u.Constraint != "" || len(u.UpdateColumns) > 0 {
+		verb = "upsert"
+	}
+	var defs, fields []string
+	req := NewRequest("")
+	for i, obj := range objects {
+		defs = append(defs, fmt.Sprintf("$r%d: %s%sInput!", i, upperFirst(verb), entity))
+		fields = append(fields, fmt.Sprintf("r%d: %s%s(input: $r%d) { clientMutationId }", i, verb, entity, i))
+		req.Var(fmt.Sprintf("r%d", i), map[string]interface{}{payload: obj})
+	}
+	req.q = fmt.Sprintf("mutation (%s) { %s }", strings.Join(defs, ", "), strings.Join(fields, " "))
+	if err := c.Run(ctx, req, nil); err != nil {
+		return 0, err
+	}
+	return len(objects), nil
+}
@@ -0,0 +1,91 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type bulkRow struct {
+	ID   int    `graphql:"id,key"`
+	Name string `graphql:"name"`
+}
+
+func TestRunBulkUpsert(t *testing.T) {
+	is := is.New(t)
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string
+			Variables struct {
+				Objects []bulkRow
+			}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		queries = append(queries, body.Query)
+		for _, row := range body.Variables.Objects {
+			if row.Name == "" {
+				io.WriteString(w, `{"errors":[{"message":"name is required"}]}`)
+				return
+			}
+		}
+		fmt.Fprintf(w, `{"data":{"insert_users":{"affected_rows":%d}}}`, len(body.Variables.Objects))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	rows := []bulkRow{{1, "a"}, {2, "b"}, {3, ""}, {4, "d"}, {5, "e"}}
+	u := BulkUpsert{
+		Builder:       CRUDBuilder{Entity: "users"},
+		ChunkSize:     2,
+		Constraint:    "users_pkey",
+		UpdateColumns: []string{"name"},
+	}
+	n, err := client.RunBulkUpsert(ctx, u, rows)
+	is.Equal(n, 2)
+	var bulkErr *BulkError
+	is.True(errors.As(err, &bulkErr))
+	is.Equal(len(bulkErr.Failures), 1)
+	is.Equal(bulkErr.Failures[0].Offset, 2)
+	is.Equal(bulkErr.Failures[0].Count, 2)
+	is.Equal(bulkErr.Remaining, 1)
+	is.Equal(queries[0], "mutation ($objects: [users_insert_input!]!) { insert_users(objects: $objects, on_conflict: {constraint: users_pkey, update_columns: [name]}) { affected_rows } }")
+
+	u.ContinueOnError = true
+	n, err = client.RunBulkUpsert(ctx, u, rows)
+	is.Equal(n, 3)
+	is.True(errors.As(err, &bulkErr))
+	is.Equal(bulkErr.Remaining, 0)
+	is.True(strings.Contains(err.Error(), "rows 2-3: graphql: name is required"))
+}
+
+func TestRunBulkUpsertPostGraphile(t *testing.T) {
+	is := is.New(t)
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Query string }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		query = body.Query
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	n, err := client.RunBulkUpsert(ctx, BulkUpsert{Builder: CRUDBuilder{Entity: "User", Style: PostGraphile}}, []bulkRow{{1, "a"}, {2, "b"}})
+	is.NoErr(err)
+	is.Equal(n, 2)
+	is.Equal(query, "mutation ($r0: CreateUserInput!, $r1: CreateUserInput!) { r0: createUser(input: $r0) { clientMutationId } r1: createUser(input: $r1) { clientMutationId } }")
+}
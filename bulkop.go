@@ -0,0 +1,142 @@
+package gographql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrBulkOperationFailed the bulk operation did not complete.
+var ErrBulkOperationFailed = errors.New("bulk operation failed")
+
+// BulkOperation runs a query as an asynchronous bulk operation on servers
+// that deliver the results as a JSON Lines (NDJSON) file, such as the
+// Shopify Admin API: the query is started with bulkOperationRunQuery, its
+// status is polled until it finishes, and the result file is streamed.
+type BulkOperation struct {
+	// Query is the query run in bulk.
+	Query string
+	// PollInterval is the time between status checks, 1 second by
+	// default.
+	PollInterval time.Duration
+}
+
+// BulkOperationStatus is the state of a bulk operation.
+type BulkOperationStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	ErrorCode string `json:"errorCode"`
+	// ObjectCount is the number of objects processed so far.
+	ObjectCount string `json:"objectCount"`
+	// URL is the result file, empty when there are no results.
+	URL string `json:"url"`
+	// PartialDataURL is the file of the results obtained before a
+	// failure.
+	PartialDataURL string `json:"partialDataUrl"`
+}
+
+const bulkOperationFields = "id status errorCode objectCount url partialDataUrl"
+
+// RunBulkOperation runs op, waits for it to finish and calls handler with
+// every line of the result file, in order. It stops at the first error
+// returned by handler. The final status is returned along with any error;
+// an operation that did not complete fails with ErrBulkOperationFailed.
+//
+//	status, err := client.RunBulkOperation(ctx, gographql.BulkOperation{
+//	    Query: `{ products { edges { node { id title } } } }`,
+//	}, func(line json.RawMessage) error {
+//	    var product Product
+//	    return json.Unmarshal(line, &product)
+//	})
+func (c *Client) RunBulkOperation(ctx context.Context, op BulkOperation, handler func(line json.RawMessage) error) (*BulkOperationStatus, error) {
+	start := NewRequest("mutation ($query: String!) { bulkOperationRunQuery(query: $query) { bulkOperation { " +
+		bulkOperationFields + " } userErrors { field message } } }")
+	start.Var("query", op.Query)
+	var started struct {
+		BulkOperationRunQuery struct {
+			BulkOperation *BulkOperationStatus `json:"bulkOperation"`
+			UserErrors    []struct {
+				Message string `json:"message"`
+			} `json:"userErrors"`
+		} `json:"bulkOperationRunQuery"`
+	}
+	if err := c.Run(ctx, start, &started); err != nil {
+		return nil, err
+	}
+	if userErrors := started.BulkOperationRunQuery.UserErrors; len(userErrors) > 0 {
+		msgs := make([]string, len(userErrors))
+		for i, e := range userErrors {
+			msgs[i] = e.Message
+		}
+		return nil, fmt.Errorf("%w: %s", ErrBulkOperationFailed, strings.Join(msgs, "; "))
+	}
+	status := started.BulkOperationRunQuery.BulkOperation
+	if status == nil {
+		return nil, fmt.Errorf("%w: no bulk operation started", ErrBulkOperationFailed)
+	}
+
+	interval := op.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	poll := NewRequest("query ($id: ID!) { node(id: $id) { ... on BulkOperation { " + bulkOperationFields + " } } }")
+	poll.Var("id", status.ID)
+	for status.Status == "CREATED" || status.Status == "RUNNING" || status.Status == "CANCELING" {
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(interval):
+		}
+		var polled struct {
+			Node *BulkOperationStatus `json:"node"`
+		}
+		if err := c.Run(ctx, poll, &polled); err != nil {
+			return status, err
+		}
+		if polled.Node == nil {
+			return status, fmt.Errorf("%w: bulk operation %s not found", ErrBulkOperationFailed, status.ID)
+		}
+		status = polled.Node
+	}
+	if status.Status != "COMPLETED" {
+		return status, fmt.Errorf("%w: status %s %s", ErrBulkOperationFailed, status.Status, status.ErrorCode)
+	}
+	if status.URL == "" {
+		return status, nil
+	}
+	res, err := c.openURL(ctx, status.URL)
+	if err != nil {
+		return status, err
+	}
+	defer res.Body.Close()
+	return status, StreamNDJSON(res.Body, handler)
+}
+
+// StreamNDJSON calls handler with every non-empty line of the JSON Lines
+// stream r, stopping at the first error. Lines may be of any length.
+func StreamNDJSON(r io.Reader, handler func(line json.RawMessage) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if !json.Valid(line) {
+				return errors.Join(ErrDecodingResponse, fmt.Errorf("invalid JSON line: %.40s", line))
+			}
+			if herr := handler(json.RawMessage(line)); herr != nil {
+				return herr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRunBulkOperation(t *testing.T) {
+	is := is.New(t)
+	polls := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			io.WriteString(w, "{\"id\":\"p1\"}\n{\"id\":\"p2\"}\n\n{\"id\":\"p3\"}")
+			return
+		}
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		if strings.Contains(body.Query, "bulkOperationRunQuery") {
+			is.Equal(body.Variables["query"], "{ products { edges { node { id } } } }")
+			io.WriteString(w, `{"data":{"bulkOperationRunQuery":{"bulkOperation":{"id":"b1","status":"CREATED"},"userErrors":[]}}}`)
+			return
+		}
+		is.Equal(body.Variables["id"], "b1")
+		polls++
+		if polls < 2 {
+			io.WriteString(w, `{"data":{"node":{"id":"b1","status":"RUNNING","objectCount":"1"}}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"node":{"id":"b1","status":"COMPLETED","objectCount":"3","url":"`+srv.URL+`/result.jsonl"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	var ids []string
+	status, err := client.RunBulkOperation(ctx, BulkOperation{
+		Query:        "{ products { edges { node { id } } } }",
+		PollInterval: time.Millisecond,
+	}, func(line json.RawMessage) error {
+		var product struct{ ID string }
+		if err := json.Unmarshal(line, &product); err != nil {
+			return err
+		}
+		ids = append(ids, product.ID)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(status.ObjectCount, "3")
+	is.Equal(ids, []string{"p1", "p2", "p3"})
+	is.Equal(polls, 2)
+}
+
+func TestRunBulkOperationFailed(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"bulkOperationRunQuery":{"bulkOperation":{"id":"b1","status":"FAILED","errorCode":"TIMEOUT"},"userErrors":[]}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	status, err := NewClient(srv.URL).RunBulkOperation(ctx, BulkOperation{Query: "{ a }"}, nil)
+	is.True(errors.Is(err, ErrBulkOperationFailed))
+	is.Equal(status.ErrorCode, "TIMEOUT")
+}
@@ -0,0 +1,607 @@
+package gographql
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrNoCache the client was not created with the WithCache option.
+var ErrNoCache = errors.New("client has no cache")
+
+// Cache is a normalized cache of GraphQL results. Objects that can be
+// identified (by default through their __typename and id fields) are
+// stored once and shared by every result referencing them, so a mutation
+// returning an updated object is reflected in all cached queries.
+//
+// A Cache is safe for concurrent use.
+type Cache struct {
+	mu        sync.RWMutex
+	entityKey func(obj map[string]interface{}) string
+	resultKey CacheKeyFunc
+	ids       *IDMap
+	entities  map[string]map[string]interface{}
+	results   map[string]interface{}
+	layers    []*cacheLayer
+	listeners map[int]func(CacheEvent)
+	nextID    int
+
+	// eviction state, see cache_gc.go
+	lru        *list.List
+	lruIndex   map[string]*list.Element
+	retained   map[string]int
+	resultSize map[string]int
+	entitySize map[string]int
+	size       int
+	maxResults int
+	maxBytes   int
+	gcOnWrite  bool
+
+	// request headers each result varies by, see cache_vary.go
+	vary        map[string][]string
+	varyHeaders []string
+}
+
+// cacheLayer is an optimistic layer applied on top of the cache entities.
+type cacheLayer struct {
+	id       string
+	entities map[string]map[string]interface{}
+}
+
+// CacheEventType describes what changed in a Cache.
+type CacheEventType int
+
+const (
+	// CacheWrite is sent when a server result was written to the cache.
+	CacheWrite CacheEventType = iota
+	// CacheOptimisticApply is sent when an optimistic result was applied.
+	CacheOptimisticApply
+	// CacheOptimisticRollback is sent when an optimistic result was rolled back.
+	CacheOptimisticRollback
+	// CacheOptimisticCommit is sent when an optimistic result was replaced
+	// by the server result.
+	CacheOptimisticCommit
+	// CacheEvict is sent when results or entities were evicted.
+	CacheEvict
+)
+
+// CacheEvent is delivered to cache listeners.
+type CacheEvent struct {
+	Type CacheEventType
+	// Layer is the optimistic layer ID for optimistic events.
+	Layer string
+	// Keys are the entity and result keys affected by the change.
+	Keys []string
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithEntityKey sets the function used to compute the cache ID of an
+// object. Objects for which it returns an empty string are not normalized.
+func WithEntityKey(fn func(obj map[string]interface{}) string) CacheOption {
+	return func(cache *Cache) {
+		cache.entityKey = fn
+	}
+}
+
+// CacheKeyFunc computes the key a request's result is stored under.
+type CacheKeyFunc func(req *Request) string
+
+// WithCacheKey sets the function used to compute result keys, for
+// example to keep the responses for different locales or roles apart.
+// DefaultCacheKey is used by default.
+//
+//	cache := gographql.NewCache(gographql.WithCacheKey(
+//	    gographql.CacheKeyWithHeaders("Accept-Language", "X-Role"),
+//	))
+func WithCacheKey(fn CacheKeyFunc) CacheOption {
+	return func(cache *Cache) {
+		cache.resultKey = fn
+	}
+}
+
+// WithCacheIDMap makes the cache resolve optimistic client-side IDs to
+// server IDs through ids when computing entity keys.
+func WithCacheIDMap(ids *IDMap) CacheOption {
+	return func(cache *Cache) {
+		cache.ids = ids
+	}
+}
+
+// NewCache makes a new, empty normalized Cache.
+func NewCache(opts ...CacheOption) *Cache {
+	cache := &Cache{
+		entities:   make(map[string]map[string]interface{}),
+		results:    make(map[string]interface{}),
+		listeners:  make(map[int]func(CacheEvent)),
+		lru:        list.New(),
+		lruIndex:   make(map[string]*list.Element),
+		retained:   make(map[string]int),
+		resultSize: make(map[string]int),
+		entitySize: make(map[string]int),
+	}
+	for _, optionFunc := range opts {
+		optionFunc(cache)
+	}
+	if cache.entityKey == nil {
+		cache.entityKey = defaultEntityKey
+	}
+	if cache.resultKey == nil {
+		cache.resultKey = DefaultCacheKey
+	}
+	return cache
+}
+
+func defaultEntityKey(obj map[string]interface{}) string {
+	typename, ok := obj["__typename"].(string)
+	if !ok {
+		return ""
+	}
+	switch id := obj["id"].(type) {
+	case string:
+		return typename + ":" + id
+	case float64:
+		// without an exponent, as %v formats large ids
+		return typename + ":" + strconv.FormatFloat(id, 'f', -1, 64)
+	case json.Number:
+		return typename + ":" + id.String()
+	}
+	return ""
+}
+
+// WithCache writes the data of successful responses into cache.
+// The client's IDMap is used to resolve optimistic IDs unless the cache
+// already has one.
+func WithCache(cache *Cache) ClientOption {
+	return func(client *Client) {
+		client.cache = cache
+	}
+}
+
+// Cache returns the client's normalized cache, or nil.
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
+// RunOptimistic runs req like Run, with optimistic applied to the cache
+// until the server responds. On success the optimistic layer is replaced
+// by the server result, otherwise it is rolled back.
+// The client must have been created with the WithCache option, otherwise
+// ErrNoCache is returned and req is not sent.
+func (c *Client) RunOptimistic(ctx context.Context, req *Request, optimistic interface{}, resp interface{}) error {
+	if c.cache == nil {
+		return ErrNoCache
+	}
+	layer := c.NewOptimisticID()
+	if err := c.cache.ApplyOptimistic(layer, optimistic); err != nil {
+		return err
+	}
+	if err := c.Run(ctx, req, resp); err != nil {
+		c.cache.Rollback(layer)
+		return err
+	}
+	c.cache.commit(layer)
+	return nil
+}
+
+// WatchQuery runs req and calls handler with the resulting data, then again
+// every time the cache entries backing that data change, whether through
+// other queries, mutations or optimistic updates. Watching stops when ctx
+// is done. Handler calls are serialized.
+// The client must have been created with the WithCache option.
+func (c *Client) WatchQuery(ctx context.Context, req *Request, handler func(data json.RawMessage)) error {
+	if c.cache == nil {
+		return ErrNoCache
+	}
+	if err := c.Run(ctx, req, nil); err != nil {
+		return err
+	}
+	key := c.cacheKey(ctx, req)
+	c.cache.Retain(key)
+	changed := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var deps map[string]bool
+	deliver := func(last []byte) []byte {
+		v, d, ok := c.cache.readWithDeps(key)
+		if !ok {
+			return last
+		}
+		mu.Lock()
+		deps = d
+		mu.Unlock()
+		b, err := json.Marshal(v)
+		if err != nil || bytes.Equal(b, last) {
+			return last
+		}
+		handler(b)
+		return b
+	}
+	last := deliver(nil)
+	cancel := c.cache.Listen(func(e CacheEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, k := range e.Keys {
+			if deps[k] {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+				return
+			}
+		}
+	})
+	go func() {
+		defer c.cache.Release(key)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				last = deliver(last)
+			}
+		}
+	}()
+	return nil
+}
+
+// Listen registers fn to be called after every change to the cache.
+// The returned function removes the listener.
+func (c *Cache) Listen(fn func(CacheEvent)) (cancel func()) {
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	c.listeners[id] = fn
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		delete(c.listeners, id)
+		c.mu.Unlock()
+	}
+}
+
+// Write normalizes data and stores it as the result for key.
+// data may be any value that encodes to a JSON object.
+func (c *Cache) Write(key string, data interface{}) error {
+	v, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	changed := make(map[string]struct{})
+	entities := make(map[string]map[string]interface{})
+	tree := c.normalize(v, entities)
+	c.mergeEntities(c.entities, entities, changed)
+	if !reflect.DeepEqual(c.results[key], tree) {
+		changed[key] = struct{}{}
+	}
+	c.results[key] = tree
+	c.touch(key)
+	c.setResultSize(key, tree)
+	for k := range changed {
+		if fields, ok := c.entities[k]; ok {
+			c.setEntitySize(k, fields)
+		}
+	}
+	evicted := c.evict()
+	c.mu.Unlock()
+	c.notify(CacheEvent{Type: CacheWrite, Keys: sortedKeys(changed)})
+	if len(evicted) > 0 {
+		c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+	}
+	return nil
+}
+
+// Read returns the denormalized result stored for key, with any
+// optimistic layers applied.
+func (c *Cache) Read(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tree, ok := c.results[key]
+	if !ok {
+		return nil, false
+	}
+	c.touch(key)
+	return c.denormalize(tree, nil, nil), true
+}
+
+// readWithDeps is like Read but also returns the entity keys the result
+// was built from.
+func (c *Cache) readWithDeps(key string) (interface{}, map[string]bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tree, ok := c.results[key]
+	if !ok {
+		return nil, nil, false
+	}
+	c.touch(key)
+	deps := map[string]bool{key: true}
+	return c.denormalize(tree, nil, deps), deps, true
+}
+
+// ReadInto decodes the result stored for key into out.
+func (c *Cache) ReadInto(key string, out interface{}) (bool, error) {
+	v, ok := c.Read(key)
+	if !ok {
+		return false, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(b, out)
+}
+
+// Entity returns the fields of the entity stored under key, with any
+// optimistic layers applied. Nested entities are returned as references.
+func (c *Cache) Entity(key string) (map[string]interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entity(key)
+}
+
+// ApplyOptimistic normalizes data into a new optimistic layer identified
+// by layer. The layer shadows the server data until it is rolled back or
+// the cache is written with the server result.
+func (c *Cache) ApplyOptimistic(layer string, data interface{}) error {
+	v, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	l := &cacheLayer{id: layer, entities: make(map[string]map[string]interface{})}
+	c.normalize(v, l.entities)
+	c.layers = append(c.layers, l)
+	keys := make([]string, 0, len(l.entities))
+	for key := range l.entities {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	sort.Strings(keys)
+	c.notify(CacheEvent{Type: CacheOptimisticApply, Layer: layer, Keys: keys})
+	return nil
+}
+
+// Rollback removes the optimistic layer, restoring the server data.
+func (c *Cache) Rollback(layer string) {
+	if keys, ok := c.removeLayer(layer); ok {
+		c.notify(CacheEvent{Type: CacheOptimisticRollback, Layer: layer, Keys: keys})
+	}
+}
+
+func (c *Cache) commit(layer string) {
+	if keys, ok := c.removeLayer(layer); ok {
+		c.notify(CacheEvent{Type: CacheOptimisticCommit, Layer: layer, Keys: keys})
+	}
+}
+
+func (c *Cache) removeLayer(layer string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, l := range c.layers {
+		if l.id != layer {
+			continue
+		}
+		c.layers = append(c.layers[:i], c.layers[i+1:]...)
+		keys := make([]string, 0, len(l.entities))
+		for key := range l.entities {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys, true
+	}
+	return nil, false
+}
+
+func (c *Cache) notify(e CacheEvent) {
+	c.mu.RLock()
+	listeners := make([]func(CacheEvent), 0, len(c.listeners))
+	for _, fn := range c.listeners {
+		listeners = append(listeners, fn)
+	}
+	c.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(e)
+	}
+}
+
+// normalize replaces identifiable objects in v with references, collecting
+// their fields into entities.
+func (c *Cache) normalize(v interface{}, entities map[string]map[string]interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for name, value := range v {
+			fields[name] = c.normalize(value, entities)
+		}
+		key := c.entityKey(v)
+		if key == "" {
+			return fields
+		}
+		key = c.resolveKey(key)
+		if existing, ok := entities[key]; ok {
+			for name, value := range fields {
+				existing[name] = value
+			}
+		} else {
+			entities[key] = fields
+		}
+		return map[string]interface{}{"__ref": key}
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i := range v {
+			items[i] = c.normalize(v[i], entities)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// resolveKey maps a key built from an optimistic ID to the server ID.
+func (c *Cache) resolveKey(key string) string {
+	if c.ids == nil {
+		return key
+	}
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == ':' {
+			return key[:i+1] + c.ids.Resolve(key[i+1:])
+		}
+	}
+	return c.ids.Resolve(key)
+}
+
+func (c *Cache) mergeEntities(dst, src map[string]map[string]interface{}, changed map[string]struct{}) {
+	for key, fields := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = fields
+			changed[key] = struct{}{}
+			continue
+		}
+		for name, value := range fields {
+			if !reflect.DeepEqual(existing[name], value) {
+				existing[name] = value
+				changed[key] = struct{}{}
+			}
+		}
+	}
+}
+
+func (c *Cache) entity(key string) (map[string]interface{}, bool) {
+	base, ok := c.entities[key]
+	fields := make(map[string]interface{}, len(base))
+	for name, value := range base {
+		fields[name] = value
+	}
+	for _, l := range c.layers {
+		if overlay, found := l.entities[key]; found {
+			ok = true
+			for name, value := range overlay {
+				fields[name] = value
+			}
+		}
+	}
+	return fields, ok
+}
+
+// denormalize resolves references in v, recording them in deps when it is
+// not nil. seen guards against cycles.
+func (c *Cache) denormalize(v interface{}, seen, deps map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["__ref"].(string); ok && len(v) == 1 {
+			if deps != nil {
+				deps[ref] = true
+			}
+			if seen[ref] {
+				return nil
+			}
+			fields, found := c.entity(ref)
+			if !found {
+				return nil
+			}
+			next := make(map[string]bool, len(seen)+1)
+			for k := range seen {
+				next[k] = true
+			}
+			next[ref] = true
+			return c.denormalize(fields, next, deps)
+		}
+		out := make(map[string]interface{}, len(v))
+		for name, value := range v {
+			out[name] = c.denormalize(value, seen, deps)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = c.denormalize(v[i], seen, deps)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Key returns the key the result of req is stored under, including the
+// values of the request headers the result varies by. Only the headers of
+// req are considered, not the default headers of a client.
+func (c *Cache) Key(req *Request) string {
+	req = req.Clone()
+	return c.varyKey(c.resultKey(req), req.Header)
+}
+
+// cacheKey returns the key the result of req is stored under in the client
+// cache, with the values of the headers the result varies by as c sends
+// them.
+func (c *Client) cacheKey(ctx context.Context, req *Request) string {
+	req = req.Clone()
+	return c.cache.varyKey(c.cache.resultKey(req), c.outgoingHeader(ctx, req))
+}
+
+// DefaultCacheKey returns a hash of the query and variables of req.
+func DefaultCacheKey(req *Request) string {
+	b, _ := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{req.Query(), req.Vars()})
+	return hexSHA256(defaultCrypto, b)
+}
+
+// CacheKeyWithHeaders returns a CacheKeyFunc that adds the values of the
+// named request headers to DefaultCacheKey, so responses varying by
+// those headers are cached separately.
+func CacheKeyWithHeaders(names ...string) CacheKeyFunc {
+	return func(req *Request) string {
+		key := DefaultCacheKey(req)
+		h := defaultCrypto.SHA256()
+		for _, name := range names {
+			req.mu.RLock()
+			values := req.Header.Values(name)
+			req.mu.RUnlock()
+			json.NewEncoder(h).Encode(values)
+		}
+		return key + ":" + hex.EncodeToString(h.Sum(nil)[:8])
+	}
+}
+
+// toJSONValue converts v to its generic JSON representation.
+func toJSONValue(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return v, nil
+	}
+	var b []byte
+	switch raw := v.(type) {
+	case json.RawMessage:
+		b = raw
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,246 @@
+package gographql
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// WithCacheMaxResults bounds the number of results kept in the cache.
+// The least recently used results are evicted first; retained results
+// are never evicted.
+func WithCacheMaxResults(n int) CacheOption {
+	return func(cache *Cache) {
+		cache.maxResults = n
+	}
+}
+
+// WithCacheMaxBytes bounds the approximate memory used by the cache, as
+// measured by the JSON encoded size of its results and entities.
+func WithCacheMaxBytes(n int) CacheOption {
+	return func(cache *Cache) {
+		cache.maxBytes = n
+	}
+}
+
+// WithCacheGCOnWrite collects unreferenced entities after every write
+// instead of only after evictions and explicit GC calls.
+func WithCacheGCOnWrite() CacheOption {
+	return func(cache *Cache) {
+		cache.gcOnWrite = true
+	}
+}
+
+// Retain protects the result stored under key from eviction until a
+// matching call to Release. Calls nest. WatchQuery retains the results
+// it watches.
+func (c *Cache) Retain(key string) {
+	c.mu.Lock()
+	c.retained[key]++
+	c.mu.Unlock()
+}
+
+// Release undoes one call to Retain.
+func (c *Cache) Release(key string) {
+	c.mu.Lock()
+	if c.retained[key] <= 1 {
+		delete(c.retained, key)
+	} else {
+		c.retained[key]--
+	}
+	evicted := c.evict()
+	c.mu.Unlock()
+	if len(evicted) > 0 {
+		c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+	}
+}
+
+// Evict removes the result stored under key, and any entities no longer
+// referenced once it is gone.
+func (c *Cache) Evict(key string) {
+	c.mu.Lock()
+	if _, ok := c.results[key]; !ok {
+		c.mu.Unlock()
+		return
+	}
+	c.removeResult(key)
+	evicted := append([]string{key}, c.collect()...)
+	c.mu.Unlock()
+	c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+}
+
+// EvictEntities removes every result built from one of the entities
+// with the given keys, and any entities no longer referenced once they
+// are gone. It returns the keys of the removed results.
+func (c *Cache) EvictEntities(keys ...string) []string {
+	c.mu.Lock()
+	var removed []string
+	for key, tree := range c.results {
+		deps := make(map[string]bool)
+		c.denormalize(tree, nil, deps)
+		for _, entity := range keys {
+			if deps[c.resolveKey(entity)] {
+				removed = append(removed, key)
+				break
+			}
+		}
+	}
+	if len(removed) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		c.removeResult(key)
+	}
+	evicted := append(append([]string(nil), removed...), c.collect()...)
+	c.mu.Unlock()
+	c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+	return removed
+}
+
+// GC removes entities that are not reachable from any result or
+// optimistic layer and returns how many were removed. Entities are
+// collected by tracing references from the results, not by counting
+// references: entity graphs are often cyclic, such as a post referencing
+// its author whose posts reference it back, and reference counts would
+// never free such cycles.
+func (c *Cache) GC() int {
+	c.mu.Lock()
+	evicted := c.collect()
+	c.mu.Unlock()
+	if len(evicted) > 0 {
+		c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+	}
+	return len(evicted)
+}
+
+// Size returns the approximate memory used by the cache in bytes.
+func (c *Cache) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.size
+}
+
+// Len returns the number of results and entities in the cache.
+func (c *Cache) Len() (results, entities int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.results), len(c.entities)
+}
+
+// touch marks key as the most recently used result.
+func (c *Cache) touch(key string) {
+	if e, ok := c.lruIndex[key]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.lruIndex[key] = c.lru.PushFront(key)
+}
+
+func (c *Cache) setResultSize(key string, tree interface{}) {
+	n := jsonSize(tree)
+	c.size += n - c.resultSize[key]
+	c.resultSize[key] = n
+}
+
+func (c *Cache) setEntitySize(key string, fields map[string]interface{}) {
+	n := jsonSize(fields)
+	c.size += n - c.entitySize[key]
+	c.entitySize[key] = n
+}
+
+func (c *Cache) removeResult(key string) {
+	delete(c.results, key)
+	if e, ok := c.lruIndex[key]; ok {
+		c.lru.Remove(e)
+		delete(c.lruIndex, key)
+	}
+	c.size -= c.resultSize[key]
+	delete(c.resultSize, key)
+}
+
+// evict removes least recently used results until the cache is within its
+// bounds, then collects unreferenced entities. It returns the removed keys.
+func (c *Cache) evict() []string {
+	var evicted []string
+	for e := c.lru.Back(); e != nil && c.overLimit(); {
+		key := e.Value.(string)
+		e = e.Prev()
+		if c.retained[key] > 0 {
+			continue
+		}
+		c.removeResult(key)
+		evicted = append(evicted, key)
+	}
+	if len(evicted) > 0 || c.gcOnWrite || (c.maxBytes > 0 && c.size > c.maxBytes) {
+		evicted = append(evicted, c.collect()...)
+	}
+	return evicted
+}
+
+func (c *Cache) overLimit() bool {
+	return (c.maxResults > 0 && len(c.results) > c.maxResults) ||
+		(c.maxBytes > 0 && c.size > c.maxBytes)
+}
+
+// collect removes the entities not reachable from a result or an
+// optimistic layer, marking from those roots and sweeping the rest, and
+// returns their keys. It runs after evictions rather than on every
+// write, unless WithCacheGCOnWrite is given, so that its cost, linear in
+// the size of the cache, is paid only when something may have become
+// unreachable.
+func (c *Cache) collect() []string {
+	marked := make(map[string]bool)
+	var mark func(v interface{})
+	mark = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["__ref"].(string); ok && len(v) == 1 {
+				if marked[ref] {
+					return
+				}
+				marked[ref] = true
+				mark(c.entities[ref])
+				for _, l := range c.layers {
+					mark(l.entities[ref])
+				}
+				return
+			}
+			for _, value := range v {
+				mark(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				mark(value)
+			}
+		}
+	}
+	for _, tree := range c.results {
+		mark(tree)
+	}
+	for _, l := range c.layers {
+		for key, fields := range l.entities {
+			marked[key] = true
+			mark(fields)
+		}
+	}
+	var removed []string
+	for key := range c.entities {
+		if marked[key] {
+			continue
+		}
+		delete(c.entities, key)
+		c.size -= c.entitySize[key]
+		delete(c.entitySize, key)
+		removed = append(removed, key)
+	}
+	return removed
+}
+
+func jsonSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,72 @@
+package gographql
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func todoResult(id, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": id, "title": title},
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(WithCacheMaxResults(2))
+	is.NoErr(cache.Write("a", todoResult("1", "a")))
+	is.NoErr(cache.Write("b", todoResult("2", "b")))
+	_, ok := cache.Read("a") // a is now more recently used than b
+	is.True(ok)
+	is.NoErr(cache.Write("c", todoResult("3", "c")))
+
+	_, ok = cache.Read("b")
+	is.True(!ok)
+	_, ok = cache.Entity("Todo:2")
+	is.True(!ok) // unreferenced entity collected with its result
+	results, entities := cache.Len()
+	is.Equal(results, 2)
+	is.Equal(entities, 2)
+}
+
+func TestCacheRetain(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(WithCacheMaxResults(1))
+	cache.Retain("a")
+	is.NoErr(cache.Write("a", todoResult("1", "a")))
+	is.NoErr(cache.Write("b", todoResult("2", "b")))
+	_, ok := cache.Read("a")
+	is.True(ok)
+	_, ok = cache.Read("b")
+	is.True(!ok)
+	cache.Release("a")
+	is.NoErr(cache.Write("c", todoResult("3", "c")))
+	_, ok = cache.Read("a")
+	is.True(!ok)
+}
+
+func TestCacheMaxBytes(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(WithCacheMaxBytes(200))
+	for _, key := range []string{"1", "2", "3", "4", "5", "6"} {
+		is.NoErr(cache.Write(key, todoResult(key, "some title")))
+		is.True(cache.Size() <= 200)
+	}
+	_, ok := cache.Read("6")
+	is.True(ok)
+}
+
+func TestCacheGC(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache()
+	is.NoErr(cache.Write("a", todoResult("1", "a")))
+	is.NoErr(cache.Write("a", todoResult("2", "b")))
+	is.Equal(cache.GC(), 1)
+	_, ok := cache.Entity("Todo:1")
+	is.True(!ok)
+	cache.Evict("a")
+	_, entities := cache.Len()
+	is.Equal(entities, 0)
+	is.Equal(cache.Size(), 0)
+}
@@ -0,0 +1,138 @@
+package gographql
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrCacheVersionMismatch the cache snapshot was written for a different
+// schema version and was not restored.
+var ErrCacheVersionMismatch = errors.New("cache snapshot version mismatch")
+
+// cacheSnapshotFormat is bumped when the snapshot layout changes.
+const cacheSnapshotFormat = 1
+
+type cacheSnapshot struct {
+	Format   int                               `json:"format"`
+	Version  string                            `json:"version"`
+	Results  map[string]interface{}            `json:"results"`
+	Entities map[string]map[string]interface{} `json:"entities"`
+	// Order lists result keys from least to most recently used.
+	Order []string `json:"order"`
+	// Vary lists the request headers results vary by.
+	Vary map[string][]string `json:"vary,omitempty"`
+}
+
+// SchemaHash returns a version string for schema (an SDL document or
+// introspection result) suitable for Snapshot and Restore.
+func SchemaHash(schema []byte) string {
+	return hexSHA256(defaultCrypto, schema)
+}
+
+// Snapshot writes the cache contents to w, tagged with version.
+// Optimistic layers are not included.
+func (c *Cache) Snapshot(w io.Writer, version string) error {
+	c.mu.RLock()
+	snap := cacheSnapshot{
+		Format:   cacheSnapshotFormat,
+		Version:  version,
+		Results:  c.results,
+		Entities: c.entities,
+		Order:    make([]string, 0, c.lru.Len()),
+		Vary:     c.vary,
+	}
+	for e := c.lru.Back(); e != nil; e = e.Prev() {
+		snap.Order = append(snap.Order, e.Value.(string))
+	}
+	err := json.NewEncoder(w).Encode(snap)
+	c.mu.RUnlock()
+	return err
+}
+
+// Restore replaces the cache contents with a snapshot written by Snapshot.
+// If the snapshot was written with a different version (e.g. before a
+// schema change), ErrCacheVersionMismatch is returned and the cache is
+// left untouched.
+func (c *Cache) Restore(r io.Reader, version string) error {
+	var snap cacheSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return err
+	}
+	if snap.Format != cacheSnapshotFormat || snap.Version != version {
+		return ErrCacheVersionMismatch
+	}
+	if snap.Results == nil {
+		snap.Results = make(map[string]interface{})
+	}
+	if snap.Entities == nil {
+		snap.Entities = make(map[string]map[string]interface{})
+	}
+	c.mu.Lock()
+	c.results = snap.Results
+	c.entities = snap.Entities
+	c.vary = snap.Vary
+	c.lru.Init()
+	c.lruIndex = make(map[string]*list.Element)
+	c.resultSize = make(map[string]int)
+	c.entitySize = make(map[string]int)
+	c.size = 0
+	for _, key := range snap.Order {
+		if _, ok := c.results[key]; ok {
+			c.touch(key)
+		}
+	}
+	for key, tree := range c.results {
+		if _, ok := c.lruIndex[key]; !ok {
+			c.lruIndex[key] = c.lru.PushBack(key)
+		}
+		c.setResultSize(key, tree)
+	}
+	for key, fields := range c.entities {
+		c.setEntitySize(key, fields)
+	}
+	evicted := c.evict()
+	c.mu.Unlock()
+	if len(evicted) > 0 {
+		c.notify(CacheEvent{Type: CacheEvict, Keys: evicted})
+	}
+	return nil
+}
+
+// SaveFile atomically writes a snapshot of the cache to path.
+func (c *Cache) SaveFile(path, version string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := c.Snapshot(f, version); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile restores a snapshot written by SaveFile. A missing file or a
+// snapshot for another version is not an error and leaves the cache
+// untouched, so LoadFile can be called unconditionally at startup.
+func (c *Cache) LoadFile(path, version string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := c.Restore(f, version); err != nil && !errors.Is(err, ErrCacheVersionMismatch) {
+		return err
+	}
+	return nil
+}
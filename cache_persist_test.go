@@ -0,0 +1,58 @@
+package gographql
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCacheSnapshotRestore(t *testing.T) {
+	is := is.New(t)
+	version := SchemaHash([]byte("type Query { todo: Todo }"))
+	cache := NewCache()
+	is.NoErr(cache.Write("a", todoResult("1", "a")))
+	is.NoErr(cache.Write("b", todoResult("2", "b")))
+
+	var buf bytes.Buffer
+	is.NoErr(cache.Snapshot(&buf, version))
+	snapshot := buf.Bytes()
+
+	restored := NewCache(WithCacheMaxResults(1))
+	is.NoErr(restored.Restore(bytes.NewReader(snapshot), version))
+	_, ok := restored.Read("a")
+	is.True(!ok) // least recently used result evicted on restore
+	v, ok := restored.Read("b")
+	is.True(ok)
+	is.Equal(v, todoResult("2", "b"))
+
+	err := NewCache().Restore(bytes.NewReader(snapshot), "other")
+	is.True(errors.Is(err, ErrCacheVersionMismatch))
+}
+
+func TestCacheSaveLoadFile(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewCache()
+	is.NoErr(cache.LoadFile(path, "v1")) // missing file is fine
+	is.NoErr(cache.Write("a", todoResult("1", "a")))
+	is.NoErr(cache.SaveFile(path, "v1"))
+
+	warm := NewCache()
+	is.NoErr(warm.LoadFile(path, "v1"))
+	_, ok := warm.Read("a")
+	is.True(ok)
+
+	stale := NewCache()
+	is.NoErr(stale.LoadFile(path, "v2"))
+	_, ok = stale.Read("a")
+	is.True(!ok)
+
+	// a mismatching snapshot leaves a filled cache untouched
+	is.NoErr(cache.Write("b", todoResult("2", "b")))
+	is.NoErr(cache.LoadFile(path, "v2"))
+	_, ok = cache.Read("b")
+	is.True(ok)
+}
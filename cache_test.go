@@ -0,0 +1,263 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestCacheNormalizes(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache()
+	is.NoErr(cache.Write("a", map[string]interface{}{
+		"user": map[string]interface{}{"__typename": "User", "id": "1", "name": "Mat"},
+	}))
+	is.NoErr(cache.Write("b", map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"__typename": "User", "id": "1", "name": "Mathew"},
+		},
+	}))
+	var a struct {
+		User struct{ Name string }
+	}
+	ok, err := cache.ReadInto("a", &a)
+	is.NoErr(err)
+	is.True(ok)
+	is.Equal(a.User.Name, "Mathew") // entity shared between results
+	_, ok = cache.Read("missing")
+	is.True(!ok)
+}
+
+func TestCacheNumericIDs(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache()
+	is.NoErr(cache.Write("a", json.RawMessage(`{"user":{"__typename":"User","id":12345678,"name":"Mat"}}`)))
+	user, ok := cache.Entity("User:12345678")
+	is.True(ok) // not User:1.2345678e+07
+	is.Equal(user["name"], "Mat")
+}
+
+func TestCacheOptimisticRollback(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache()
+	var mu sync.Mutex
+	var events []CacheEventType
+	cancel := cache.Listen(func(e CacheEvent) {
+		mu.Lock()
+		events = append(events, e.Type)
+		mu.Unlock()
+	})
+	defer cancel()
+	is.NoErr(cache.Write("q", map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": "1", "done": false},
+	}))
+	is.NoErr(cache.ApplyOptimistic("layer", map[string]interface{}{
+		"__typename": "Todo", "id": "1", "done": true,
+	}))
+	fields, ok := cache.Entity("Todo:1")
+	is.True(ok)
+	is.Equal(fields["done"], true)
+	cache.Rollback("layer")
+	fields, _ = cache.Entity("Todo:1")
+	is.Equal(fields["done"], false)
+	is.Equal(events, []CacheEventType{CacheWrite, CacheOptimisticApply, CacheOptimisticRollback})
+}
+
+func TestRunOptimistic(t *testing.T) {
+	is := is.New(t)
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			io.WriteString(w, `{"errors":[{"message":"nope"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"toggle":{"__typename":"Todo","id":"1","done":true}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache()
+	client := NewClient(srv.URL, WithCache(cache))
+	is.NoErr(cache.Write("q", map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": "1", "done": false},
+	}))
+	var seen []interface{}
+	cache.Listen(func(e CacheEvent) {
+		if e.Type == CacheOptimisticApply {
+			fields, _ := cache.Entity("Todo:1")
+			seen = append(seen, fields["done"])
+		}
+	})
+	optimistic := map[string]interface{}{"__typename": "Todo", "id": "1", "done": true}
+
+	err := client.RunOptimistic(ctx, NewRequest("mutation { toggle }"), optimistic, nil)
+	is.True(err != nil)
+	fields, _ := cache.Entity("Todo:1")
+	is.Equal(fields["done"], false) // rolled back
+
+	fail = false
+	err = client.RunOptimistic(ctx, NewRequest("mutation { toggle }"), optimistic, nil)
+	is.NoErr(err)
+	fields, _ = cache.Entity("Todo:1")
+	is.Equal(fields["done"], true) // server result
+	is.Equal(seen, []interface{}{true, true})
+
+	err = NewClient(srv.URL).RunOptimistic(ctx, NewRequest("mutation { toggle }"), optimistic, nil)
+	is.True(errors.Is(err, ErrNoCache))
+}
+
+func TestCacheResolvesOptimisticIDs(t *testing.T) {
+	is := is.New(t)
+	ids := NewIDMap()
+	cache := NewCache(WithCacheIDMap(ids))
+	ids.Set("tmp-1", "42")
+	is.NoErr(cache.Write("q", map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": "tmp-1", "title": "a"},
+	}))
+	_, ok := cache.Entity("Todo:42")
+	is.True(ok)
+}
+
+func TestWatchQuery(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"todo":{"__typename":"Todo","id":"1","title":"a"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache()
+	client := NewClient(srv.URL, WithCache(cache))
+	results := make(chan string, 3)
+	err := client.WatchQuery(ctx, NewRequest("query { todo }"), func(data json.RawMessage) {
+		results <- string(data)
+	})
+	is.NoErr(err)
+	is.Equal(<-results, `{"todo":{"__typename":"Todo","id":"1","title":"a"}}`)
+
+	// unrelated writes are not delivered
+	is.NoErr(cache.Write("other", map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": "2", "title": "x"},
+	}))
+	is.NoErr(cache.Write("other", map[string]interface{}{
+		"todo": map[string]interface{}{"__typename": "Todo", "id": "1", "title": "b"},
+	}))
+	is.Equal(<-results, `{"todo":{"__typename":"Todo","id":"1","title":"b"}}`)
+	select {
+	case r := <-results:
+		t.Fatalf("unexpected result %s", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCacheKeyWithHeaders(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"greeting":"`+r.Header.Get("Accept-Language")+`"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache(WithCacheKey(CacheKeyWithHeaders("Accept-Language")))
+	client := NewClient(srv.URL, WithCache(cache))
+	en := NewRequest("{ greeting }")
+	en.SetHeader("Accept-Language", "en")
+	fr := NewRequest("{ greeting }")
+	fr.SetHeader("Accept-Language", "fr")
+	is.NoErr(client.Run(ctx, en, nil))
+	is.NoErr(client.Run(ctx, fr, nil))
+	is.True(cache.Key(en) != cache.Key(fr))
+
+	var resp struct{ Greeting string }
+	ok, err := cache.ReadInto(cache.Key(en), &resp)
+	is.NoErr(err)
+	is.True(ok)
+	is.Equal(resp.Greeting, "en")
+	ok, err = cache.ReadInto(cache.Key(fr), &resp)
+	is.NoErr(err)
+	is.True(ok)
+	is.Equal(resp.Greeting, "fr")
+}
+
+func TestCacheVary(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Accept-Encoding, authorization")
+		io.WriteString(w, `{"data":{"me":"`+r.Header.Get("Authorization")+`"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache()
+	client := NewClient(srv.URL, WithCache(cache))
+	alice := NewRequest("{ me }")
+	alice.SetHeader("Authorization", "alice")
+	bob := NewRequest("{ me }")
+	bob.SetHeader("Authorization", "bob")
+	is.NoErr(client.Run(ctx, alice, nil))
+	is.NoErr(client.Run(ctx, bob, nil))
+	results, _ := cache.Len()
+	is.Equal(results, 2)
+
+	var resp struct{ Me string }
+	ok, err := cache.ReadInto(cache.Key(alice), &resp)
+	is.NoErr(err)
+	is.True(ok)
+	is.Equal(resp.Me, "alice")
+	ok, _ = cache.ReadInto(cache.Key(NewRequest("{ me }")), &resp)
+	is.True(!ok) // anonymous requests don't see other users' results
+}
+
+func TestCacheVaryDefaultHeaders(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Authorization")
+		io.WriteString(w, `{"data":{"me":"`+r.Header.Get("Authorization")+`"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache()
+	kill := NewKillSwitch(nil)
+	client := NewClient(srv.URL, WithCache(cache), WithKillSwitch(kill))
+	alice := client.With(WithDefaultHeaders(http.Header{"Authorization": {"alice"}}))
+	bob := client.With(WithDefaultHeaders(http.Header{"Authorization": {"bob"}}))
+	req := NewRequest("query Me { me }")
+	is.NoErr(alice.Run(ctx, req, nil))
+	is.NoErr(bob.Run(ctx, req, nil))
+	results, _ := cache.Len()
+	is.Equal(results, 2) // the default headers keep the results apart
+
+	kill.Disable("Me", OperationServeCached)
+	var resp struct{ Me string }
+	is.NoErr(alice.Run(ctx, req, &resp))
+	is.Equal(resp.Me, "alice")
+	is.NoErr(bob.Run(ctx, req, &resp))
+	is.Equal(resp.Me, "bob")
+}
+
+func TestCacheVaryHeadersOption(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(WithCacheVaryHeaders("X-Tenant"))
+	a := NewRequest("{ me }")
+	a.SetHeader("X-Tenant", "a")
+	b := NewRequest("{ me }")
+	b.SetHeader("X-Tenant", "b")
+	is.True(cache.Key(a) != cache.Key(b))
+	is.NoErr(cache.writeResponse(a, a.Header, http.Header{"Vary": {"*"}}, map[string]interface{}{"me": "a"}))
+	results, _ := cache.Len()
+	is.Equal(results, 0) // Vary: * is never stored
+}
@@ -0,0 +1,85 @@
+package gographql
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WithCacheVaryHeaders makes results vary by the named request headers in
+// addition to those listed in the Vary header of the responses, e.g. to
+// keep the results of different users apart behind a shared client even
+// when the server does not send Vary.
+func WithCacheVaryHeaders(names ...string) CacheOption {
+	return func(cache *Cache) {
+		for _, name := range names {
+			cache.varyHeaders = append(cache.varyHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// varyKey returns the result key base, extended with the values in header
+// of the request headers the result varies by.
+func (c *Cache) varyKey(base string, header http.Header) string {
+	c.mu.RLock()
+	names := c.vary[base]
+	c.mu.RUnlock()
+	if len(names) == 0 && len(c.varyHeaders) == 0 {
+		return base
+	}
+	h := defaultCrypto.SHA256()
+	for _, name := range mergeHeaderNames(c.varyHeaders, names) {
+		json.NewEncoder(h).Encode([]interface{}{name, header.Values(name)})
+	}
+	return base + ":vary:" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// writeResponse stores the data of the response to req, sent with
+// reqHeader, honouring the Vary header of the response. Responses with
+// "Vary: *" are not stored.
+func (c *Cache) writeResponse(req *Request, reqHeader, header http.Header, data interface{}) error {
+	req = req.Clone()
+	base := c.resultKey(req)
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	names = mergeHeaderNames(nil, names)
+	c.mu.Lock()
+	if len(names) > 0 {
+		if c.vary == nil {
+			c.vary = make(map[string][]string)
+		}
+		c.vary[base] = names
+	} else {
+		delete(c.vary, base)
+	}
+	c.mu.Unlock()
+	return c.Write(c.varyKey(base, reqHeader), data)
+}
+
+// mergeHeaderNames returns the sorted union of a and b.
+func mergeHeaderNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, names := range [][]string{a, b} {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
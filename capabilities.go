@@ -0,0 +1,229 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Capabilities describes the optional features supported by a GraphQL
+// server, as detected by Client.Probe.
+type Capabilities struct {
+	// GET reports whether queries may be sent as HTTP GET requests.
+	GET bool
+	// Batching reports whether several operations may be sent as a JSON
+	// array in one request.
+	Batching bool
+	// PersistedQueries reports support for Automatic Persisted Queries.
+	PersistedQueries bool
+	// MultipartUploads reports support for the GraphQL multipart request
+	// spec used for file uploads.
+	MultipartUploads bool
+	// RequestCompression reports whether gzip compressed request bodies
+	// are accepted.
+	RequestCompression bool
+	// Defer and Stream report support for incremental delivery.
+	Defer  bool
+	Stream bool
+	// Directives lists the directives declared by the schema, if
+	// introspection is enabled.
+	Directives []string
+}
+
+// HasDirective reports whether the schema declares the named directive.
+func (caps *Capabilities) HasDirective(name string) bool {
+	for _, d := range caps.Directives {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities returns the capabilities detected by the last successful
+// Probe, or nil if the server was never probed.
+func (c *Client) Capabilities() *Capabilities {
+	return c.caps.Load()
+}
+
+// Probe detects which optional features the server supports by sending a
+// few cheap requests, and caches the result on the client. It is
+// typically called once at startup.
+//
+// Once probed, the client stops using the features the server lacks:
+// compressed bodies, Automatic Persisted Queries and GET fall back to
+// plain JSON POST requests, RunBatch sends its operations one by one, and
+// requests with files fail with ErrUploadsUnsupported under
+// UseMultipartRequestSpec.
+func (c *Client) Probe(ctx context.Context) (*Capabilities, error) {
+	const probeQuery = "{__typename}"
+	caps := &Capabilities{}
+	endpoint, err := c.endpoint(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if caps.GET, err = c.probe(ctx, func() (*http.Request, error) {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		q.Set("query", probeQuery)
+		u.RawQuery = q.Encode()
+		return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	}, func(body []byte) bool {
+		return bytes.Contains(body, []byte(`"__typename"`))
+	}); err != nil {
+		return nil, err
+	}
+
+	if caps.Batching, err = c.probe(ctx, func() (*http.Request, error) {
+		return c.probeJSON(ctx, endpoint, []map[string]string{{"query": probeQuery}})
+	}, func(body []byte) bool {
+		var batch []json.RawMessage
+		return json.Unmarshal(body, &batch) == nil && len(batch) == 1
+	}); err != nil {
+		return nil, err
+	}
+
+	if caps.PersistedQueries, err = c.probe(ctx, func() (*http.Request, error) {
+		return c.probeJSON(ctx, endpoint, map[string]interface{}{
+			"extensions": map[string]interface{}{
+				"persistedQuery": map[string]interface{}{
+					"version":    1,
+					"sha256Hash": hexSHA256(c.crypto, []byte(probeQuery)),
+				},
+			},
+		})
+	}, func(body []byte) bool {
+		return bytes.Contains(body, []byte("PersistedQueryNotFound")) ||
+			bytes.Contains(body, []byte(`"__typename"`))
+	}); err != nil {
+		return nil, err
+	}
+
+	if caps.MultipartUploads, err = c.probe(ctx, func() (*http.Request, error) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("operations", `{"query":"`+probeQuery+`","variables":{}}`)
+		writer.WriteField("map", `{}`)
+		writer.Close()
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", writer.FormDataContentType())
+		r.Header.Set("GraphQL-Preflight", "1")
+		return r, nil
+	}, func(body []byte) bool {
+		return bytes.Contains(body, []byte(`"__typename"`))
+	}); err != nil {
+		return nil, err
+	}
+
+	if caps.RequestCompression, err = c.probe(ctx, func() (*http.Request, error) {
+		body, err := gzipBytes([]byte(`{"query":"` + probeQuery + `"}`))
+		if err != nil {
+			return nil, err
+		}
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		r.Header.Set("Content-Encoding", "gzip")
+		return r, nil
+	}, func(body []byte) bool {
+		return bytes.Contains(body, []byte(`"__typename"`))
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err = c.probe(ctx, func() (*http.Request, error) {
+		return c.probeJSON(ctx, endpoint, map[string]string{"query": "{__schema{directives{name}}}"})
+	}, func(body []byte) bool {
+		var resp struct {
+			Data struct {
+				Schema struct {
+					Directives []struct {
+						Name string `json:"name"`
+					} `json:"directives"`
+				} `json:"__schema"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(body, &resp) != nil {
+			return false
+		}
+		for _, d := range resp.Data.Schema.Directives {
+			caps.Directives = append(caps.Directives, d.Name)
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	caps.Defer = caps.HasDirective("defer")
+	caps.Stream = caps.HasDirective("stream")
+
+	c.caps.Store(caps)
+	if c.debug(ctx) {
+		infof(c.log, "probed %s: %+v", endpoint, *caps)
+	}
+	return caps, nil
+}
+
+// lacks reports whether the last Probe found that the server does not
+// support the feature reported by has. Features are assumed to be
+// supported until the server is probed.
+func (c *Client) lacks(has func(*Capabilities) bool) bool {
+	caps := c.caps.Load()
+	return caps != nil && !has(caps)
+}
+
+// probe sends the request built by newRequest and reports whether the
+// response was successful and accepted by check. Only transport errors
+// are returned.
+func (c *Client) probe(ctx context.Context, newRequest func() (*http.Request, error), check func(body []byte) bool) (bool, error) {
+	r, err := newRequest()
+	if err != nil {
+		return false, err
+	}
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "application/json; charset=utf-8")
+	}
+	c.setHeaders(ctx, r, &Request{})
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if c.debug(ctx) {
+		c.log.Debugf("probe %s %s: %d %s", r.Method, r.URL, res.StatusCode, body)
+	}
+	if res.StatusCode >= http.StatusInternalServerError || !strings.Contains(res.Header.Get("Content-Type"), "json") && !json.Valid(body) {
+		return false, nil
+	}
+	return check(body), nil
+}
+
+func (c *Client) probeJSON(ctx context.Context, endpoint string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return r, nil
+}
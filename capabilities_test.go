@@ -0,0 +1,118 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestProbe(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+			return
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"errors":[{"message":"unsupported content type"}]}`)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		body := string(b)
+		switch {
+		case strings.HasPrefix(body, "["):
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"errors":[{"message":"batching disabled"}]}`)
+		case strings.Contains(body, "persistedQuery"):
+			io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotFound"}]}`)
+		case strings.Contains(body, "__schema"):
+			io.WriteString(w, `{"data":{"__schema":{"directives":[{"name":"include"},{"name":"defer"}]}}}`)
+		default:
+			io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+		}
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	is.Equal(client.Capabilities(), nil)
+	caps, err := client.Probe(ctx)
+	is.NoErr(err)
+	is.True(caps.GET)
+	is.True(!caps.Batching)
+	is.True(caps.PersistedQueries)
+	is.True(!caps.MultipartUploads)
+	is.True(caps.RequestCompression)
+	is.True(caps.Defer)
+	is.True(!caps.Stream)
+	is.True(caps.HasDirective("include"))
+	is.Equal(client.Capabilities(), caps)
+}
+
+func TestProbeUnreachable(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	client := NewClient(srv.URL)
+	_, err := client.Probe(context.Background())
+	is.True(err != nil)
+	is.Equal(client.Capabilities(), nil)
+}
+
+func TestProbeDisablesFeatures(t *testing.T) {
+	is := is.New(t)
+	var probed bool
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		b, _ := io.ReadAll(r.Body)
+		body := string(b)
+		if probed {
+			requests = append(requests, r.Method+" "+body)
+		}
+		switch {
+		case r.Method == http.MethodGet, strings.HasPrefix(body, "["),
+			strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/"):
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"errors":[{"message":"unsupported"}]}`)
+		case strings.Contains(body, "persistedQuery"):
+			io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotSupported"}]}`)
+		default:
+			io.WriteString(w, `{"data":{"value":"ok"}}`)
+		}
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseGET(), WithAutomaticPersistedQueries(), UseMultipartRequestSpec())
+	_, err := client.Probe(ctx)
+	is.NoErr(err)
+	probed = true
+
+	var resp struct{ Value string }
+	is.NoErr(client.Run(ctx, NewRequest("{ value }"), &resp))
+	is.Equal(resp.Value, "ok")
+	is.Equal(len(requests), 1)
+	is.True(strings.HasPrefix(requests[0], `POST {"query":"{ value }"`)) // no GET, no APQ hash
+
+	requests = nil
+	var a, b struct{ Value string }
+	is.NoErr(client.RunBatch(ctx, []*Request{NewRequest("{ value }"), NewRequest("{ value }")}, []interface{}{&a, &b}))
+	is.Equal(len(requests), 2) // one request per operation
+	is.Equal(b.Value, "ok")
+
+	req := NewRequest(`mutation ($file: Upload!) { upload(file: $file) }`)
+	req.File("file", "report.txt", strings.NewReader("report"))
+	is.True(errors.Is(client.Run(ctx, req, nil), ErrUploadsUnsupported))
+}
@@ -40,7 +40,12 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // ErrSendFilesPostField cannot send files with PostFields option.
@@ -55,6 +60,14 @@ var ErrEncodingRequestBody = errors.New("encoding request body error")
 // ErrDecodingResponse decoding response error.
 var ErrDecodingResponse = errors.New("decoding response error")
 
+// ErrNullData the server returned null data without errors.
+var ErrNullData = errors.New("graphql server returned null data without errors")
+
+// acceptGraphQLResponse is the Accept header of requests, preferring the
+// media type of the GraphQL over HTTP specification, whose error
+// responses have 4xx statuses.
+const acceptGraphQLResponse = "application/graphql-response+json, application/json;q=0.9"
+
 // HTTPClient custom HTTP client interface.
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -62,7 +75,8 @@ type HTTPClient interface {
 
 // Client is a client for interacting with a GraphQL API.
 type Client struct {
-	// Endpoint GraphQL Server URL.
+	// Endpoint GraphQL Server URL. It may contain {name} placeholders
+	// resolved per request, see ContextWithEndpointParams.
 	Endpoint string
 	// DebugLog enables ddebug logging.
 	DebugLog bool
@@ -72,20 +86,149 @@ type Client struct {
 	httpClient       HTTPClient
 	useMultipartForm bool
 	log              Logger
+	har              *HARRecorder
+	idGenerator      IDGenerator
+	ids              *IDMap
+	cache            *Cache
+	transforms       []fieldTransform
+	labeler          Labeler
+	metrics          Metrics
+	propagateTrace   bool
+	caps             *atomic.Pointer[Capabilities]
+	validateHeader   string
+	consistency      *ConsistencyTokens
+	drift            *ShapeRecorder
+	killSwitch       *KillSwitch
+	deadlines        *DeadlinePolicy
+	defaultHeader    http.Header
+	compression      *requestCompression
+	latencyBreakdown bool
+	slowQuery        time.Duration
+	debugSampling    bool
+	debugRate        float64
+	logLevel         LogLevel
+	reporter         ErrorReporter
+	journal          *journal
+	impersonation    *Impersonation
+	affinity         *SessionAffinity
+	nullDataError    bool
+	apq              *persistedQueries
+	groupErrors      bool
+	trusted          *trustedIndex
+	warnings         *warningFilter
+	uploadSpec       bool
+	useGET           bool
+	rawQueryBody     bool
+	opEndpoints      map[string]string
+	inFlight         *flightGroup
+	http3            http.RoundTripper
+	encryption       KeyProvider
+	crypto           Crypto
+	partialData      bool
+	events           *eventBus
+	strictDecoding   bool
+	useNumber        bool
+	codec            Codec
+	inFlightBytes    *inFlightBytes
+	ladder           *transportLadder
+
+	subscriptionEndpoint  string
+	subscriptionProtocols []string
+	reconnect             *ReconnectPolicy
+	subConns              *subConnPool
+	connectionInit        json.RawMessage
+	connectionAckTimeout  time.Duration
+	keepAlive             time.Duration
+	idleTimeout           time.Duration
+	appSync               AppSyncAuth
+	connectionInitFunc    func(ctx context.Context) (interface{}, error)
+	authRefresh           *SubscriptionAuthRefresh
+	subMiddleware         []SubscriptionMiddleware
+	// optionErrs are the problems found while applying options,
+	// reported by NewClientE.
+	optionErrs []error
 }
 
 // NewClient makes a new Client capable of making GraphQL requests.
 func NewClient(endpoint string, opts ...ClientOption) *Client {
 	c := &Client{
 		Endpoint: endpoint,
+		ids:      NewIDMap(),
 	}
 	for _, optionFunc := range opts {
 		optionFunc(c)
 	}
+	c.init()
+	return c
+}
+
+// With returns a shallow copy of c with opts applied, for per-feature
+// tweaks such as different headers or logging. The copy shares the
+// transport, and with it the connection pool, as well as the cache and
+// probed capabilities of c unless opts replace them.
+func (c *Client) With(opts ...ClientOption) *Client {
+	clone := *c
+	clone.transforms = slices.Clip(c.transforms)
+	clone.optionErrs = nil
+	clone.subConns = nil
+	if c.inFlight != nil {
+		// clones may send the same request with other credentials
+		clone.inFlight = &flightGroup{}
+	}
+	if h, ok := clone.httpClient.(*harClient); ok {
+		clone.httpClient = h.next
+	}
+	if h, ok := clone.httpClient.(*http3Client); ok {
+		clone.httpClient = h.next
+	}
+	for _, optionFunc := range opts {
+		optionFunc(&clone)
+	}
+	clone.init()
+	return &clone
+}
+
+// init sets the defaults for options that were not given.
+func (c *Client) init() {
+	if c.caps == nil {
+		c.caps = new(atomic.Pointer[Capabilities])
+	}
 	if c.httpClient == nil {
 		c.httpClient = http.DefaultClient
 	}
-	return c
+	if c.crypto == nil {
+		c.crypto = defaultCrypto
+	}
+	if c.codec == nil {
+		c.codec = stdCodec{}
+	}
+	if c.log == nil {
+		c.log = createDefaultLogger()
+	}
+	c.log = withLevel(c.log, c.logLevel)
+	if c.idGenerator == nil {
+		c.idGenerator = UUIDv7()
+	}
+	if c.subConns == nil {
+		c.subConns = &subConnPool{}
+	}
+	if c.events == nil {
+		c.events = &eventBus{listeners: make(map[int]func(Event))}
+	}
+	c.forwardCacheEvictions()
+	if c.cache != nil {
+		c.cache.mu.Lock()
+		if c.cache.ids == nil {
+			c.cache.ids = c.ids
+		}
+		c.cache.mu.Unlock()
+	}
+	if c.http3 != nil {
+		c.httpClient = newHTTP3Client(c.http3, c.httpClient, c.emit)
+	}
+	if c.har != nil {
+		c.httpClient = &harClient{next: c.httpClient, rec: c.har}
+	}
 }
 
 // Run executes the query and unmarshals the response from the data field
@@ -94,131 +237,641 @@ func NewClient(endpoint string, opts ...ClientOption) *Client {
 // If the request fails or the server returns an error, the first error
 // will be returned.
 func (c *Client) Run(ctx context.Context, req *Request, resp interface{}) error {
+	return c.run(ctx, req, resp, nil)
+}
+
+// responseMeta collects details of the HTTP exchange for callers that
+// need more than the decoded data.
+type responseMeta struct {
+	// body is the raw response body, only kept when keepBody is set.
+	body []byte
+	// keepBody asks for the raw response body.
+	keepBody bool
+	// captured is a copy of the response body, kept when it is at most
+	// capture bytes.
+	captured []byte
+	capture  int
+	// size is the size of the response body as received.
+	size int64
+	// statusCode and header are taken from the HTTP response.
+	statusCode int
+	header     http.Header
+	// extensions is the extensions field of the response.
+	extensions map[string]interface{}
+	// timings is the latency breakdown, with WithLatencyBreakdown.
+	timings *Timings
+	// cached is set when the result was served from the cache.
+	cached bool
+}
+
+func (c *Client) run(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
-	if len(req.files) > 0 && !c.useMultipartForm {
+	req = req.Clone()
+	if len(req.files) > 0 && !c.useMultipartForm && !c.uploadSpec {
 		return ErrSendFilesPostField
 	}
+	ctx = c.sampleDebug(ctx)
+	ctx, op := c.operation(ctx, req)
+	if c.journal != nil {
+		if meta == nil {
+			meta = &responseMeta{}
+		}
+		meta.capture = maxJournalResponse
+	}
+	start := time.Now()
+	var killed bool
+	var err error
+	if c.impersonation != nil {
+		err = c.impersonation.audit(ctx, req)
+	}
+	// the operations of a batch were checked one by one by RunBatch
+	if err == nil && c.killSwitch != nil && req.batch == nil {
+		killed, err = c.killed(ctx, req, op, resp)
+		if killed && err == nil && meta != nil {
+			meta.cached = true
+		}
+	}
+	if err == nil && !killed {
+		err = c.sendAdmitted(ctx, req, resp, meta)
+	}
+	elapsed := time.Since(start)
+	if c.slowQuery > 0 && elapsed >= c.slowQuery {
+		c.logSlowQuery(op, req, elapsed, err)
+	}
+	if c.metrics != nil {
+		c.metrics.ObserveOperation(ctx, op, elapsed, err)
+	}
+	if err != nil && c.reporter != nil {
+		c.reportError(ctx, req, err)
+	}
+	if c.journal != nil {
+		c.recordJournal(start, elapsed, op, req, meta, err)
+	}
+	return err
+}
+
+// sendAdmitted sends req once admitted by the in-flight bytes limit.
+func (c *Client) sendAdmitted(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.inFlightBytes == nil {
+		return c.send(ctx, req, resp, meta)
+	}
+	ctx, release, err := c.inFlightBytes.admit(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return c.send(ctx, req, resp, meta)
+}
+
+// send dispatches req, sharing the call with identical requests in flight
+// when requests are deduplicated.
+func (c *Client) send(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.inFlight != nil {
+		return c.sendShared(ctx, req, resp, meta)
+	}
+	return c.sendOne(ctx, req, resp, meta)
+}
+
+// sendOne dispatches req within the budget allowed by the deadline policy.
+func (c *Client) sendOne(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.deadlines == nil {
+		return c.dispatch(ctx, req, resp, meta)
+	}
+	ctx, cancel, err := c.deadlines.budget(ctx)
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return c.dispatch(ctx, req, resp, meta)
+}
+
+// dispatch sends req using the transport selected by the client options.
+func (c *Client) dispatch(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.encryption != nil && len(req.files) > 0 {
+		return fmt.Errorf("%w: file uploads cannot be encrypted", ErrEncryption)
+	}
+	if req.batch != nil {
+		return c.postBatch(ctx, req, resp, meta)
+	}
+	if c.trusted != nil {
+		id, ok := c.trusted.lookup(req.q)
+		switch {
+		case ok && !c.useMultipartForm && len(req.files) == 0:
+			return c.runTrusted(ctx, req, id, resp, meta)
+		case !ok && !c.trusted.AllowUnknown:
+			return ErrUntrustedDocument
+		}
+	}
+	if c.uploadSpec && len(req.files) > 0 {
+		if c.lacks(func(caps *Capabilities) bool { return caps.MultipartUploads }) {
+			return ErrUploadsUnsupported
+		}
+		return c.runWithUploads(ctx, req, resp, meta)
+	}
 	if c.useMultipartForm {
-		return c.runWithPostFields(ctx, req, resp)
+		return c.runWithPostFields(ctx, req, resp, meta)
+	}
+	return c.runWithJSON(ctx, req, resp, meta)
+}
+
+func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.rawQueryBody && c.encryption == nil && !c.usesGET(req) {
+		return c.runWithRawQuery(ctx, req, resp, meta)
+	}
+	if c.apq != nil && !c.apq.unsupported.Load() && !c.lacks(func(caps *Capabilities) bool { return caps.PersistedQueries }) &&
+		c.useTransport(ctx, req, transportAPQ) {
+		return c.runPersisted(ctx, req, resp, meta)
 	}
-	return c.runWithJSON(ctx, req, resp)
+	return c.postQuery(ctx, req, resp, meta)
 }
 
-func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}) error {
-	var requestBody bytes.Buffer
+// postQuery sends the query, variables and operation name of req as JSON.
+func (c *Client) postQuery(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	requestBody := getBodyBuffer()
+	defer requestBody.release()
+	_, name := operationInfo(req.q)
 	requestBodyObj := struct {
-		Query     string                 `json:"query"`
-		Variables map[string]interface{} `json:"variables"`
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables"`
+		OperationName string                 `json:"operationName,omitempty"`
 	}{
-		Query:     req.q,
-		Variables: req.vars,
+		Query:         req.q,
+		Variables:     req.vars,
+		OperationName: name,
 	}
-	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
+	if err := c.encodeBody(&requestBody.Buffer, requestBodyObj); err != nil {
 		return errors.Join(ErrEncodingRequestBody, err)
 	}
-	if c.DebugLog {
+	if c.debug(ctx) {
 		c.log.Debugf("variables: %+v", req.vars)
 		c.log.Debugf("query: %s", req.q)
 	}
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, &requestBody)
+	return c.postJSON(ctx, req, requestBody, resp, meta)
+}
+
+func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	if c.debug(ctx) {
+		c.log.Debugf("variables: %+v", req.vars)
+		c.log.Debugf("num of files: %d", len(req.files))
+		c.log.Debugf("query: %s", req.q)
+	}
+	return c.postMultipart(ctx, req, func(writer *multipart.Writer) error {
+		if err := writer.WriteField("query", req.q); err != nil {
+			return fmt.Errorf("write query field error: %w", err)
+		}
+		if len(req.vars) > 0 {
+			variablesField, err := writer.CreateFormField("variables")
+			if err != nil {
+				return fmt.Errorf("create variables field error: %w", err)
+			}
+			if err := json.NewEncoder(variablesField).Encode(req.vars); err != nil {
+				return fmt.Errorf("encode variables error: %w", err)
+			}
+		}
+		for i := range req.files {
+			if err := writeFile(writer, req.files[i].Field, req.files[i], req.progress); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, resp, meta)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeFile adds f to the multipart body as the file part field,
+// reporting the progress to progress if not nil.
+func writeFile(writer *multipart.Writer, field string, f File, progress UploadProgress) error {
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(field), quoteEscaper.Replace(f.Name)))
+	h.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(h)
+	if err != nil {
+		return fmt.Errorf("create form file error: %w", err)
+	}
+	r, err := f.reader()
+	if err != nil {
+		return fmt.Errorf("open file error: %w", err)
+	}
+	defer r.Close()
+	var src io.Reader = r
+	if progress != nil {
+		size := f.Size
+		if size <= 0 {
+			size = readerSize(r)
+			if f.Open == nil && f.R != nil {
+				size = readerSize(f.R)
+			}
+		}
+		progress(f, 0, size)
+		src = &progressReader{r: r, file: f, size: size, progress: progress}
+	}
+	if _, err := io.Copy(part, src); err != nil {
+		return fmt.Errorf("preparing file error: %w", err)
+	}
+	return nil
+}
+
+// postMultipart streams the multipart body written by write to the
+// endpoint and decodes the response. The body is produced while it is
+// sent, through a pipe, so memory use does not depend on the size of the
+// files; it is therefore never compressed. write is called again when the
+// body must be resent, such as after a redirect.
+func (c *Client) postMultipart(ctx context.Context, req *Request, write func(*multipart.Writer) error, resp interface{}, meta *responseMeta) error {
+	endpoint, err := c.endpoint(ctx, req)
 	if err != nil {
 		return err
 	}
-	r.Header.Set("Content-Type", "application/json; charset=utf-8")
-	r.Header.Set("Accept", "application/json; charset=utf-8")
-	for key, values := range req.Header {
-		for _, value := range values {
-			r.Header.Add(key, value)
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		pipes    []*io.PipeReader
+		writeErr error
+	)
+	// the writers are stopped and waited for before returning, so none
+	// outlives the request.
+	defer func() {
+		mu.Lock()
+		for _, pr := range pipes {
+			pr.Close()
 		}
+		mu.Unlock()
+		wg.Wait()
+	}()
+	open := func() io.ReadCloser {
+		pr, pw := io.Pipe()
+		mu.Lock()
+		pipes = append(pipes, pr)
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			writer := multipart.NewWriter(pw)
+			writer.SetBoundary(boundary)
+			err := write(writer)
+			if err == nil {
+				if err = writer.Close(); err != nil {
+					err = fmt.Errorf("close writer error: %w", err)
+				}
+			}
+			if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				mu.Lock()
+				writeErr = err
+				mu.Unlock()
+			}
+			pw.CloseWithError(err)
+		}()
+		return pr
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, open())
+	if err != nil {
+		return err
 	}
-	return c.doHTTP(ctx, r, resp)
+	r.GetBody = func() (io.ReadCloser, error) {
+		return open(), nil
+	}
+	r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+	r.Header.Set("Accept", acceptGraphQLResponse)
+	c.setHeaders(ctx, r, req)
+	err = c.doHTTP(ctx, req, r, resp, meta)
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil && writeErr != nil {
+		return writeErr
+	}
+	return err
 }
 
-func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp interface{}) error {
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-	if err := writer.WriteField("query", req.q); err != nil {
-		return fmt.Errorf("write query field error: %w", err)
+// post sends body to the endpoint, compressed if the client is
+// configured to, and decodes the response.
+func (c *Client) post(ctx context.Context, req *Request, body *bodyBuffer, contentType string, resp interface{}, meta *responseMeta) error {
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return err
 	}
-	var variablesBuf bytes.Buffer
-	if len(req.vars) > 0 {
-		variablesField, err := writer.CreateFormField("variables")
+	compressed := c.encryption == nil && c.shouldCompress(body.Len())
+	var r *http.Request
+	switch {
+	case c.encryption != nil:
+		payload, err := encryptJWE(ctx, c.crypto, c.encryption, body.Bytes())
 		if err != nil {
-			return fmt.Errorf("create variables field error: %w", err)
+			return err
 		}
-		if err := json.NewEncoder(io.MultiWriter(variablesField, &variablesBuf)).Encode(req.vars); err != nil {
-			return fmt.Errorf("encode variables error: %w", err)
+		contentType = joseContentType
+		if r, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload)); err != nil {
+			return err
 		}
-	}
-	for i := range req.files {
-		part, err := writer.CreateFormFile(req.files[i].Field, req.files[i].Name)
-		if err != nil {
-			return fmt.Errorf("create form file error: %w", err)
+	case compressed:
+		payload := getBodyBuffer()
+		defer payload.release()
+		if err := gzipTo(payload, body.Bytes()); err != nil {
+			return errors.Join(ErrEncodingRequestBody, err)
 		}
-		if _, err := io.Copy(part, req.files[i].R); err != nil {
-			return fmt.Errorf("preparing file error: %w", err)
+		if r, err = newBodyRequest(ctx, endpoint, payload); err != nil {
+			return err
+		}
+	default:
+		if r, err = newBodyRequest(ctx, endpoint, body); err != nil {
+			return err
 		}
 	}
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("close writer error: %w", err)
+	c.trackBytes(ctx, int(r.ContentLength))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("Accept", acceptGraphQLResponse)
+	if c.encryption != nil {
+		r.Header.Set("Accept", joseContentType+", "+acceptGraphQLResponse)
 	}
-	if c.DebugLog {
-		c.log.Debugf("variables: %s", variablesBuf.String())
-		c.log.Debugf("num of files: %d", len(req.files))
-		c.log.Debugf("query: %s", req.q)
+	if compressed {
+		r.Header.Set("Content-Encoding", "gzip")
+	}
+	c.setHeaders(ctx, r, req)
+	if c.compression == nil {
+		return c.doHTTP(ctx, req, r, resp, meta)
+	}
+	if meta == nil {
+		meta = &responseMeta{}
+	}
+	err = c.doHTTP(ctx, req, r, resp, meta)
+	if c.compression.negotiate(meta, compressed) {
+		if c.debug(ctx) {
+			c.log.Warnf("server rejected compressed request body, resending uncompressed")
+		}
+		c.emit(Event{Type: EventRetry, Err: err})
+		return c.post(ctx, req, body, contentType, resp, meta)
 	}
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, &requestBody)
+	return err
+}
+
+// newBodyRequest returns a POST request to endpoint whose body is read
+// from buf, which it holds until the body is closed.
+func newBodyRequest(ctx context.Context, endpoint string, buf *bodyBuffer) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	r.Body = buf.reader()
+	r.ContentLength = int64(buf.Len())
+	r.GetBody = func() (io.ReadCloser, error) {
+		return buf.reader(), nil
+	}
+	return r, nil
+}
+
+// outgoingHeader returns the headers req is sent with, including those
+// derived from the client options and ctx.
+func (c *Client) outgoingHeader(ctx context.Context, req *Request) http.Header {
+	r := &http.Request{Header: make(http.Header)}
+	c.setHeaders(ctx, r, req)
+	return r.Header
+}
+
+// setHeaders adds the request headers, and any headers derived from the
+// client options, to r.
+func (c *Client) setHeaders(ctx context.Context, r *http.Request, req *Request) {
+	for key, values := range c.defaultHeader {
+		if _, ok := req.Header[key]; ok {
+			continue
+		}
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
 	}
-	r.Header.Set("Content-Type", writer.FormDataContentType())
-	r.Header.Set("Accept", "application/json; charset=utf-8")
 	for key, values := range req.Header {
 		for _, value := range values {
 			r.Header.Add(key, value)
 		}
 	}
-	return c.doHTTP(ctx, r, resp)
+	if c.propagateTrace {
+		setTraceHeaders(ctx, r.Header)
+	}
+	if c.consistency != nil {
+		c.consistency.attach(ctx, r.Header)
+	}
+	if c.impersonation != nil {
+		c.impersonation.attach(ctx, r.Header)
+	}
+	if c.affinity != nil {
+		c.affinity.attach(ctx, r)
+	}
 }
 
-func (c *Client) doHTTP(ctx context.Context, r *http.Request, resp interface{}) error {
-	gr := &GraphQLResponse{
-		Data: resp,
+func (c *Client) doHTTP(ctx context.Context, req *Request, r *http.Request, resp interface{}, meta *responseMeta) error {
+	// the data is decoded straight into resp, unless it is needed raw
+	var data json.RawMessage
+	gr := struct {
+		Data       interface{}            `json:"data"`
+		Errors     GraphQLErrors          `json:"errors,omitempty"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}{Data: &data}
+	direct := resp != nil && c.decodesDirectly(req)
+	if direct {
+		gr.Data = resp
+	}
+	if meta == nil {
+		meta = &responseMeta{}
 	}
 	r.Close = c.closeReq
-	if c.DebugLog {
+	if c.debug(ctx) {
 		c.log.Debugf("headers: %+v", r.Header)
 	}
+	var tt *timingTrace
+	if c.latencyBreakdown {
+		ctx, tt = newTimingTrace(ctx)
+	}
 	r = r.WithContext(ctx)
 	res, err := c.httpClient.Do(r)
 	if err != nil {
+		if ctx.Err() == nil && c.debug(ctx) {
+			c.log.Errorf("request failed: %v", err)
+		}
 		return err
 	}
 	defer res.Body.Close()
+	if c.affinity != nil {
+		c.affinity.capture(ctx, res)
+	}
 
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return errors.Join(ErrDecodingResponse, err)
+	readStart := time.Now()
+	body := &countingReader{r: res.Body}
+	if c.inFlightBytes != nil {
+		// the bytes are accounted as they are read, so a large response
+		// holds back other runs while it is being read
+		body.read = func(n int) { c.trackBytes(ctx, n) }
+	}
+	var dec Decoder
+	var debugBody *bytes.Buffer
+	var captured *cappedBuffer
+	buffered := meta.keepBody || res.StatusCode != http.StatusOK ||
+		(c.encryption != nil && strings.HasPrefix(res.Header.Get("Content-Type"), joseContentType))
+	meta.body, meta.captured = nil, nil
+	if buffered {
+		// buffered mode: the body is needed as a whole, to decrypt it, to
+		// return it raw or to diagnose a failed request
+		buf := &bytes.Buffer{}
+		if !meta.keepBody {
+			buf = getResponseBuffer()
+			defer putResponseBuffer(buf)
+		}
+		b, err := c.readBody(ctx, buf, body, res)
+		if err != nil {
+			return err
+		}
+		if c.debug(ctx) {
+			c.log.Debugf("response body: %s", b)
+		}
+		if meta.keepBody {
+			meta.body = b
+		}
+		if len(b) <= meta.capture {
+			meta.captured = bytes.Clone(b)
+		}
+		dec = c.codec.NewDecoder(bytes.NewReader(b))
+	} else {
+		// the response is decoded as it is read, without a copy of the
+		// whole body
+		var r io.Reader = body
+		if c.debug(ctx) {
+			debugBody = getResponseBuffer()
+			defer putResponseBuffer(debugBody)
+			r = io.TeeReader(r, debugBody)
+		}
+		if meta.capture > 0 {
+			captured = &cappedBuffer{limit: meta.capture}
+			r = io.TeeReader(r, captured)
+		}
+		dec = c.codec.NewDecoder(r)
+	}
+	meta.statusCode = res.StatusCode
+	meta.header = res.Header
+	var decodeErr error
+	if req.batch != nil {
+		// the results of a batch are decoded by RunBatch
+		decodeErr = dec.Decode(resp)
+	} else {
+		decodeErr = dec.Decode(&gr)
+	}
+	if decodeErr == nil && !buffered {
+		// drain the rest of the body so the connection can be reused
+		_, decodeErr = io.Copy(io.Discard, body)
+	}
+	meta.size = body.n
+	if captured != nil && !captured.overflow {
+		meta.captured = captured.Bytes()
+	}
+	if tt != nil {
+		timings := tt.bodyRead(readStart)
+		meta.timings = &timings
+		if op, ok := OperationFromContext(ctx); ok {
+			op.Timings = &timings
+		}
 	}
-	if c.DebugLog {
-		c.log.Debugf("response body: %s", buf.String())
+	if debugBody != nil {
+		c.log.Debugf("response body: %s", debugBody.String())
 	}
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
+	if decodeErr != nil {
 		if res.StatusCode != http.StatusOK {
 			return fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
 		}
-		return errors.Join(ErrDecodingResponse, err)
+		return errors.Join(ErrDecodingResponse, decodeErr)
+	}
+	if req.batch != nil {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK && len(gr.Errors) == 0 {
+		// a response with another status is only a GraphQL response if it
+		// reports errors, such as the 4xx of application/graphql-response+json
+		return fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
+	}
+	meta.extensions = gr.Extensions
+	if c.consistency != nil {
+		c.consistency.capture(ctx, meta)
+	}
+	if c.warnings != nil {
+		gr.Errors = c.warnings.filter(ctx, req, gr.Errors, gr.Extensions)
+	}
+	if c.nullDataError && len(gr.Errors) == 0 && (len(data) == 0 || string(data) == "null") {
+		return fmt.Errorf("%w; statuscode: %v", ErrNullData, res.StatusCode)
+	}
+	if len(c.transforms) > 0 && len(data) > 0 && string(data) != "null" {
+		if data, err = c.applyTransforms(ctx, data); err != nil {
+			return err
+		}
+	}
+	if resp != nil && !direct && len(data) > 0 {
+		if err := c.decodeData(data, resp); err != nil {
+			if res.StatusCode != http.StatusOK {
+				return fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
+			}
+			return errors.Join(ErrDecodingResponse, err)
+		}
 	}
 	if len(gr.Errors) > 0 {
-		return gr.Errors
+		var err error = gr.Errors
+		if c.groupErrors {
+			err = groupErrors(gr.Errors)
+		}
+		if (c.partialData || req.partialData) && len(data) > 0 && string(data) != "null" {
+			return &PartialDataError{Errors: gr.Errors, err: err}
+		}
+		return err
+	}
+	if c.drift != nil && len(data) > 0 {
+		c.detectDrift(ctx, req, data)
+	}
+	if c.cache != nil && len(data) > 0 && string(data) != "null" {
+		if err := c.cache.writeResponse(req, c.outgoingHeader(ctx, req), res.Header, data); err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
 	}
 	return nil
 }
 
+// decodesDirectly reports whether the data of the response to req can be
+// decoded into the response object along with the rest of the response,
+// as no option needs the raw data or decodes it differently.
+func (c *Client) decodesDirectly(req *Request) bool {
+	return req.batch == nil && len(c.transforms) == 0 && c.cache == nil && c.drift == nil &&
+		!c.strictDecoding && !c.useNumber && !c.nullDataError && !c.partialData && !req.partialData
+}
+
+// readBody reads the whole body of res into buf and returns it,
+// decrypted if it is encrypted.
+func (c *Client) readBody(ctx context.Context, buf *bytes.Buffer, body io.Reader, res *http.Response) ([]byte, error) {
+	if _, err := buf.ReadFrom(body); err != nil {
+		return nil, errors.Join(ErrDecodingResponse, err)
+	}
+	if c.encryption != nil && strings.HasPrefix(res.Header.Get("Content-Type"), joseContentType) {
+		return decryptJWE(ctx, c.crypto, c.encryption, buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// countingReader counts the bytes read from r, also reporting them to
+// read if set.
+type countingReader struct {
+	r    io.Reader
+	n    int64
+	read func(n int)
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	if cr.read != nil && n > 0 {
+		cr.read(n)
+	}
+	return n, err
+}
+
 // DisableDebugLog disable debug level log (disabled by default).
 func (c *Client) DisableDebugLog() *Client {
 	c.DebugLog = false
@@ -241,12 +894,13 @@ func (c *Client) GetLogger() Logger {
 }
 
 // SetLogger set the customized logger for client, will disable log if set to nil.
+// Messages are filtered by the level set with WithLogLevel.
 func (c *Client) SetLogger(log Logger) *Client {
 	if log == nil {
 		c.log = &disableLogger{}
 		return c
 	}
-	c.log = log
+	c.log = withLevel(log, c.logLevel)
 	return c
 }
 
@@ -272,6 +926,32 @@ func UseMultipartForm() ClientOption {
 	}
 }
 
+// WithDefaultHeaders sets headers sent with every request, such as
+// Authorization or tenant headers. A header set on the Request replaces
+// the default of the same name. Using the option more than once, for
+// example with Client.With, adds to the previous defaults.
+func WithDefaultHeaders(header http.Header) ClientOption {
+	return func(client *Client) {
+		merged := client.defaultHeader.Clone()
+		if merged == nil {
+			merged = make(http.Header)
+		}
+		for key, values := range header {
+			merged[http.CanonicalHeaderKey(key)] = slices.Clone(values)
+		}
+		client.defaultHeader = merged
+	}
+}
+
+// WithNullDataError makes operations whose response has null or missing
+// data and no errors fail with ErrNullData, which some servers return on
+// failures, instead of leaving the response object untouched.
+func WithNullDataError() ClientOption {
+	return func(client *Client) {
+		client.nullDataError = true
+	}
+}
+
 // ImmediatelyCloseReqBody will close the req body immediately after each request body is ready.
 func ImmediatelyCloseReqBody() ClientOption {
 	return func(client *Client) {
@@ -1,7 +1,10 @@
 package gographql
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -177,6 +180,22 @@ func TestQueryJSON(t *testing.T) {
 	is.Equal(resp.Value, "some data")
 }
 
+func TestQueryJSONOperationName(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(b), `{"query":"query Viewer { me }","variables":null,"operationName":"Viewer"}`+"\n")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	is.NoErr(client.Run(ctx, NewRequest("query Viewer { me }"), nil))
+}
+
 func TestHeader(t *testing.T) {
 	is := is.New(t)
 
@@ -495,8 +514,246 @@ func TestFile(t *testing.T) {
 	is.NoErr(err)
 }
 
+func TestFileFunc(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/graphql", http.StatusTemporaryRedirect)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer file.Close()
+		b, err := io.ReadAll(file)
+		is.NoErr(err)
+		is.Equal(string(b), `This is a file`)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, UseMultipartForm())
+	opened, closed := 0, 0
+	req := NewRequest("query {}")
+	req.FileFunc("file", "filename.txt", func() (io.ReadCloser, error) {
+		opened++
+		return readCloser{strings.NewReader(`This is a file`), func() { closed++ }}, nil
+	})
+	is.NoErr(client.Run(ctx, req, nil))
+	is.NoErr(client.Run(ctx, req, nil))
+	// bodies are streamed, so the file is opened again for the redirect
+	is.Equal(opened, 4)
+	is.Equal(closed, 4)
+}
+
+func TestFileStreamed(t *testing.T) {
+	is := is.New(t)
+	const size = 8 << 20
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.ContentLength, int64(-1)) // streamed, not buffered
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		n, err := io.Copy(io.Discard, file)
+		is.NoErr(err)
+		is.Equal(n, int64(size))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, UseMultipartForm())
+	req := NewRequest("query {}")
+	req.File("file", "zeros.bin", io.LimitReader(zeros{}, size))
+	is.NoErr(client.Run(ctx, req, nil))
+
+	openErr := errors.New("no such file")
+	req = NewRequest("query {}")
+	req.FileFunc("file", "missing.txt", func() (io.ReadCloser, error) {
+		return nil, openErr
+	})
+	err := client.Run(ctx, req, nil)
+	is.True(errors.Is(err, openErr))
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func()
+}
+
+func (r readCloser) Close() error {
+	r.close()
+	return nil
+}
+
+func TestWith(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	rec := NewHARRecorder()
+	parent := NewClient(srv.URL, WithHARRecorder(rec))
+	var observed int
+	child := parent.With(WithMetrics(metricsFunc(func(ctx context.Context, op *Operation, duration time.Duration, err error) {
+		observed++
+	})))
+	is.NoErr(child.Run(ctx, NewRequest("query { a }"), nil))
+	is.NoErr(parent.Run(ctx, NewRequest("query { a }"), nil))
+	is.Equal(observed, 1)  // the parent is unchanged
+	is.Equal(rec.Len(), 2) // the recorder is shared, not wrapped twice
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	is := is.New(t)
+	var got []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithDefaultHeaders(http.Header{
+		"Authorization": {"Bearer token"},
+		"x-tenant":      {"acme"},
+	}))
+	req := NewRequest("query { a }")
+	req.SetHeader("X-Tenant", "globex")
+	is.NoErr(client.Run(ctx, req, nil))
+	is.NoErr(client.With(WithDefaultHeaders(http.Header{"X-Feature": {"beta"}})).Run(ctx, NewRequest("query { a }"), nil))
+	is.Equal(got[0].Get("Authorization"), "Bearer token")
+	is.Equal(got[0].Values("X-Tenant"), []string{"globex"}) // the request wins
+	is.Equal(got[1].Get("X-Tenant"), "acme")
+	is.Equal(got[1].Get("X-Feature"), "beta")
+}
+
+func TestNullDataError(t *testing.T) {
+	is := is.New(t)
+	body := `{"data":null}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp struct{ A string }
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest("query { a }"), &resp)) // passed through
+	client := NewClient(srv.URL, WithNullDataError())
+	is.True(errors.Is(client.Run(ctx, NewRequest("query { a }"), &resp), ErrNullData))
+	body = `{}`
+	is.True(errors.Is(client.Run(ctx, NewRequest("query { a }"), &resp), ErrNullData))
+	body = `{"data":null,"errors":[{"message":"boom"}]}`
+	is.Equal(client.Run(ctx, NewRequest("query { a }"), &resp).Error(), "graphql: boom")
+	body = `{"data":{"a":"ok"}}`
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), &resp))
+}
+
 type roundTripperFunc func(req *http.Request) (*http.Response, error)
 
 func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+func TestGraphQLResponseJSON(t *testing.T) {
+	is := is.New(t)
+	status, body := http.StatusBadRequest, `{"errors":[{"message":"Cannot query field \"b\""}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Accept"), "application/graphql-response+json, application/json;q=0.9")
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	err := client.Run(ctx, NewRequest("query { b }"), nil)
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+	is.Equal(gqlErrs[0].Message, `Cannot query field "b"`)
+
+	// a JSON body without errors is not a GraphQL response
+	status, body = http.StatusUnauthorized, `{"message":"unauthorized"}`
+	err = client.Run(ctx, NewRequest("query { a }"), nil)
+	is.True(errors.Is(err, ErrGraphqlServerError))
+}
+
+// readerCodec counts the responses decoded as they are read rather
+// than from a buffered body, and all the decoders made.
+type readerCodec struct {
+	stdCodec
+	streamed int
+	decoders int
+}
+
+func (c *readerCodec) NewDecoder(r io.Reader) Decoder {
+	c.decoders++
+	if _, ok := r.(*bytes.Reader); !ok {
+		c.streamed++
+	}
+	return json.NewDecoder(r)
+}
+
+func TestStreamDecoding(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status") == "" {
+			io.WriteString(w, `{"data":{"name":"Mat"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"errors":[{"message":"bad"}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	codec := &readerCodec{}
+	var logs bytes.Buffer
+	client := NewClient(srv.URL, WithCodec(codec))
+	client.SetLogger(NewLogger(&logs, "", 0)).EnableDebugLog()
+	var resp struct{ Name string }
+	is.NoErr(client.Run(ctx, NewRequest(`{ name }`), &resp))
+	is.Equal(resp.Name, "Mat")
+	is.Equal(codec.streamed, 1) // decoded as it is read
+	is.Equal(codec.decoders, 1) // with the data, not decoded again
+	is.True(strings.Contains(logs.String(), `response body: {"data":{"name":"Mat"}}`))
+
+	// options needing the raw data decode it on its own
+	codec.streamed, codec.decoders = 0, 0
+	var upper struct{ Name string }
+	err := client.With(WithFieldTransform("name", func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error) {
+		return strings.ToUpper(value.(string)), nil
+	})).Run(ctx, NewRequest(`{ name }`), &upper)
+	is.NoErr(err)
+	is.Equal(upper.Name, "MAT")
+	is.Equal(codec.decoders, 2)
+
+	// raw responses and failed requests are buffered
+	codec.streamed = 0
+	raw, err := client.RunRaw(ctx, NewRequest(`{ name }`))
+	is.NoErr(err)
+	is.Equal(string(raw), `{"data":{"name":"Mat"}}`)
+	is.Equal(codec.streamed, 0)
+	codec.streamed = 0
+	err = NewClient(srv.URL+"?status=400", WithCodec(codec)).Run(ctx, NewRequest(`{ name }`), nil)
+	is.Equal(err.Error(), "graphql: bad")
+	is.Equal(codec.streamed, 0)
+}
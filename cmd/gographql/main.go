@@ -0,0 +1,48 @@
+// Command gographql is a command line companion to the gographql package.
+//
+// Usage:
+//
+//	gographql proxy --listen :8088 --target https://api.example.com/graphql
+//	gographql query --endpoint https://api.example.com/graphql --vars-file vars.yaml @user.graphql
+//
+// The proxy command forwards GraphQL requests to the target through a
+// gographql.Client and serves the captured operations at /_captures.
+// The query command sends a query, read from a file when prefixed with
+// @, with variables from a JSON or YAML file, and prints the response.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: gographql <command> [flags]
+
+commands:
+  proxy    forward GraphQL requests to an endpoint and capture them
+  query    send a query to an endpoint and print the response
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "proxy":
+		return proxyCommand(args[1:], stdout, stderr)
+	case "query":
+		return queryCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gographql: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
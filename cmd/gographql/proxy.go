@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vikramarsid/gographql"
+)
+
+// forwardedHeaders are the request headers passed on to the target.
+var forwardedHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+
+func proxyCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("proxy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	listen := flags.String("listen", ":8088", "address to listen on")
+	target := flags.String("target", "", "GraphQL endpoint to forward requests to")
+	size := flags.Int("captures", 200, "number of captured operations to keep")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *target == "" {
+		fmt.Fprintln(stderr, "gographql proxy: --target is required")
+		return 2
+	}
+	p := newProxy(gographql.NewClient(*target), *size)
+	fmt.Fprintf(stdout, "forwarding %s to %s, captures at http://%s/_captures\n", *listen, *target, *listen)
+	if err := http.ListenAndServe(*listen, p); err != nil {
+		fmt.Fprintf(stderr, "gographql proxy: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// capture is a proxied operation.
+type capture struct {
+	Time          time.Time              `json:"time"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Response      json.RawMessage        `json:"response,omitempty"`
+	Duration      time.Duration          `json:"duration"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// proxy forwards GraphQL requests through a client and keeps the last
+// captures.
+type proxy struct {
+	handler http.Handler
+	size    int
+
+	mu       sync.Mutex
+	captures []capture
+}
+
+func newProxy(client *gographql.Client, size int) *proxy {
+	return &proxy{
+		handler: gographql.Handler(client, gographql.ForwardHeaders(forwardedHeaders...)),
+		size:    size,
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/_captures":
+		p.serveCaptures(w, r)
+	case "/_captures.json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.snapshot())
+	default:
+		p.forward(w, r)
+	}
+}
+
+// forward serves r with the handler, capturing the operation and its
+// response.
+func (p *proxy) forward(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if r.Method == http.MethodPost {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		json.Unmarshal(b, &body)
+	} else {
+		q := r.URL.Query()
+		body.Query = q.Get("query")
+		body.OperationName = q.Get("operationName")
+		json.Unmarshal([]byte(q.Get("variables")), &body.Variables)
+	}
+
+	rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	p.handler.ServeHTTP(rec, r)
+	c := capture{
+		Time:          start,
+		OperationName: body.OperationName,
+		Query:         body.Query,
+		Variables:     body.Variables,
+		Duration:      time.Since(start),
+	}
+	var resp struct {
+		Errors []struct{ Message string } `json:"errors"`
+	}
+	if json.Unmarshal(rec.body.Bytes(), &resp) == nil {
+		c.Response = json.RawMessage(rec.body.Bytes())
+		for i, e := range resp.Errors {
+			if i > 0 {
+				c.Error += "; "
+			}
+			c.Error += e.Message
+		}
+	}
+	if c.Error == "" && rec.status != http.StatusOK {
+		c.Error = http.StatusText(rec.status)
+	}
+	p.record(c)
+}
+
+// recorder keeps a copy of the response written through it.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (p *proxy) record(c capture) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.captures = append(p.captures, c)
+	if len(p.captures) > p.size {
+		p.captures = p.captures[len(p.captures)-p.size:]
+	}
+}
+
+// snapshot returns the captures, most recent first.
+func (p *proxy) snapshot() []capture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]capture, len(p.captures))
+	for i, c := range p.captures {
+		out[len(out)-1-i] = c
+	}
+	return out
+}
+
+var capturesPage = template.Must(template.New("captures").Funcs(template.FuncMap{
+	"json": func(v interface{}) string {
+		b, _ := json.MarshalIndent(v, "", "  ")
+		return string(b)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gographql proxy</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+details { border-bottom: 1px solid #ddd; padding: .5em 0; }
+summary { cursor: pointer; }
+pre { background: #f6f6f6; padding: .5em; overflow: auto; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Captured operations</h1>
+{{range .}}
+<details>
+<summary>{{.Time.Format "15:04:05.000"}} <b>{{or .OperationName "(anonymous)"}}</b> {{.Duration}}{{if .Error}} <span class="error">{{.Error}}</span>{{end}}</summary>
+<pre>{{.Query}}</pre>
+{{if .Variables}}<pre>{{json .Variables}}</pre>{{end}}
+{{if .Response}}<pre>{{json .Response}}</pre>{{end}}
+</details>
+{{else}}
+<p>No operations yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+func (p *proxy) serveCaptures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	capturesPage.Execute(w, p.snapshot())
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/vikramarsid/gographql"
+)
+
+func TestProxy(t *testing.T) {
+	is := is.New(t)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		if body.Variables["id"] == "missing" {
+			io.WriteString(w, `{"data":{"user":null},"errors":[{"message":"not found"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}}}`)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(newProxy(gographql.NewClient(target.URL), 1))
+	defer srv.Close()
+
+	post := func(id string) string {
+		r, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(
+			`{"query":"query User($id: ID!) { user(id: $id) { name } }","operationName":"User","variables":{"id":"`+id+`"}}`))
+		is.NoErr(err)
+		r.Header.Set("Authorization", "Bearer token")
+		res, err := http.DefaultClient.Do(r)
+		is.NoErr(err)
+		defer res.Body.Close()
+		is.Equal(res.StatusCode, http.StatusOK)
+		b, err := io.ReadAll(res.Body)
+		is.NoErr(err)
+		return string(b)
+	}
+	is.Equal(post("1"), `{"data":{"user":{"name":"Mat"}}}`)
+	is.Equal(post("missing"), `{"data":{"user":null},"errors":[{"message":"not found"}]}`)
+
+	res, err := http.Get(srv.URL + "/_captures.json")
+	is.NoErr(err)
+	defer res.Body.Close()
+	var captures []capture
+	is.NoErr(json.NewDecoder(res.Body).Decode(&captures))
+	is.Equal(len(captures), 1) // only the last one is kept
+	is.Equal(captures[0].OperationName, "User")
+	is.Equal(captures[0].Variables["id"], "missing")
+	is.Equal(captures[0].Error, "not found")
+
+	res, err = http.Get(srv.URL + "/_captures")
+	is.NoErr(err)
+	defer res.Body.Close()
+	page, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.True(bytes.Contains(page, []byte("<b>User</b>")))
+}
+
+func TestRunUsage(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	is.Equal(run(nil, &stdout, &stderr), 2)
+	is.Equal(run([]string{"proxy"}, &stdout, &stderr), 2)
+	is.True(strings.Contains(stderr.String(), "--target is required"))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vikramarsid/gographql"
+)
+
+// headerFlags are the repeated --header flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("header %q is not Name: value", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func queryCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	endpoint := flags.String("endpoint", "", "GraphQL endpoint to send the query to")
+	varsFile := flags.String("vars-file", "", "JSON or YAML file of variables, with ${NAME} environment references")
+	var headers headerFlags
+	flags.Var(&headers, "header", "request header, Name: value (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *endpoint == "" || flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "gographql query: --endpoint and a query, or @file, are required")
+		return 2
+	}
+	query := flags.Arg(0)
+	if name, ok := strings.CutPrefix(query, "@"); ok {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "gographql query: %v\n", err)
+			return 1
+		}
+		query = string(b)
+	}
+	req := gographql.NewRequest(query)
+	if *varsFile != "" {
+		if err := req.VarsFromFile(*varsFile); err != nil {
+			fmt.Fprintf(stderr, "gographql query: %v\n", err)
+			return 1
+		}
+	}
+	for _, header := range headers {
+		name, value, _ := strings.Cut(header, ":")
+		req.SetHeader(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	client := gographql.NewClient(*endpoint)
+	res, err := client.RunRaw(context.Background(), req)
+	if len(res) > 0 {
+		fmt.Fprintf(stdout, "%s\n", res)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gographql query: %v\n", err)
+		return 1
+	}
+	return 0
+}
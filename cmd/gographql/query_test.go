@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestQuery(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Query, `query ($id: ID!) { user(id: $id) { name } }`)
+		is.Equal(body.Variables, map[string]interface{}{"id": "1", "token": "secret"})
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}}}`)
+	}))
+	defer srv.Close()
+	t.Setenv("GOGRAPHQL_TOKEN", "secret")
+	dir := t.TempDir()
+	varsFile := filepath.Join(dir, "vars.yaml")
+	is.NoErr(os.WriteFile(varsFile, []byte("id: \"1\"\ntoken: ${GOGRAPHQL_TOKEN}\n"), 0o600))
+	queryFile := filepath.Join(dir, "user.graphql")
+	is.NoErr(os.WriteFile(queryFile, []byte(`query ($id: ID!) { user(id: $id) { name } }`), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"query", "--endpoint", srv.URL, "--vars-file", varsFile, "--header", "Authorization: Bearer token", "@" + queryFile}, &stdout, &stderr)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 0)
+	is.Equal(stdout.String(), `{"data":{"user":{"name":"Mat"}}}`+"\n")
+
+	stderr.Reset()
+	is.Equal(run([]string{"query", "--endpoint", srv.URL, "--vars-file", filepath.Join(dir, "missing.yaml"), "{ a }"}, &stdout, &stderr), 1)
+	is.True(strings.Contains(stderr.String(), "missing.yaml"))
+	is.Equal(run([]string{"query", "{ a }"}, &stdout, &stderr), 2)
+}
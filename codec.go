@@ -0,0 +1,66 @@
+package gographql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Codec encodes request bodies and decodes responses. The default codec
+// is encoding/json; faster implementations such as jsoniter or sonic can
+// be plugged in with WithCodec. Codecs must support json.RawMessage and
+// the json struct tags.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder decodes a JSON value. Decoders also implementing
+// DisallowUnknownFields() and UseNumber(), as *json.Decoder does, support
+// WithStrictDecoding and WithUseNumber.
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// WithCodec makes the client encode JSON request bodies and decode
+// responses with codec instead of encoding/json. Less common paths, such
+// as file uploads, GET parameters and batches, still use encoding/json.
+//
+//	type sonicCodec struct{}
+//
+//	func (sonicCodec) Marshal(v interface{}) ([]byte, error) { return sonic.Marshal(v) }
+//	func (sonicCodec) NewDecoder(r io.Reader) gographql.Decoder { return decoder.NewStreamDecoder(r) }
+//
+//	client := gographql.NewClient(endpoint, gographql.WithCodec(sonicCodec{}))
+func WithCodec(codec Codec) ClientOption {
+	return func(client *Client) {
+		client.codec = codec
+	}
+}
+
+// stdCodec is the Codec of encoding/json.
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
+
+// encodeBody encodes v with the client codec, followed by a newline as
+// json.Encoder does.
+func (c *Client) encodeBody(buf *bytes.Buffer, v interface{}) error {
+	if _, ok := c.codec.(stdCodec); ok {
+		// encode straight into buf, without an intermediate slice
+		return json.NewEncoder(buf).Encode(v)
+	}
+	b, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	buf.WriteByte('\n')
+	return nil
+}
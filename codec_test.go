@@ -0,0 +1,49 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// countingCodec counts the values encoded and decoded with encoding/json.
+type countingCodec struct {
+	marshaled, decoders atomic.Int32
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshaled.Add(1)
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) NewDecoder(r io.Reader) Decoder {
+	c.decoders.Add(1)
+	return json.NewDecoder(r)
+}
+
+func TestWithCodec(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		is.Equal(string(b), `{"query":"{ user { id } }","variables":null}`+"\n")
+		io.WriteString(w, `{"data":{"user":{"id":12345678901234567890,"extra":1}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	codec := &countingCodec{}
+	var resp map[string]map[string]interface{}
+	client := NewClient(srv.URL, WithCodec(codec), WithUseNumber())
+	is.NoErr(client.Run(ctx, NewRequest(`{ user { id } }`), &resp))
+	is.Equal(resp["user"]["id"], json.Number("12345678901234567890"))
+	is.Equal(codec.marshaled.Load(), int32(1))
+	is.Equal(codec.decoders.Load(), int32(2)) // envelope and data
+}
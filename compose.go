@@ -0,0 +1,387 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrHeaderConflict requests merged into one set a header to different
+// values.
+var ErrHeaderConflict = errors.New("requests set conflicting headers")
+
+// Composer merges independent requests into a single one, so a screen
+// built from several components makes one round trip. Make one with
+// Client.Compose.
+//
+// Each request's root fields, variables and fragments are prefixed with
+// r<index>_ in the merged document, and the response is split back per
+// request. Only variable references are renamed: string values
+// containing "$name" are sent as they are.
+type Composer struct {
+	client *Client
+	parts  []composed
+}
+
+type composed struct {
+	req  *Request
+	resp interface{}
+}
+
+// Compose returns an empty Composer running with c.
+//
+//	cp := client.Compose()
+//	cp.Add(headerReq, &header)
+//	cp.Add(feedReq, &feed)
+//	if err := cp.Run(ctx); err != nil {
+//	    return err
+//	}
+func (c *Client) Compose() *Composer {
+	return &Composer{client: c}
+}
+
+// Add adds req, whose data is decoded into resp by Run, and returns its
+// index in ComposeError.
+func (cp *Composer) Add(req *Request, resp interface{}) int {
+	cp.parts = append(cp.parts, composed{req: req, resp: resp})
+	return len(cp.parts) - 1
+}
+
+// ComposeError reports the GraphQL errors of composed requests, with
+// their paths relative to the original requests.
+type ComposeError struct {
+	// Errors holds the errors of each request in the order they were
+	// added, nil for requests without errors.
+	Errors []GraphQLErrors
+}
+
+func (e *ComposeError) Error() string {
+	var msgs []string
+	for i, errs := range e.Errors {
+		if len(errs) > 0 {
+			msgs = append(msgs, fmt.Sprintf("request %d: %v", i, errs))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ComposeError) Unwrap() []error {
+	var errs []error
+	for _, gqlErrs := range e.Errors {
+		if len(gqlErrs) > 0 {
+			errs = append(errs, gqlErrs)
+		}
+	}
+	return errs
+}
+
+// mergeHeader adds header, that of request i, to merged, failing if an
+// earlier request, recorded in from, set one of its keys to other values.
+func mergeHeader(merged http.Header, from map[string]int, header http.Header, i int) error {
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		if prev, ok := merged[key]; ok {
+			if !slices.Equal(prev, values) {
+				return fmt.Errorf("%w: %s of request %d differs from request %d", ErrHeaderConflict, key, i, from[key])
+			}
+			continue
+		}
+		merged[key] = append([]string(nil), values...)
+		from[key] = i
+	}
+	return nil
+}
+
+func composePrefix(i int) string {
+	return "r" + strconv.Itoa(i) + "_"
+}
+
+// Request returns the merged request. All requests must be operations of
+// the same type, without operation directives or files. The merged
+// request carries the headers of every request, which must not set a
+// header to different values, failing with ErrHeaderConflict, so no
+// request runs with the credentials of another.
+func (cp *Composer) Request() (*Request, error) {
+	if len(cp.parts) == 0 {
+		return nil, errors.New("compose: no requests")
+	}
+	merged := &Definition{}
+	var varDefs []string
+	var fragments []*Definition
+	header := make(http.Header)
+	headerFrom := make(map[string]int)
+	vars := make(map[string]interface{})
+	for i, part := range cp.parts {
+		prefix := composePrefix(i)
+		doc, err := ParseDocument(part.req.Query())
+		if err != nil {
+			return nil, fmt.Errorf("compose: request %d: %w", i, err)
+		}
+		if len(part.req.Files()) > 0 {
+			return nil, fmt.Errorf("compose: request %d has files", i)
+		}
+		op := doc.Operation("")
+		if op == nil {
+			return nil, fmt.Errorf("compose: request %d has no operation", i)
+		}
+		if merged.Kind == "" {
+			merged.Kind = op.Kind
+		}
+		if op.Kind != merged.Kind || op.Kind == "subscription" {
+			return nil, fmt.Errorf("compose: request %d is a %s, cannot merge with a %s", i, op.Kind, merged.Kind)
+		}
+		if op.Header != "" {
+			if !strings.HasPrefix(op.Header, "(") || !strings.HasSuffix(op.Header, ")") {
+				return nil, fmt.Errorf("compose: request %d: operation directives are not supported", i)
+			}
+			varDefs = append(varDefs, renameVariables(op.Header[1:len(op.Header)-1], prefix))
+		}
+		c := &composer{doc: doc, prefix: prefix}
+		merged.Selections = append(merged.Selections, c.root(op.Selections)...)
+		for _, def := range doc.Definitions {
+			if def.Kind == "fragment" {
+				fragments = append(fragments, &Definition{
+					Kind:       "fragment",
+					Name:       prefix + def.Name,
+					Header:     renameVariables(def.Header, prefix),
+					Selections: c.rename(def.Selections),
+				})
+			}
+		}
+		for key, value := range part.req.Vars() {
+			vars[prefix+key] = value
+		}
+		part.req.mu.RLock()
+		err = mergeHeader(header, headerFrom, part.req.Header, i)
+		part.req.mu.RUnlock()
+		if err != nil {
+			return nil, fmt.Errorf("compose: %w", err)
+		}
+	}
+	if len(varDefs) > 0 {
+		merged.Name = "Composed"
+		merged.Header = "(" + strings.Join(varDefs, ", ") + ")"
+	}
+	doc := &Document{Definitions: append([]*Definition{merged}, usedFragments(merged, fragments)...)}
+	req := NewRequest(doc.String())
+	req.vars = vars
+	req.Header = header
+	return req, nil
+}
+
+// Run sends the merged request and decodes each request's data into its
+// response. GraphQL errors are returned as a *ComposeError.
+func (cp *Composer) Run(ctx context.Context) error {
+	data, composeErr, err := cp.send(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cp.decode(data); err != nil {
+		return err
+	}
+	if composeErr != nil {
+		return composeErr
+	}
+	return nil
+}
+
+// send sends the merged request and splits the response data and errors
+// per request. The *ComposeError is nil when there are no errors.
+func (cp *Composer) send(ctx context.Context) ([]map[string]json.RawMessage, *ComposeError, error) {
+	req, err := cp.Request()
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := cp.client.RunRaw(ctx, req)
+	var gqlErrs GraphQLErrors
+	if err != nil && !errors.As(err, &gqlErrs) {
+		return nil, nil, err
+	}
+	var body struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors GraphQLErrors              `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, nil, errors.Join(ErrDecodingResponse, err)
+	}
+	data := make([]map[string]json.RawMessage, len(cp.parts))
+	for key, value := range body.Data {
+		if i, name, ok := splitComposedKey(key); ok && i < len(cp.parts) {
+			if data[i] == nil {
+				data[i] = make(map[string]json.RawMessage)
+			}
+			data[i][name] = value
+		}
+	}
+	if len(body.Errors) == 0 {
+		return data, nil, nil
+	}
+	composeErr := &ComposeError{Errors: make([]GraphQLErrors, len(cp.parts))}
+	for _, e := range body.Errors {
+		if len(e.Path) > 0 {
+			if key, ok := e.Path[0].(string); ok {
+				if i, name, ok := splitComposedKey(key); ok && i < len(cp.parts) {
+					e.Path = append([]interface{}{name}, e.Path[1:]...)
+					composeErr.Errors[i] = append(composeErr.Errors[i], e)
+					continue
+				}
+			}
+		}
+		// errors without a path concern the whole merged request
+		for i := range composeErr.Errors {
+			composeErr.Errors[i] = append(composeErr.Errors[i], e)
+		}
+	}
+	return data, composeErr, nil
+}
+
+// decode decodes the data of each request into its response.
+func (cp *Composer) decode(data []map[string]json.RawMessage) error {
+	for i, part := range cp.parts {
+		if part.resp == nil || data[i] == nil {
+			continue
+		}
+		b, err := json.Marshal(data[i])
+		if err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+		if err := cp.client.decodeData(b, part.resp); err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+	}
+	return nil
+}
+
+// splitComposedKey splits a root response key into the request index and
+// the original key.
+func splitComposedKey(key string) (int, string, bool) {
+	if !strings.HasPrefix(key, "r") {
+		return 0, "", false
+	}
+	index, name, ok := strings.Cut(key[1:], "_")
+	if !ok {
+		return 0, "", false
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, "", false
+	}
+	return i, name, true
+}
+
+// usedFragments returns the fragments spread in op, directly or through
+// other fragments, as servers reject unused fragments.
+func usedFragments(op *Definition, fragments []*Definition) []*Definition {
+	byName := make(map[string]*Definition, len(fragments))
+	for _, def := range fragments {
+		byName[def.Name] = def
+	}
+	used := make(map[string]bool)
+	var walk func([]*Selection)
+	walk = func(sels []*Selection) {
+		for _, sel := range sels {
+			if sel.Kind == FragmentSpread && !used[sel.Name] {
+				used[sel.Name] = true
+				if def, ok := byName[sel.Name]; ok {
+					walk(def.Selections)
+				}
+			}
+			walk(sel.Selections)
+		}
+	}
+	walk(op.Selections)
+	var out []*Definition
+	for _, def := range fragments {
+		if used[def.Name] {
+			out = append(out, def)
+		}
+	}
+	return out
+}
+
+// renameVariables prefixes the variables referenced in src, a part of a
+// parsed document, leaving strings and comments untouched.
+func renameVariables(src, prefix string) string {
+	var b strings.Builder
+	l := lexer{src: src}
+	last := 0
+	dollar := false
+	for {
+		tok, err := l.next()
+		if err != nil || tok.kind == tokEOF {
+			break
+		}
+		if dollar && tok.kind == tokName {
+			b.WriteString(src[last:tok.start])
+			b.WriteString(prefix)
+			last = tok.start
+		}
+		dollar = tok.kind == tokPunct && tok.value == "$"
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// composer rewrites the selections of one request.
+type composer struct {
+	doc    *Document
+	prefix string
+}
+
+// root aliases the root fields, inlining root fragments so that their
+// fields are aliased too.
+func (c *composer) root(sels []*Selection) []*Selection {
+	var out []*Selection
+	for _, sel := range sels {
+		switch sel.Kind {
+		case FieldSelection:
+			field := c.renameOne(sel)
+			field.Alias = c.prefix + sel.ResponseKey()
+			out = append(out, field)
+		case InlineFragment:
+			frag := c.renameOne(sel)
+			frag.Selections = c.root(sel.Selections)
+			out = append(out, frag)
+		case FragmentSpread:
+			def := c.doc.Fragment(sel.Name)
+			if def == nil {
+				out = append(out, c.renameOne(sel))
+				continue
+			}
+			out = append(out, &Selection{
+				Kind:          InlineFragment,
+				TypeCondition: strings.Fields(def.Header)[1],
+				Directives:    renameVariables(sel.Directives, c.prefix),
+				Selections:    c.root(def.Selections),
+			})
+		}
+	}
+	return out
+}
+
+func (c *composer) rename(sels []*Selection) []*Selection {
+	out := make([]*Selection, len(sels))
+	for i, sel := range sels {
+		out[i] = c.renameOne(sel)
+	}
+	return out
+}
+
+// renameOne copies sel with its variables and fragment spreads renamed.
+func (c *composer) renameOne(sel *Selection) *Selection {
+	out := *sel
+	out.Arguments = renameVariables(sel.Arguments, c.prefix)
+	out.Directives = renameVariables(sel.Directives, c.prefix)
+	if sel.Kind == FragmentSpread {
+		out.Name = c.prefix + sel.Name
+	}
+	if sel.Selections != nil {
+		out.Selections = c.rename(sel.Selections)
+	}
+	return &out
+}
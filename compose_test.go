@@ -0,0 +1,127 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestCompose(t *testing.T) {
+	is := is.New(t)
+	var got struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(json.NewDecoder(r.Body).Decode(&got))
+		is.Equal(r.Header.Get("X-Screen"), "home")
+		io.WriteString(w, `{
+			"data":{"r0_me":{"name":"Mat","r":"admin"},"r1_feed":null,"r1_count":3},
+			"errors":[{"message":"feed unavailable","path":["r1_feed"]}]
+		}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	header := NewRequest(`query Header($id: ID!) { ...Me }
+		fragment Me on Query { me: user(id: $id) { ...Name r: role } }
+		fragment Name on User { name }
+		fragment Unused on User { id }`)
+	header.Var("id", "1")
+	header.SetHeader("X-Screen", "home")
+	feed := NewRequest(`query ($first: Int) { feed(first: $first) { id } count }`)
+	feed.Var("first", 10)
+
+	cp := client.Compose()
+	var headerResp struct {
+		Me struct{ Name, R string }
+	}
+	var feedResp struct {
+		Feed  []struct{ ID string }
+		Count int
+	}
+	is.Equal(cp.Add(header, &headerResp), 0)
+	is.Equal(cp.Add(feed, &feedResp), 1)
+	err := cp.Run(ctx)
+
+	is.Equal(got.Query, `query Composed($r0_id: ID!, $r1_first: Int) {
+  ... on Query {
+    r0_me: user(id: $r0_id) {
+      ...r0_Name
+      r: role
+    }
+  }
+  r1_feed: feed(first: $r1_first) {
+    id
+  }
+  r1_count: count
+}
+
+fragment r0_Name on User {
+  name
+}
+`)
+	is.Equal(got.Variables, map[string]interface{}{"r0_id": "1", "r1_first": float64(10)})
+	is.Equal(headerResp.Me.Name, "Mat")
+	is.Equal(headerResp.Me.R, "admin")
+	is.Equal(feedResp.Count, 3)
+
+	var composeErr *ComposeError
+	is.True(errors.As(err, &composeErr))
+	is.Equal(composeErr.Errors[0], nil)
+	is.Equal(composeErr.Errors[1][0].Path, []interface{}{"feed"})
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+}
+
+func TestComposeMixedOperations(t *testing.T) {
+	is := is.New(t)
+	cp := NewClient("http://localhost").Compose()
+	cp.Add(NewRequest(`{ a }`), nil)
+	cp.Add(NewRequest(`mutation { b }`), nil)
+	_, err := cp.Request()
+	is.True(err != nil)
+}
+
+func TestComposeHeaderConflict(t *testing.T) {
+	is := is.New(t)
+	cp := NewClient("http://localhost").Compose()
+	alice, bob, other := NewRequest(`{ a }`), NewRequest(`{ b }`), NewRequest(`{ c }`)
+	alice.SetHeader("Authorization", "Bearer alice")
+	other.SetHeader("Authorization", "Bearer alice")
+	bob.SetHeader("authorization", "Bearer bob")
+	cp.Add(alice, nil)
+	cp.Add(other, nil)
+	req, err := cp.Request()
+	is.NoErr(err)
+	is.Equal(req.Header.Get("Authorization"), "Bearer alice")
+	cp.Add(bob, nil)
+	_, err = cp.Request()
+	is.True(errors.Is(err, ErrHeaderConflict))
+	is.Equal(err.Error(), "compose: requests set conflicting headers: Authorization of request 2 differs from request 0")
+}
+
+func TestComposeStringsKeepDollars(t *testing.T) {
+	is := is.New(t)
+	cp := NewClient("http://localhost").Compose()
+	req := NewRequest(`query ($amount: Int!) { price(label: "costs $amount", note: """$amount""", amount: $amount) @include(if: true) }`)
+	req.Var("amount", 3)
+	cp.Add(req, nil)
+	merged, err := cp.Request()
+	is.NoErr(err)
+	is.True(strings.Contains(merged.Query(), `$r0_amount: Int!`))
+	is.True(strings.Contains(merged.Query(), `label: "costs $amount"`))
+	is.True(strings.Contains(merged.Query(), `note: """$amount"""`))
+	is.True(strings.Contains(merged.Query(), `amount: $r0_amount`))
+	is.Equal(merged.Vars()["r0_amount"], 3)
+}
@@ -0,0 +1,79 @@
+package gographql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultCompressionThreshold is a request body size, in bytes, below
+// which compression rarely pays off.
+const DefaultCompressionThreshold = 1400
+
+// requestCompression is the state of request body compression, shared by
+// clients made with Client.With.
+type requestCompression struct {
+	threshold int
+	// unsupported is set once the server has shown it does not accept
+	// compressed bodies.
+	unsupported atomic.Bool
+}
+
+// WithRequestCompression gzips request bodies of at least threshold
+// bytes, trading CPU for bandwidth on large mutations and uploads.
+// Compression is skipped when the server does not support it: when
+// Probe found it unsupported, when a response advertises other encodings
+// in its Accept-Encoding header, or after the server rejected a
+// compressed body with 415 Unsupported Media Type, in which case the
+// request is sent again uncompressed.
+func WithRequestCompression(threshold int) ClientOption {
+	return func(client *Client) {
+		if threshold < 0 {
+			client.invalidOption("WithRequestCompression: negative threshold %d", threshold)
+		}
+		client.compression = &requestCompression{threshold: threshold}
+	}
+}
+
+func (c *Client) shouldCompress(size int) bool {
+	if c.compression == nil || size < c.compression.threshold || c.compression.unsupported.Load() {
+		return false
+	}
+	return !c.lacks(func(caps *Capabilities) bool { return caps.RequestCompression })
+}
+
+// negotiate records whether the server accepts compressed bodies from the
+// response described by meta, and reports whether the body was compressed
+// and rejected.
+func (rc *requestCompression) negotiate(meta *responseMeta, compressed bool) bool {
+	if compressed && meta.statusCode == http.StatusUnsupportedMediaType {
+		rc.unsupported.Store(true)
+		return true
+	}
+	if accept := meta.header.Values("Accept-Encoding"); len(accept) > 0 && !strings.Contains(strings.Join(accept, ","), "gzip") {
+		rc.unsupported.Store(true)
+	}
+	return false
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gzipTo(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gzipTo writes b compressed to w, with a pooled writer.
+func gzipTo(w io.Writer, b []byte) error {
+	zw := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(zw)
+	zw.Reset(w)
+	if _, err := zw.Write(b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,61 @@
+package gographql
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRequestCompression(t *testing.T) {
+	is := is.New(t)
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			is.NoErr(err)
+			body = zr
+		}
+		b, err := io.ReadAll(body)
+		is.NoErr(err)
+		is.True(strings.Contains(string(b), "query"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithRequestCompression(100))
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("{ "+strings.Repeat("a ", 100)+"}"), nil))
+	is.Equal(encodings, []string{"", "gzip"})
+}
+
+func TestRequestCompressionRejected(t *testing.T) {
+	is := is.New(t)
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		if r.Header.Get("Content-Encoding") != "" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithRequestCompression(0))
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.Equal(encodings, []string{"gzip", "", ""}) // resent uncompressed, then never compressed
+}
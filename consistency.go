@@ -0,0 +1,118 @@
+package gographql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// DefaultConsistencyHeader is the header used to send consistency tokens
+// when ConsistencyTokens.RequestHeader is not set.
+const DefaultConsistencyHeader = "X-Consistency-Token"
+
+// ConsistencyTokens configures read-your-writes consistency for
+// eventually consistent backends: tokens returned by the server are
+// captured into the ConsistencySession of the request context, and sent
+// with every later request made with that context.
+type ConsistencyTokens struct {
+	// ResponseHeader is the response header carrying the token.
+	ResponseHeader string
+	// Extension is the response extensions key carrying the token. It is
+	// used when the header is not set or not present.
+	Extension string
+	// RequestHeader is the header on which the token is sent. It defaults
+	// to ResponseHeader, or DefaultConsistencyHeader.
+	RequestHeader string
+}
+
+// WithConsistencyTokens enables read-your-writes consistency tokens.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithConsistencyTokens(gographql.ConsistencyTokens{
+//	    Extension: "consistencyToken",
+//	}))
+//	ctx = gographql.ContextWithConsistencySession(ctx)
+//	client.Run(ctx, mutation, nil)  // token captured
+//	client.Run(ctx, query, &resp)   // token sent
+func WithConsistencyTokens(cfg ConsistencyTokens) ClientOption {
+	return func(client *Client) {
+		client.consistency = &cfg
+	}
+}
+
+// ConsistencySession holds the latest consistency token seen within a
+// logical session. It is safe for concurrent use.
+type ConsistencySession struct {
+	mu    sync.Mutex
+	token string
+}
+
+// Token returns the latest captured token.
+func (s *ConsistencySession) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// SetToken replaces the session token, e.g. to resume a session.
+func (s *ConsistencySession) SetToken(token string) {
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+}
+
+type consistencySessionKey struct{}
+
+// ContextWithConsistencySession returns a copy of ctx carrying a new,
+// empty ConsistencySession.
+func ContextWithConsistencySession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistencySessionKey{}, &ConsistencySession{})
+}
+
+// ConsistencySessionFromContext returns the session carried by ctx.
+func ConsistencySessionFromContext(ctx context.Context) (*ConsistencySession, bool) {
+	s, ok := ctx.Value(consistencySessionKey{}).(*ConsistencySession)
+	return s, ok
+}
+
+func (cfg *ConsistencyTokens) requestHeader() string {
+	switch {
+	case cfg.RequestHeader != "":
+		return cfg.RequestHeader
+	case cfg.ResponseHeader != "":
+		return cfg.ResponseHeader
+	}
+	return DefaultConsistencyHeader
+}
+
+func (cfg *ConsistencyTokens) attach(ctx context.Context, h http.Header) {
+	session, ok := ConsistencySessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	if token := session.Token(); token != "" {
+		h.Set(cfg.requestHeader(), token)
+	}
+}
+
+func (cfg *ConsistencyTokens) capture(ctx context.Context, meta *responseMeta) {
+	session, ok := ConsistencySessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	if cfg.ResponseHeader != "" {
+		if token := meta.header.Get(cfg.ResponseHeader); token != "" {
+			session.SetToken(token)
+			return
+		}
+	}
+	if cfg.Extension != "" {
+		switch token := meta.extensions[cfg.Extension].(type) {
+		case nil:
+		case string:
+			session.SetToken(token)
+		default:
+			session.SetToken(fmt.Sprint(token))
+		}
+	}
+}
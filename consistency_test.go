@@ -0,0 +1,55 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestConsistencyTokens(t *testing.T) {
+	is := is.New(t)
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Consistency-Token"))
+		io.WriteString(w, `{"data":{},"extensions":{"consistencyToken":"v42"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithConsistencyTokens(ConsistencyTokens{Extension: "consistencyToken"}))
+	session := ContextWithConsistencySession(ctx)
+	is.NoErr(client.Run(session, NewRequest("mutation { a }"), nil))
+	is.NoErr(client.Run(session, NewRequest("query { a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), nil)) // no session, no token
+	is.Equal(seen, []string{"", "v42", ""})
+	s, _ := ConsistencySessionFromContext(session)
+	is.Equal(s.Token(), "v42")
+}
+
+func TestConsistencyTokensHeader(t *testing.T) {
+	is := is.New(t)
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Read-After"))
+		w.Header().Set("X-Write-Version", "7")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithConsistencyTokens(ConsistencyTokens{
+		ResponseHeader: "X-Write-Version",
+		RequestHeader:  "X-Read-After",
+	}))
+	ctx = ContextWithConsistencySession(ctx)
+	is.NoErr(client.Run(ctx, NewRequest("mutation { a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), nil))
+	is.Equal(seen, []string{"", "7"})
+}
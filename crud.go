@@ -0,0 +1,374 @@
+package gographql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrNoKeyFields the struct passed to an update or delete builder has no
+// field tagged as a key.
+var ErrNoKeyFields = errors.New("no key fields")
+
+// CRUDStyle selects the naming conventions used by CRUDBuilder.
+type CRUDStyle int
+
+const (
+	// Hasura generates insert_<entity>_one, update_<entity>_by_pk and
+	// delete_<entity>_by_pk mutations.
+	Hasura CRUDStyle = iota
+	// PostGraphile generates create<Entity>, update<Entity>By<Key> and
+	// delete<Entity>By<Key> mutations.
+	PostGraphile
+)
+
+// CRUDBuilder generates create, update and delete mutations for
+// conventional CRUD GraphQL APIs from annotated Go structs.
+//
+// Struct fields are mapped with the graphql tag, falling back to the json
+// tag and then to the lower camel case field name:
+//
+//	type User struct {
+//	    ID        string    `graphql:"id,key"`
+//	    Name      string    `graphql:"name"`
+//	    Email     string    `graphql:"email,omitempty"`
+//	    CreatedAt time.Time `graphql:"created_at,readonly"`
+//	    Secret    string    `graphql:"-"`
+//	}
+//
+// Key fields identify the row for updates and deletes, readonly fields are
+// selected in the result but never sent, and omitempty fields are only
+// sent when they are not the zero value. Nested structs are mapped the
+// same way. Every mapped field, including nested structs, is selected in
+// the mutation result, except fields referring back to a struct being
+// selected, such as Parent *Category in Category, and fields nested more
+// than maxCRUDDepth structs deep.
+type CRUDBuilder struct {
+	// Entity is the table or type name, e.g. "users" for Hasura or
+	// "User" for PostGraphile.
+	Entity string
+	Style  CRUDStyle
+}
+
+// Create returns a request inserting input.
+func (b CRUDBuilder) Create(input interface{}) (*Request, error) {
+	m, err := parseCRUDStruct(input)
+	if err != nil {
+		return nil, err
+	}
+	var req *Request
+	switch b.Style {
+	case PostGraphile:
+		payload := lowerFirst(b.Entity)
+		req = NewRequest(fmt.Sprintf("mutation ($input: Create%sInput!) { create%s(input: $input) { %s { %s } } }",
+			b.Entity, b.Entity, payload, m.selection))
+		req.Var("input", map[string]interface{}{payload: m.values(false)})
+	default:
+		req = NewRequest(fmt.Sprintf("mutation ($object: %s_insert_input!) { insert_%s_one(object: $object) { %s } }",
+			b.Entity, b.Entity, m.selection))
+		req.Var("object", m.values(false))
+	}
+	return req, nil
+}
+
+// Update returns a request setting the fields of input on the row
+// identified by its key fields.
+func (b CRUDBuilder) Update(input interface{}) (*Request, error) {
+	m, err := parseCRUDStruct(input)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := m.keyValues()
+	if err != nil {
+		return nil, err
+	}
+	var req *Request
+	switch b.Style {
+	case PostGraphile:
+		payload := lowerFirst(b.Entity)
+		by := m.keyNames()
+		req = NewRequest(fmt.Sprintf("mutation ($input: Update%sBy%sInput!) { update%sBy%s(input: $input) { %s { %s } } }",
+			b.Entity, by, b.Entity, by, payload, m.selection))
+		input := m.keyMap()
+		input[payload+"Patch"] = m.values(true)
+		req.Var("input", input)
+	default:
+		req = NewRequest(fmt.Sprintf("mutation ($set: %s_set_input!) { update_%s_by_pk(pk_columns: {%s}, _set: $set) { %s } }",
+			b.Entity, b.Entity, keys, m.selection))
+		req.Var("set", m.values(true))
+	}
+	return req, nil
+}
+
+// Delete returns a request deleting the row identified by the key fields
+// of input.
+func (b CRUDBuilder) Delete(input interface{}) (*Request, error) {
+	m, err := parseCRUDStruct(input)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := m.keyValues()
+	if err != nil {
+		return nil, err
+	}
+	switch b.Style {
+	case PostGraphile:
+		payload := lowerFirst(b.Entity)
+		by := m.keyNames()
+		req := NewRequest(fmt.Sprintf("mutation ($input: Delete%sBy%sInput!) { delete%sBy%s(input: $input) { %s { %s } } }",
+			b.Entity, by, b.Entity, by, payload, m.selection))
+		req.Var("input", m.keyMap())
+		return req, nil
+	default:
+		return NewRequest(fmt.Sprintf("mutation { delete_%s_by_pk(%s) { %s } }", b.Entity, keys, m.selection)), nil
+	}
+}
+
+type crudField struct {
+	name      string
+	key       bool
+	readonly  bool
+	omitempty bool
+	value     reflect.Value
+}
+
+type crudStruct struct {
+	fields    []crudField
+	selection string
+}
+
+func parseCRUDStruct(v interface{}) (*crudStruct, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("nil input")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input must be a struct, got %s", rv.Type())
+	}
+	m := &crudStruct{}
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		field, ok := crudFieldFromTag(sf)
+		if !ok {
+			continue
+		}
+		if _, err := gqlName(field.name); err != nil {
+			return nil, err
+		}
+		field.value = rv.Field(i)
+		m.fields = append(m.fields, field)
+	}
+	if len(m.fields) == 0 {
+		return nil, fmt.Errorf("%s has no mapped fields", rv.Type())
+	}
+	m.selection = selectionFor(rv.Type(), nil)
+	return m, nil
+}
+
+func crudFieldFromTag(sf reflect.StructField) (crudField, bool) {
+	tag, ok := sf.Tag.Lookup("graphql")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return crudField{}, false
+	}
+	if name == "" {
+		name = lowerFirst(sf.Name)
+	}
+	field := crudField{name: name}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "key":
+			field.key = true
+		case "readonly":
+			field.readonly = true
+		case "omitempty":
+			field.omitempty = true
+		}
+	}
+	return field, true
+}
+
+// maxCRUDDepth is the number of nested structs selected by CRUDBuilder.
+const maxCRUDDepth = 8
+
+// selectionFor returns the selection set body for the mapped fields of t.
+// path are the structs being selected, which t is nested in.
+func selectionFor(t reflect.Type, path []reflect.Type) string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	path = append(path, t)
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		field, ok := crudFieldFromTag(sf)
+		if !ok {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !isScalarStruct(ft) {
+			if len(path) == maxCRUDDepth || slices.Contains(path, ft) {
+				// a cycle, as in Parent *Category
+				continue
+			}
+			if sel := selectionFor(ft, path); sel != "" {
+				names = append(names, field.name+" { "+sel+" }")
+			}
+			continue
+		}
+		names = append(names, field.name)
+	}
+	return strings.Join(names, " ")
+}
+
+// isScalarStruct reports whether values of t are encoded as JSON scalars,
+// like time.Time.
+func isScalarStruct(t reflect.Type) bool {
+	type textMarshaler interface{ MarshalText() ([]byte, error) }
+	type jsonMarshaler interface{ MarshalJSON() ([]byte, error) }
+	pt := reflect.PointerTo(t)
+	return pt.Implements(reflect.TypeOf((*textMarshaler)(nil)).Elem()) ||
+		pt.Implements(reflect.TypeOf((*jsonMarshaler)(nil)).Elem())
+}
+
+// values returns the input object. Key fields are left out of patches.
+func (m *crudStruct) values(patch bool) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, f := range m.fields {
+		if f.readonly || (patch && f.key) || (f.omitempty && f.value.IsZero()) {
+			continue
+		}
+		out[f.name] = inputValue(f.value, 0)
+	}
+	return out
+}
+
+// inputValue returns v with its nested structs mapped like the input
+// struct, up to maxCRUDDepth structs deep.
+func inputValue(v reflect.Value, depth int) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer && isScalarStruct(v.Type().Elem()) {
+			return v.Interface()
+		}
+		return inputValue(v.Elem(), depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = inputValue(v.Index(i), depth)
+		}
+		return out
+	case reflect.Struct:
+		if isScalarStruct(v.Type()) || depth == maxCRUDDepth {
+			return v.Interface()
+		}
+		out := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			field, ok := crudFieldFromTag(sf)
+			if !ok || field.readonly || (field.omitempty && v.Field(i).IsZero()) {
+				continue
+			}
+			out[field.name] = inputValue(v.Field(i), depth+1)
+		}
+		return out
+	}
+	return v.Interface()
+}
+
+func (m *crudStruct) keyMap() map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, f := range m.fields {
+		if f.key {
+			out[f.name] = f.value.Interface()
+		}
+	}
+	return out
+}
+
+// keyValues renders the key fields as GraphQL arguments, e.g. `id: 42`.
+func (m *crudStruct) keyValues() (string, error) {
+	var args []string
+	for _, f := range m.fields {
+		if !f.key {
+			continue
+		}
+		value, err := gqlValue(f.value.Interface())
+		if err != nil {
+			return "", err
+		}
+		args = append(args, f.name+": "+value)
+	}
+	if len(args) == 0 {
+		return "", ErrNoKeyFields
+	}
+	return strings.Join(args, ", "), nil
+}
+
+// keyNames returns the key field names joined for PostGraphile mutation
+// names, e.g. "IdAndOrgId".
+func (m *crudStruct) keyNames() string {
+	var names []string
+	for _, f := range m.fields {
+		if f.key {
+			names = append(names, upperFirst(f.name))
+		}
+	}
+	return strings.Join(names, "And")
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	// keep initialisms such as ID or URL readable: ID -> id, URLPath -> urlPath
+	i := 0
+	for i < len(r) && unicode.IsUpper(r[i]) {
+		i++
+	}
+	switch {
+	case i == 0:
+		return s
+	case i == 1 || i == len(r):
+		return strings.ToLower(string(r[:i])) + string(r[i:])
+	default:
+		return strings.ToLower(string(r[:i-1])) + string(r[i-1:])
+	}
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,114 @@
+package gographql
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type crudUser struct {
+	ID        int       `graphql:"id,key"`
+	Name      string    `graphql:"name"`
+	Email     string    `graphql:"email,omitempty"`
+	CreatedAt time.Time `graphql:"created_at,readonly"`
+	Profile   struct {
+		Bio string `json:"bio"`
+	} `graphql:"profile,readonly"`
+	Secret string `graphql:"-"`
+}
+
+func TestCRUDBuilderHasura(t *testing.T) {
+	is := is.New(t)
+	b := CRUDBuilder{Entity: "users"}
+	user := crudUser{ID: 1, Name: "Mat", Secret: "x"}
+
+	req, err := b.Create(user)
+	is.NoErr(err)
+	is.Equal(req.Query(), "mutation ($object: users_insert_input!) { insert_users_one(object: $object) { id name email created_at profile { bio } } }")
+	is.Equal(req.Vars(), map[string]interface{}{"object": map[string]interface{}{"id": 1, "name": "Mat"}})
+
+	req, err = b.Update(&user)
+	is.NoErr(err)
+	is.Equal(req.Query(), "mutation ($set: users_set_input!) { update_users_by_pk(pk_columns: {id: 1}, _set: $set) { id name email created_at profile { bio } } }")
+	is.Equal(req.Vars(), map[string]interface{}{"set": map[string]interface{}{"name": "Mat"}})
+
+	req, err = b.Delete(user)
+	is.NoErr(err)
+	is.Equal(req.Query(), "mutation { delete_users_by_pk(id: 1) { id name email created_at profile { bio } } }")
+}
+
+func TestCRUDBuilderPostGraphile(t *testing.T) {
+	is := is.New(t)
+	b := CRUDBuilder{Entity: "User", Style: PostGraphile}
+	user := crudUser{ID: 1, Name: "Mat", Email: "mat@example.com"}
+
+	req, err := b.Create(user)
+	is.NoErr(err)
+	is.Equal(req.Query(), "mutation ($input: CreateUserInput!) { createUser(input: $input) { user { id name email created_at profile { bio } } } }")
+	is.Equal(req.Vars()["input"], map[string]interface{}{"user": map[string]interface{}{"id": 1, "name": "Mat", "email": "mat@example.com"}})
+
+	req, err = b.Update(user)
+	is.NoErr(err)
+	is.Equal(req.Query(), "mutation ($input: UpdateUserByIdInput!) { updateUserById(input: $input) { user { id name email created_at profile { bio } } } }")
+	is.Equal(req.Vars()["input"], map[string]interface{}{"id": 1, "userPatch": map[string]interface{}{"name": "Mat", "email": "mat@example.com"}})
+
+	req, err = b.Delete(user)
+	is.NoErr(err)
+	is.Equal(req.Vars()["input"], map[string]interface{}{"id": 1})
+}
+
+func TestCRUDBuilderErrors(t *testing.T) {
+	is := is.New(t)
+	b := CRUDBuilder{Entity: "users"}
+	_, err := b.Update(struct {
+		Name string `graphql:"name"`
+	}{})
+	is.True(errors.Is(err, ErrNoKeyFields))
+	_, err = b.Create("not a struct")
+	is.True(err != nil)
+	_, err = b.Create(struct {
+		Name string `graphql:"bad name"`
+	}{})
+	is.True(err != nil)
+	is.Equal(lowerFirst("ID"), "id")
+	is.Equal(lowerFirst("URLPath"), "urlPath")
+	is.Equal(lowerFirst("Name"), "name")
+}
+
+type crudCategory struct {
+	ID       int             `graphql:"id,key"`
+	Name     string          `graphql:"name"`
+	Parent   *crudCategory   `graphql:"parent,omitempty"`
+	Children []crudCategory  `graphql:"children,omitempty"`
+	Meta     crudCategoryTag `graphql:"meta"`
+}
+
+type crudCategoryTag struct {
+	DisplayName string `graphql:"display_name" json:"displayName"`
+	Internal    string `graphql:"internal,readonly"`
+}
+
+func TestCRUDBuilderNested(t *testing.T) {
+	is := is.New(t)
+	b := CRUDBuilder{Entity: "categories"}
+	req, err := b.Create(&crudCategory{
+		Name:   "shoes",
+		Parent: &crudCategory{ID: 1, Name: "clothes", Meta: crudCategoryTag{DisplayName: "Clothes", Internal: "x"}},
+		Meta:   crudCategoryTag{DisplayName: "Shoes"},
+	})
+	is.NoErr(err)
+	// self-referential fields are not selected
+	is.Equal(req.Query(), "mutation ($object: categories_insert_input!) { insert_categories_one(object: $object) { id name meta { display_name internal } } }")
+	is.Equal(req.Vars()["object"], map[string]interface{}{
+		"id":   0,
+		"name": "shoes",
+		"parent": map[string]interface{}{
+			"id":   1,
+			"name": "clothes",
+			"meta": map[string]interface{}{"display_name": "Clothes"},
+		},
+		"meta": map[string]interface{}{"display_name": "Shoes"},
+	})
+}
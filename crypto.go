@@ -0,0 +1,93 @@
+package gographql
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// Crypto provides the cryptography used by the client: SHA-256 for
+// persisted query hashes, cache keys and request signatures, HMAC-SHA256
+// for signatures, and the random numbers, AES-GCM and RSA-OAEP of body
+// encryption. Regulated deployments can route them through a validated
+// module.
+type Crypto interface {
+	// SHA256 returns a new SHA-256 hash.
+	SHA256() hash.Hash
+	// HMACSHA256 returns a new HMAC-SHA256 keyed with key.
+	HMACSHA256(key []byte) hash.Hash
+	// Random returns a cryptographically secure random number generator.
+	Random() io.Reader
+	// AESGCM returns the AES-GCM AEAD with the standard nonce size keyed
+	// with key.
+	AESGCM(key []byte) (cipher.AEAD, error)
+	// EncryptRSAOAEP encrypts msg for pub with RSA-OAEP and SHA-256.
+	EncryptRSAOAEP(pub *rsa.PublicKey, msg []byte) ([]byte, error)
+	// DecryptRSAOAEP decrypts ciphertext with priv, RSA-OAEP and SHA-256.
+	DecryptRSAOAEP(priv *rsa.PrivateKey, ciphertext []byte) ([]byte, error)
+}
+
+// DefaultCrypto returns the Crypto of the build: the standard library,
+// or, when built with the fips tag, the standard library checked to run
+// in FIPS 140-3 mode.
+func DefaultCrypto() Crypto {
+	return defaultCrypto
+}
+
+// WithCrypto makes the client hash with crypto instead of DefaultCrypto.
+// Package level functions, such as DefaultCacheKey and AppSyncIAM, always
+// use DefaultCrypto.
+func WithCrypto(crypto Crypto) ClientOption {
+	return func(client *Client) {
+		client.crypto = crypto
+	}
+}
+
+// stdCrypto is the Crypto of the standard library.
+type stdCrypto struct{}
+
+func (stdCrypto) SHA256() hash.Hash {
+	return sha256.New()
+}
+
+func (stdCrypto) HMACSHA256(key []byte) hash.Hash {
+	return hmac.New(sha256.New, key)
+}
+
+func (stdCrypto) Random() io.Reader {
+	return rand.Reader
+}
+
+func (stdCrypto) AESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (stdCrypto) EncryptRSAOAEP(pub *rsa.PublicKey, msg []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, nil)
+}
+
+func (stdCrypto) DecryptRSAOAEP(priv *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), nil, priv, ciphertext, nil)
+}
+
+// sumSHA256 returns the SHA-256 of b computed with crypto.
+func sumSHA256(crypto Crypto, b []byte) []byte {
+	h := crypto.SHA256()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// hexSHA256 returns the hex encoded SHA-256 of b computed with crypto.
+func hexSHA256(crypto Crypto, b []byte) string {
+	return hex.EncodeToString(sumSHA256(crypto, b))
+}
@@ -0,0 +1,17 @@
+//go:build fips && go1.24
+
+package gographql
+
+import "crypto/fips140"
+
+// Builds with the fips tag use the standard library, whose Go
+// Cryptographic Module is FIPS 140-3 validated, and refuse to start
+// unless FIPS 140-3 mode is on, as with GODEBUG=fips140=on or GOFIPS140
+// at build time.
+var defaultCrypto Crypto = stdCrypto{}
+
+func init() {
+	if !fips140.Enabled() {
+		panic("gographql: built with the fips tag but FIPS 140-3 mode is off; run with GODEBUG=fips140=on")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build fips && !go1.24
+
+package gographql
+
+// The fips tag needs the crypto/fips140 package of Go 1.24 or later: this
+// reference fails the build with older versions instead of leaving the
+// package without a Crypto.
+var _ = fipsTagRequiresGo1_24
@@ -0,0 +1,5 @@
+//go:build !fips
+
+package gographql
+
+var defaultCrypto Crypto = stdCrypto{}
@@ -0,0 +1,57 @@
+package gographql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// countingCrypto counts the hashes made with DefaultCrypto.
+type countingCrypto struct {
+	stdCrypto
+	hashes atomic.Int32
+}
+
+func (c *countingCrypto) SHA256() hash.Hash {
+	c.hashes.Add(1)
+	return DefaultCrypto().SHA256()
+}
+
+func (c *countingCrypto) HMACSHA256(key []byte) hash.Hash {
+	c.hashes.Add(1)
+	return DefaultCrypto().HMACSHA256(key)
+}
+
+func TestWithCrypto(t *testing.T) {
+	is := is.New(t)
+	query := `{ me }`
+	sum := sha256.Sum256([]byte(query))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Extensions struct {
+				PersistedQuery struct{ SHA256Hash string }
+			}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Extensions.PersistedQuery.SHA256Hash, hex.EncodeToString(sum[:]))
+		io.WriteString(w, `{"data":{"me":"Mat"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	crypto := &countingCrypto{}
+	client := NewClient(srv.URL, WithAutomaticPersistedQueries(), WithCrypto(crypto))
+	is.NoErr(client.Run(ctx, NewRequest(query), nil))
+	is.Equal(crypto.hashes.Load(), int32(1))
+}
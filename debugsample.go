@@ -0,0 +1,46 @@
+package gographql
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// WithDebugSampling limits debug logging to a random fraction of the
+// operations, between 0 and 1, so detailed wire logging can stay enabled
+// in production without flooding log pipelines. All the debug logs of a
+// sampled operation are kept, so each one is complete. It has no effect
+// unless debug logging is enabled.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithDebugSampling(0.01))
+//	client.EnableDebugLog()
+func WithDebugSampling(rate float64) ClientOption {
+	return func(client *Client) {
+		if rate < 0 || rate > 1 {
+			client.invalidOption("WithDebugSampling: rate %v is not between 0 and 1", rate)
+		}
+		client.debugSampling = true
+		client.debugRate = rate
+	}
+}
+
+type debugSampledKey struct{}
+
+// sampleDebug decides whether the operation run with ctx is logged.
+func (c *Client) sampleDebug(ctx context.Context) context.Context {
+	if !c.DebugLog || !c.debugSampling {
+		return ctx
+	}
+	return context.WithValue(ctx, debugSampledKey{}, rand.Float64() < c.debugRate)
+}
+
+// debug reports whether debug logs are written for the operation run
+// with ctx.
+func (c *Client) debug(ctx context.Context) bool {
+	if !c.DebugLog {
+		return false
+	}
+	if sampled, ok := ctx.Value(debugSampledKey{}).(bool); ok {
+		return sampled
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDebugSampling(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	for rate, want := range map[float64]int{0: 0, 1: 10} {
+		var logs bytes.Buffer
+		client := NewClient(srv.URL, WithDebugSampling(rate))
+		client.SetLogger(NewLogger(&logs, "", 0)).EnableDebugLog()
+		for i := 0; i < 10; i++ {
+			is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+		}
+		is.Equal(strings.Count(logs.String(), "response body:"), want)
+		is.Equal(strings.Count(logs.String(), "query:"), want) // logs of an operation are kept together
+	}
+}
@@ -0,0 +1,42 @@
+package gographql
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// WithStrictDecoding fails decoding when the data of a response has a
+// field that the response object does not declare, so that schema drift
+// between the server and the client types shows up in tests instead of
+// fields being silently dropped. The error wraps ErrDecodingResponse.
+// Maps and interface{} values accept any field.
+func WithStrictDecoding() ClientOption {
+	return func(client *Client) {
+		client.strictDecoding = true
+	}
+}
+
+// WithUseNumber decodes the numbers of response data into interface{}
+// values, such as the values of a map[string]interface{}, as json.Number
+// instead of float64, so large integer IDs keep their precision.
+func WithUseNumber() ClientOption {
+	return func(client *Client) {
+		client.useNumber = true
+	}
+}
+
+// decodeData decodes the data of a response into resp, as configured by
+// WithStrictDecoding and WithUseNumber.
+func (c *Client) decodeData(data []byte, resp interface{}) error {
+	if _, std := c.codec.(stdCodec); std && !c.strictDecoding && !c.useNumber {
+		return json.Unmarshal(data, resp)
+	}
+	dec := c.codec.NewDecoder(bytes.NewReader(data))
+	if d, ok := dec.(interface{ DisallowUnknownFields() }); ok && c.strictDecoding {
+		d.DisallowUnknownFields()
+	}
+	if d, ok := dec.(interface{ UseNumber() }); ok && c.useNumber {
+		d.UseNumber()
+	}
+	return dec.Decode(resp)
+}
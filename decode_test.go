@@ -0,0 +1,57 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"name":"Mat","email":"mat@example.com"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest(`{ user { name email } }`), &resp))
+	is.Equal(resp.User.Name, "Mat")
+
+	err := NewClient(srv.URL, WithStrictDecoding()).Run(ctx, NewRequest(`{ user { name email } }`), &resp)
+	is.True(errors.Is(err, ErrDecodingResponse))
+
+	var generic map[string]interface{}
+	is.NoErr(NewClient(srv.URL, WithStrictDecoding()).Run(ctx, NewRequest(`{ user { name email } }`), &generic))
+}
+
+func TestUseNumber(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"order":{"id":9007199254740993,"total":12.5}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp map[string]map[string]interface{}
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest(`{ order { id total } }`), &resp))
+	is.Equal(resp["order"]["id"], float64(9007199254740992))
+
+	resp = nil
+	is.NoErr(NewClient(srv.URL, WithUseNumber()).Run(ctx, NewRequest(`{ order { id total } }`), &resp))
+	is.Equal(resp["order"]["id"], json.Number("9007199254740993"))
+	is.Equal(resp["order"]["total"], json.Number("12.5"))
+}
@@ -0,0 +1,113 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// WithRequestDeduplication coalesces identical requests made
+// concurrently, with the same query, variables and headers, including
+// default headers and those derived from the context, into a single
+// HTTP call whose result is shared by all of them. Mutations and file
+// uploads are always sent on their own. A caller whose context is done
+// stops waiting, but the shared call runs with the context of the first
+// caller, whose cancellation fails every request waiting on it. Clients
+// made with Client.With do not share requests with c.
+func WithRequestDeduplication() ClientOption {
+	return func(client *Client) {
+		client.inFlight = &flightGroup{}
+	}
+}
+
+// flightGroup tracks the requests in flight by key.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a request in flight, whose result is set once done is
+// closed.
+type flightCall struct {
+	done chan struct{}
+	data json.RawMessage
+	meta responseMeta
+	err  error
+}
+
+// sendShared sends req, or waits for an identical request in flight, and
+// decodes the shared data into resp.
+func (c *Client) sendShared(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	key, ok := c.flightKey(ctx, req)
+	if !ok {
+		return c.sendOne(ctx, req, resp, meta)
+	}
+	g := c.inFlight
+	g.mu.Lock()
+	call, shared := g.calls[key]
+	if !shared {
+		// the body is kept for callers sharing the call that need it
+		call = &flightCall{done: make(chan struct{}), meta: responseMeta{keepBody: true}}
+		if g.calls == nil {
+			g.calls = make(map[string]*flightCall)
+		}
+		g.calls[key] = call
+	}
+	g.mu.Unlock()
+	if !shared {
+		call.err = c.sendOne(ctx, req, &call.data, &call.meta)
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	} else {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if meta != nil {
+		*meta = call.meta
+	}
+	if resp != nil && len(call.data) > 0 && string(call.data) != "null" {
+		if err := c.decodeData(call.data, resp); err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+	}
+	return call.err
+}
+
+// flightKey returns the key identifying the requests identical to req,
+// and false if req must not be shared.
+func (c *Client) flightKey(ctx context.Context, req *Request) (string, bool) {
+	if len(req.files) > 0 || req.batch != nil {
+		return "", false
+	}
+	if kind, _ := operationInfo(req.q); kind == "mutation" {
+		return "", false
+	}
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return "", false
+	}
+	// the headers sent, with those of the client options and ctx, as they
+	// carry the credentials; trace headers are left out so requests of
+	// different traces are still shared
+	header := c.outgoingHeader(ctx, req)
+	for _, key := range []string{"Traceparent", "Tracestate", "Baggage"} {
+		header.Del(key)
+	}
+	b, err := json.Marshal(struct {
+		Endpoint  string
+		Method    string
+		Query     string
+		Variables map[string]interface{}
+		Header    map[string][]string
+	}{endpoint, req.method, req.q, req.vars, header})
+	if err != nil {
+		return "", false
+	}
+	return hexSHA256(c.crypto, b), true
+}
@@ -0,0 +1,115 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRequestDeduplication(t *testing.T) {
+	is := is.New(t)
+	var calls atomic.Int32
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		received <- struct{}{}
+		<-release
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithRequestDeduplication())
+	var wg sync.WaitGroup
+	names := make([]string, 5)
+	errs := make([]error, 5)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := NewRequest(`query ($id: ID!) { user(id: $id) { name } }`)
+			req.Var("id", "1")
+			var resp struct{ User struct{ Name string } }
+			errs[i] = client.Run(ctx, req, &resp)
+			names[i] = resp.User.Name
+		}()
+	}
+	<-received
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	is.Equal(calls.Load(), int32(1))
+	for i := range names {
+		is.NoErr(errs[i])
+		is.Equal(names[i], "Mat")
+	}
+
+	// requests differing in headers and mutations are not shared
+	other := NewRequest(`query ($id: ID!) { user(id: $id) { name } }`)
+	other.Var("id", "1")
+	other.SetHeader("Authorization", "Bearer other")
+	is.NoErr(client.Run(ctx, other, nil))
+	is.NoErr(client.Run(ctx, NewRequest(`mutation { save }`), nil))
+	is.Equal(calls.Load(), int32(3))
+}
+
+func TestRequestDeduplicationCredentials(t *testing.T) {
+	is := is.New(t)
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		me := r.Header.Get("Authorization") + r.Header.Get(DefaultImpersonationHeader)
+		io.WriteString(w, `{"data":{"me":"`+me+`"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	base := NewClient(srv.URL, WithRequestDeduplication(), WithImpersonation(Impersonation{}))
+	alice := base.With(WithDefaultHeaders(http.Header{"Authorization": {"alice"}}))
+	bob := base.With(WithDefaultHeaders(http.Header{"Authorization": {"bob"}}))
+	runs := map[string]func() (string, error){
+		"alice": func() (string, error) { return runMe(ctx, alice) },
+		"bob":   func() (string, error) { return runMe(ctx, bob) },
+		// the same client, with credentials from the context
+		"carol": func() (string, error) { return runMe(ContextWithImpersonation(ctx, "carol"), base) },
+		"dave":  func() (string, error) { return runMe(ContextWithImpersonation(ctx, "dave"), base) },
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	got := make(map[string]string)
+	for name, run := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			me, err := run()
+			is.NoErr(err)
+			mu.Lock()
+			got[name] = me
+			mu.Unlock()
+		}()
+	}
+	for range runs {
+		<-received
+	}
+	close(release)
+	wg.Wait()
+	is.Equal(got, map[string]string{"alice": "alice", "bob": "bob", "carol": "carol", "dave": "dave"})
+}
+
+func runMe(ctx context.Context, client *Client) (string, error) {
+	var resp struct{ Me string }
+	err := client.Run(ctx, NewRequest(`{ me }`), &resp)
+	return resp.Me, err
+}
@@ -0,0 +1,721 @@
+package gographql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDocument the GraphQL document could not be parsed.
+var ErrInvalidDocument = errors.New("invalid graphql document")
+
+// Document is a parsed executable GraphQL document. It is deliberately
+// shallow: selection sets are fully parsed so they can be inspected and
+// edited, while arguments, directives and variable definitions are kept
+// as source text.
+type Document struct {
+	Definitions []*Definition
+}
+
+// Definition is an operation or fragment definition.
+type Definition struct {
+	// Kind is "query", "mutation", "subscription" or "fragment".
+	Kind string
+	// Name is the operation or fragment name, empty for anonymous operations.
+	Name string
+	// Header is the source text between the name and the selection set:
+	// variable definitions and directives for operations, the type
+	// condition and directives for fragments.
+	Header     string
+	Selections []*Selection
+}
+
+// SelectionKind identifies the kind of a Selection.
+type SelectionKind int
+
+const (
+	// FieldSelection is a field, possibly aliased.
+	FieldSelection SelectionKind = iota
+	// FragmentSpread is a named fragment spread (...Name).
+	FragmentSpread
+	// InlineFragment is an inline fragment (... on Type { }).
+	InlineFragment
+)
+
+// Selection is a field, fragment spread or inline fragment in a selection set.
+type Selection struct {
+	Kind SelectionKind
+	// Alias is the field alias, if any.
+	Alias string
+	// Name is the field name or the spread fragment name.
+	Name string
+	// TypeCondition is the type of an inline fragment, if any.
+	TypeCondition string
+	// Arguments is the source text of the field arguments, including parentheses.
+	Arguments string
+	// Directives is the source text of the directives.
+	Directives string
+	// Selections is the sub-selection set, nil for leaf fields and spreads.
+	Selections []*Selection
+}
+
+// ResponseKey returns the key under which a field appears in the response.
+func (s *Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// ParseDocument parses an executable GraphQL document.
+func ParseDocument(src string) (*Document, error) {
+	p := &parser{lex: lexer{src: src}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	for p.tok.kind != tokEOF {
+		def, err := p.parseDefinition()
+		if err != nil {
+			return nil, err
+		}
+		doc.Definitions = append(doc.Definitions, def)
+	}
+	if len(doc.Definitions) == 0 {
+		return nil, fmt.Errorf("%w: no definitions", ErrInvalidDocument)
+	}
+	return doc, nil
+}
+
+// Operation returns the operation called name, or the first operation
+// when name is empty. It returns nil if there is no such operation.
+func (d *Document) Operation(name string) *Definition {
+	for _, def := range d.Definitions {
+		if def.Kind == "fragment" {
+			continue
+		}
+		if name == "" || def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// Fragment returns the fragment called name, or nil.
+func (d *Document) Fragment(name string) *Definition {
+	for _, def := range d.Definitions {
+		if def.Kind == "fragment" && def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// HasField reports whether the first operation selects the field at path,
+// a dot separated list of response keys such as "user.profile.avatar".
+func (d *Document) HasField(path string) bool {
+	_, err := d.lookup(path)
+	return err == nil
+}
+
+// AddField adds field to the selection set at path in the first
+// operation. field is a selection in GraphQL syntax, for example
+// "avatarUrl", "small: avatar(size: 64)" or "friends { id name }".
+// An empty path adds to the operation's top level selection set.
+func (d *Document) AddField(path, field string) error {
+	sels, err := parseSelections(field)
+	if err != nil {
+		return err
+	}
+	target, err := d.selectionSet(path)
+	if err != nil {
+		return err
+	}
+	for _, sel := range sels {
+		if sel.Kind == FieldSelection {
+			if existing := findField(*target, sel.ResponseKey()); existing != nil {
+				if existing.Name != sel.Name || existing.Arguments != sel.Arguments {
+					return fmt.Errorf("%w: field %q conflicts with existing selection", ErrInvalidDocument, sel.ResponseKey())
+				}
+				continue
+			}
+		}
+		*target = append(*target, sel)
+	}
+	return nil
+}
+
+// RemoveField removes the field at path from the first operation.
+// Removing the only field of a selection set is an error since it would
+// leave the document invalid.
+func (d *Document) RemoveField(path string) error {
+	parent, key := "", path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, key = path[:i], path[i+1:]
+	}
+	target, err := d.selectionSet(parent)
+	if err != nil {
+		return err
+	}
+	for i, sel := range *target {
+		if sel.Kind == FieldSelection && sel.ResponseKey() == key {
+			if len(*target) == 1 {
+				return fmt.Errorf("%w: cannot remove the only selection of %q", ErrInvalidDocument, parent)
+			}
+			*target = append((*target)[:i], (*target)[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no field at %q", ErrInvalidDocument, path)
+}
+
+// selectionSet returns a pointer to the selection set at path.
+func (d *Document) selectionSet(path string) (*[]*Selection, error) {
+	op := d.Operation("")
+	if op == nil {
+		return nil, fmt.Errorf("%w: no operation", ErrInvalidDocument)
+	}
+	if path == "" {
+		return &op.Selections, nil
+	}
+	sel, err := d.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if sel.Selections == nil {
+		return nil, fmt.Errorf("%w: %q is a leaf field", ErrInvalidDocument, path)
+	}
+	return &sel.Selections, nil
+}
+
+func (d *Document) lookup(path string) (*Selection, error) {
+	op := d.Operation("")
+	if op == nil {
+		return nil, fmt.Errorf("%w: no operation", ErrInvalidDocument)
+	}
+	sels := op.Selections
+	var sel *Selection
+	for _, key := range strings.Split(path, ".") {
+		sel = d.findFieldDeep(sels, key)
+		if sel == nil {
+			return nil, fmt.Errorf("%w: no field at %q", ErrInvalidDocument, path)
+		}
+		sels = sel.Selections
+	}
+	return sel, nil
+}
+
+// findFieldDeep finds a field by response key, looking through inline
+// fragments and named fragment spreads.
+func (d *Document) findFieldDeep(sels []*Selection, key string) *Selection {
+	if sel := findField(sels, key); sel != nil {
+		return sel
+	}
+	for _, sel := range sels {
+		var found *Selection
+		switch sel.Kind {
+		case InlineFragment:
+			found = d.findFieldDeep(sel.Selections, key)
+		case FragmentSpread:
+			if frag := d.Fragment(sel.Name); frag != nil {
+				found = d.findFieldDeep(frag.Selections, key)
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findField(sels []*Selection, key string) *Selection {
+	for _, sel := range sels {
+		if sel.Kind == FieldSelection && sel.ResponseKey() == key {
+			return sel
+		}
+	}
+	return nil
+}
+
+// String prints the document in GraphQL syntax.
+func (d *Document) String() string {
+	var b strings.Builder
+	for i, def := range d.Definitions {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(def.Kind)
+		if def.Name != "" {
+			b.WriteString(" ")
+			b.WriteString(def.Name)
+		}
+		if def.Header != "" {
+			if def.Kind == "fragment" || !strings.HasPrefix(def.Header, "(") || def.Name == "" {
+				b.WriteString(" ")
+			}
+			b.WriteString(def.Header)
+		}
+		b.WriteString(" ")
+		writeSelections(&b, def.Selections, 0)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeSelections(b *strings.Builder, sels []*Selection, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	b.WriteString("{\n")
+	for _, sel := range sels {
+		b.WriteString(indent)
+		switch sel.Kind {
+		case FieldSelection:
+			if sel.Alias != "" {
+				b.WriteString(sel.Alias)
+				b.WriteString(": ")
+			}
+			b.WriteString(sel.Name)
+			b.WriteString(sel.Arguments)
+		case FragmentSpread:
+			b.WriteString("...")
+			b.WriteString(sel.Name)
+		case InlineFragment:
+			b.WriteString("...")
+			if sel.TypeCondition != "" {
+				b.WriteString(" on ")
+				b.WriteString(sel.TypeCondition)
+			}
+		}
+		if sel.Directives != "" {
+			b.WriteString(" ")
+			b.WriteString(sel.Directives)
+		}
+		if sel.Selections != nil {
+			b.WriteString(" ")
+			writeSelections(b, sel.Selections, depth+1)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("}")
+}
+
+// parseSelections parses the body of a selection set.
+func parseSelections(src string) ([]*Selection, error) {
+	p := &parser{lex: lexer{src: "{" + src + "}"}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	sels, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.value)
+	}
+	return sels, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line, col := p.lex.position(p.tok.start)
+	return fmt.Errorf("%w: %d:%d: %s", ErrInvalidDocument, line, col, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) is(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.is(kind, value) {
+		return p.errorf("expected %q, found %q", value, p.tok.value)
+	}
+	return p.next()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.errorf("expected name, found %q", p.tok.value)
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *parser) parseDefinition() (*Definition, error) {
+	def := &Definition{Kind: "query"}
+	if p.is(tokPunct, "{") {
+		sels, err := p.parseSelectionSet()
+		def.Selections = sels
+		return def, err
+	}
+	if p.tok.kind != tokName {
+		return nil, p.errorf("unexpected %q", p.tok.value)
+	}
+	switch p.tok.value {
+	case "query", "mutation", "subscription":
+		def.Kind = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokName {
+			def.Name = p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		start := p.tok.start
+		if p.is(tokPunct, "(") {
+			if err := p.skipGroup("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		def.Header = strings.TrimSpace(p.lex.src[start:p.tok.start])
+	case "fragment":
+		def.Kind = "fragment"
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		def.Name = name
+		start := p.tok.start
+		if err := p.expect(tokName, "on"); err != nil {
+			return nil, err
+		}
+		if _, err := p.expectName(); err != nil {
+			return nil, err
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		def.Header = strings.TrimSpace(p.lex.src[start:p.tok.start])
+	default:
+		return nil, p.errorf("unsupported definition %q", p.tok.value)
+	}
+	sels, err := p.parseSelectionSet()
+	def.Selections = sels
+	return def, err
+}
+
+func (p *parser) parseSelectionSet() ([]*Selection, error) {
+	if err := p.expect(tokPunct, "{"); err != nil {
+		return nil, err
+	}
+	sels := make([]*Selection, 0)
+	for !p.is(tokPunct, "}") {
+		if p.tok.kind == tokEOF {
+			return nil, p.errorf("unterminated selection set")
+		}
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+	return sels, p.next()
+}
+
+func (p *parser) parseSelection() (*Selection, error) {
+	sel := &Selection{}
+	if p.is(tokPunct, "...") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokName && p.tok.value != "on" {
+			sel.Kind = FragmentSpread
+			sel.Name = p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			directives, err := p.captureDirectives()
+			sel.Directives = directives
+			return sel, err
+		}
+		sel.Kind = InlineFragment
+		if p.is(tokName, "on") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			typeCondition, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			sel.TypeCondition = typeCondition
+		}
+		directives, err := p.captureDirectives()
+		if err != nil {
+			return nil, err
+		}
+		sel.Directives = directives
+		sel.Selections, err = p.parseSelectionSet()
+		return sel, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	sel.Name = name
+	if p.is(tokPunct, ":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		sel.Alias = name
+		if sel.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.is(tokPunct, "(") {
+		start := p.tok.start
+		if err := p.skipGroup("(", ")"); err != nil {
+			return nil, err
+		}
+		sel.Arguments = p.lex.src[start:p.prevEnd()]
+	}
+	if sel.Directives, err = p.captureDirectives(); err != nil {
+		return nil, err
+	}
+	if p.is(tokPunct, "{") {
+		if sel.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+// prevEnd returns the end offset of the previous token.
+func (p *parser) prevEnd() int {
+	return p.lex.prevEnd
+}
+
+func (p *parser) captureDirectives() (string, error) {
+	if !p.is(tokPunct, "@") {
+		return "", nil
+	}
+	start := p.tok.start
+	if err := p.skipDirectives(); err != nil {
+		return "", err
+	}
+	return p.lex.src[start:p.prevEnd()], nil
+}
+
+func (p *parser) skipDirectives() error {
+	for p.is(tokPunct, "@") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if _, err := p.expectName(); err != nil {
+			return err
+		}
+		if p.is(tokPunct, "(") {
+			if err := p.skipGroup("(", ")"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipGroup skips a balanced group of tokens starting at open.
+func (p *parser) skipGroup(open, close string) error {
+	depth := 0
+	for {
+		switch {
+		case p.tok.kind == tokEOF:
+			return p.errorf("unterminated %q", open)
+		case p.tok.kind == tokPunct && (p.tok.value == "(" || p.tok.value == "[" || p.tok.value == "{"):
+			depth++
+		case p.tok.kind == tokPunct && (p.tok.value == ")" || p.tok.value == "]" || p.tok.value == "}"):
+			depth--
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokNumber
+	tokString
+)
+
+type token struct {
+	kind       tokenKind
+	value      string
+	start, end int
+}
+
+type lexer struct {
+	src     string
+	pos     int
+	prevEnd int
+	lastEnd int
+}
+
+func (l *lexer) position(offset int) (line, col int) {
+	line = 1 + strings.Count(l.src[:offset], "\n")
+	col = offset - strings.LastIndex(l.src[:offset], "\n")
+	return line, col
+}
+
+func (l *lexer) errorf(offset int, format string, args ...interface{}) error {
+	line, col := l.position(offset)
+	return fmt.Errorf("%w: %d:%d: %s", ErrInvalidDocument, line, col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) next() (token, error) {
+	l.prevEnd = l.lastEnd
+	tok, err := l.scan()
+	l.lastEnd = tok.end
+	return tok, err
+}
+
+func (l *lexer) scan() (token, error) {
+	// skip ignored tokens: whitespace, commas, comments and the BOM
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.pos++
+			continue
+		}
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if strings.HasPrefix(l.src[l.pos:], "\uFEFF") {
+			l.pos += len("\uFEFF")
+			continue
+		}
+		break
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, start: start, end: start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, value: "...", start: start, end: l.pos}, nil
+	case strings.ContainsRune("!$&():=@[]{|}", rune(c)):
+		l.pos++
+		return token{kind: tokPunct, value: string(c), start: start, end: l.pos}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, value: l.src[start:l.pos], start: start, end: l.pos}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || strings.IndexByte(".eE+-", l.src[l.pos]) >= 0) {
+			l.pos++
+		}
+		return token{kind: tokNumber, value: l.src[start:l.pos], start: start, end: l.pos}, nil
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		end := strings.Index(l.src[l.pos+3:], `"""`)
+		for end >= 0 && l.src[l.pos+3+end-1] == '\\' {
+			next := strings.Index(l.src[l.pos+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			return token{}, l.errorf(start, "unterminated block string")
+		}
+		l.pos += 3 + end + 3
+		return token{kind: tokString, value: l.src[start:l.pos], start: start, end: l.pos}, nil
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\n' || l.src[l.pos] == '\r' {
+				return token{}, l.errorf(start, "unterminated string")
+			}
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, l.errorf(start, "unterminated string")
+		}
+		l.pos++
+		return token{kind: tokString, value: l.src[start:l.pos], start: start, end: l.pos}, nil
+	}
+	return token{}, l.errorf(start, "unexpected character %q", c)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// operationInfo returns the type and name of the first operation in src
+// without parsing the whole document. It returns empty strings if src
+// contains no operation.
+func operationInfo(src string) (kind, name string) {
+	l := lexer{src: src}
+	depth := 0
+	inFragment := false
+	for {
+		tok, err := l.next()
+		if err != nil || tok.kind == tokEOF {
+			return "", ""
+		}
+		switch {
+		case tok.kind == tokPunct && tok.value == "{":
+			if depth == 0 && !inFragment {
+				return "query", ""
+			}
+			depth++
+		case tok.kind == tokPunct && tok.value == "}":
+			depth--
+			if depth == 0 {
+				inFragment = false
+			}
+		case depth == 0 && tok.kind == tokName:
+			switch tok.value {
+			case "query", "mutation", "subscription":
+				if next, err := l.next(); err == nil && next.kind == tokName {
+					name = next.value
+				}
+				return tok.value, name
+			case "fragment":
+				inFragment = true
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package gographql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testDocument = `# fetch a user
+query GetUser($id: ID!, $filter: Filter = {tags: ["a", "b"]}) @cached(ttl: 60) {
+	user(id: $id) {
+		id
+		name: fullName
+		... on Admin { permissions }
+		...Profile
+	}
+}
+
+fragment Profile on User {
+	profile { bio }
+}
+`
+
+func TestParseDocument(t *testing.T) {
+	is := is.New(t)
+	doc, err := ParseDocument(testDocument)
+	is.NoErr(err)
+	is.Equal(len(doc.Definitions), 2)
+	op := doc.Operation("")
+	is.Equal(op.Kind, "query")
+	is.Equal(op.Name, "GetUser")
+	is.Equal(op.Header, `($id: ID!, $filter: Filter = {tags: ["a", "b"]}) @cached(ttl: 60)`)
+	user := op.Selections[0]
+	is.Equal(user.Name, "user")
+	is.Equal(user.Arguments, "(id: $id)")
+	is.Equal(user.Selections[1].ResponseKey(), "name")
+	is.Equal(user.Selections[2].Kind, InlineFragment)
+	is.Equal(user.Selections[3].Kind, FragmentSpread)
+	is.True(doc.HasField("user.permissions"))
+	is.True(doc.HasField("user.profile.bio"))
+	is.True(!doc.HasField("user.email"))
+
+	reparsed, err := ParseDocument(doc.String())
+	is.NoErr(err)
+	is.Equal(reparsed.String(), doc.String())
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	is := is.New(t)
+	for _, src := range []string{
+		``,
+		`query {`,
+		`query { }`,
+		`query { user(id: "1) }`,
+		`type Query { a: Int }`,
+	} {
+		_, err := ParseDocument(src)
+		is.True(errors.Is(err, ErrInvalidDocument))
+	}
+}
+
+func TestDocumentAddRemoveField(t *testing.T) {
+	is := is.New(t)
+	doc, err := ParseDocument(`{ user { id email } }`)
+	is.NoErr(err)
+	is.NoErr(doc.AddField("user", "avatar(size: 64) { url }"))
+	is.NoErr(doc.AddField("user", "id")) // already selected
+	is.NoErr(doc.RemoveField("user.email"))
+	is.Equal(doc.String(), "query {\n  user {\n    id\n    avatar(size: 64) {\n      url\n    }\n  }\n}\n")
+
+	is.True(doc.AddField("user", "id: name") != nil)    // conflicting response key
+	is.True(doc.AddField("user.id", "x") != nil)        // leaf field
+	is.True(doc.AddField("missing", "x") != nil)        // unknown path
+	is.True(doc.AddField("user", "bad field {") != nil) // invalid syntax
+	is.True(doc.RemoveField("user.avatar.url") != nil)  // only selection
+	is.True(doc.RemoveField("user.missing") != nil)     // unknown field
+}
@@ -0,0 +1,109 @@
+package gographql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNoDownload the response has no downloadable value at the given path.
+var ErrNoDownload = errors.New("no downloadable value at path")
+
+// ProgressFunc is called as bytes are transferred. total is -1 when the
+// size is not known in advance.
+type ProgressFunc func(transferred, total int64)
+
+// Download runs req and copies the file referenced by the string at path
+// in the response data to w. The value may be an http(s) URL, typically a
+// signed download link, which is fetched without the request headers and
+// streamed, or base64 encoded content. progress, if not nil, is called
+// after every chunk. It returns the number of bytes written to w.
+//
+// Base64 content is not streamed: the GraphQL response is read into
+// memory in full, and the value is decoded from it, so large files are
+// better served as download links.
+//
+//	n, err := client.Download(ctx, req, "export.url", f, nil)
+func (c *Client) Download(ctx context.Context, req *Request, path string, w io.Writer, progress ProgressFunc) (int64, error) {
+	raw, err := c.RunRaw(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := raw.GetPath("data." + path).(string)
+	if !ok || value == "" {
+		return 0, fmt.Errorf("%w: %s", ErrNoDownload, path)
+	}
+	if strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://") {
+		return c.downloadURL(ctx, value, w, progress)
+	}
+	total := int64(base64.StdEncoding.DecodedLen(len(value)))
+	return copyWithProgress(w, base64.NewDecoder(base64Encoding(value), strings.NewReader(value)), total, progress)
+}
+
+func (c *Client) downloadURL(ctx context.Context, url string, w io.Writer, progress ProgressFunc) (int64, error) {
+	res, err := c.openURL(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	return copyWithProgress(w, res.Body, res.ContentLength, progress)
+}
+
+// openURL fetches url without the request headers. The caller must close
+// the response body.
+func (c *Client) openURL(ctx context.Context, url string) (*http.Response, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("download failed; statuscode: %v", res.StatusCode)
+	}
+	return res, nil
+}
+
+// base64Encoding picks the encoding of s, which may be URL safe and may
+// lack padding.
+func base64Encoding(s string) *base64.Encoding {
+	urlSafe := strings.ContainsAny(s, "-_")
+	padded := strings.HasSuffix(s, "=") || len(s)%4 == 0
+	switch {
+	case urlSafe && padded:
+		return base64.URLEncoding
+	case urlSafe:
+		return base64.RawURLEncoding
+	case padded:
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
+
+func copyWithProgress(w io.Writer, r io.Reader, total int64, progress ProgressFunc) (int64, error) {
+	return io.Copy(&progressWriter{w: w, total: total, progress: progress}, r)
+}
@@ -0,0 +1,63 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDownloadURL(t *testing.T) {
+	is := is.New(t)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file" {
+			is.Equal(r.Header.Get("Authorization"), "") // request headers are not leaked
+			io.WriteString(w, "file contents")
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"export":{"url":%q}}}`, srv.URL+"/file?sig=abc")
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL + "/graphql")
+	req := NewRequest("query { export { url } }")
+	req.Header.Set("Authorization", "Bearer token")
+	var buf bytes.Buffer
+	var last int64
+	n, err := client.Download(ctx, req, "export.url", &buf, func(transferred, total int64) {
+		last = transferred
+	})
+	is.NoErr(err)
+	is.Equal(n, int64(13))
+	is.Equal(last, int64(13))
+	is.Equal(buf.String(), "file contents")
+}
+
+func TestDownloadBase64(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"report":{"content":"aGVsbG8gd29ybGQ"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	var buf bytes.Buffer
+	_, err := client.Download(ctx, NewRequest("query {}"), "report.content", &buf, nil)
+	is.NoErr(err)
+	is.Equal(buf.String(), "hello world")
+
+	_, err = client.Download(ctx, NewRequest("query {}"), "report.missing", &buf, nil)
+	is.True(errors.Is(err, ErrNoDownload))
+}
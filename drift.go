@@ -0,0 +1,232 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShapeChange is a single difference between two observed response shapes.
+type ShapeChange struct {
+	// Path is the dot separated response path, "#" standing for array
+	// elements.
+	Path string
+	// Old and New are the JSON types seen before and now, empty when the
+	// field was absent.
+	Old, New string
+}
+
+// ShapeDrift reports that the shape of an operation's response changed.
+type ShapeDrift struct {
+	Operation string
+	Changes   []ShapeChange
+}
+
+// ShapeRecorder records the shape (fields and JSON types) of responses per
+// operation and reports when it changes, as a lightweight canary for
+// unannounced API changes. Shapes can be saved and loaded to compare
+// across program runs. A null value is compatible with any type.
+type ShapeRecorder struct {
+	mu      sync.Mutex
+	shapes  map[string]map[string]string
+	onDrift func(ShapeDrift)
+}
+
+// NewShapeRecorder makes a new ShapeRecorder. onDrift, if not nil, is
+// called for every detected drift in addition to the warning logged by
+// the client.
+func NewShapeRecorder(onDrift func(ShapeDrift)) *ShapeRecorder {
+	return &ShapeRecorder{
+		shapes:  make(map[string]map[string]string),
+		onDrift: onDrift,
+	}
+}
+
+// WithDriftDetection records response shapes into rec and logs a warning
+// whenever the shape of an operation changes.
+func WithDriftDetection(rec *ShapeRecorder) ClientOption {
+	return func(client *Client) {
+		client.drift = rec
+	}
+}
+
+// Observe records the shape of data for operation and returns the
+// differences to the previously recorded shape, if any.
+func (r *ShapeRecorder) Observe(operation string, data json.RawMessage) (*ShapeDrift, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	shape := make(map[string]string)
+	collectShape(v, "", shape)
+
+	r.mu.Lock()
+	old, seen := r.shapes[operation]
+	r.shapes[operation] = mergeShapes(old, shape)
+	r.mu.Unlock()
+	if !seen {
+		return nil, nil
+	}
+	var changes []ShapeChange
+	for path, typ := range shape {
+		oldType, ok := old[path]
+		switch {
+		case !ok:
+			changes = append(changes, ShapeChange{Path: path, New: typ})
+		case oldType != typ && oldType != "null" && typ != "null":
+			changes = append(changes, ShapeChange{Path: path, Old: oldType, New: typ})
+		}
+	}
+	for path, typ := range old {
+		if _, ok := shape[path]; !ok && !underNull(path, shape) {
+			changes = append(changes, ShapeChange{Path: path, Old: typ})
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	drift := &ShapeDrift{Operation: operation, Changes: changes}
+	if r.onDrift != nil {
+		r.onDrift(*drift)
+	}
+	return drift, nil
+}
+
+// Save writes the recorded shapes to w as JSON.
+func (r *ShapeRecorder) Save(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.NewEncoder(w).Encode(r.shapes)
+}
+
+// Load merges shapes previously written by Save.
+func (r *ShapeRecorder) Load(rd io.Reader) error {
+	var shapes map[string]map[string]string
+	if err := json.NewDecoder(rd).Decode(&shapes); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for op, shape := range shapes {
+		r.shapes[op] = shape
+	}
+	return nil
+}
+
+// SaveFile writes the recorded shapes to path.
+func (r *ShapeRecorder) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadFile loads shapes from path. A missing file is not an error.
+func (r *ShapeRecorder) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Load(f)
+}
+
+func (c *Client) detectDrift(ctx context.Context, req *Request, data json.RawMessage) {
+	var name string
+	if op, ok := OperationFromContext(ctx); ok && op.Name != "" {
+		name = op.Name
+	} else {
+		name = "anonymous:" + hexSHA256(c.crypto, []byte(req.q))[:16]
+	}
+	drift, err := c.drift.Observe(name, data)
+	if err != nil || drift == nil {
+		return
+	}
+	for _, change := range drift.Changes {
+		switch {
+		case change.Old == "":
+			c.log.Warnf("response drift in %s: field %s added (%s)", name, change.Path, change.New)
+		case change.New == "":
+			c.log.Warnf("response drift in %s: field %s removed (was %s)", name, change.Path, change.Old)
+		default:
+			c.log.Warnf("response drift in %s: field %s changed from %s to %s", name, change.Path, change.Old, change.New)
+		}
+	}
+}
+
+func collectShape(v interface{}, path string, shape map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if path != "" {
+			shape[path] = "object"
+		}
+		for key, value := range v {
+			collectShape(value, join(key), shape)
+		}
+	case []interface{}:
+		shape[path] = "array"
+		for _, value := range v {
+			collectShape(value, join("#"), shape)
+		}
+	case string:
+		shape[path] = "string"
+	case float64, json.Number:
+		shape[path] = "number"
+	case bool:
+		shape[path] = "boolean"
+	case nil:
+		if _, ok := shape[path]; !ok {
+			shape[path] = "null"
+		}
+	}
+}
+
+// mergeShapes returns the new shape, keeping the known type of fields
+// that are null this time.
+func mergeShapes(old, shape map[string]string) map[string]string {
+	merged := make(map[string]string, len(shape))
+	for path, typ := range shape {
+		if typ == "null" && old[path] != "" {
+			typ = old[path]
+		}
+		merged[path] = typ
+	}
+	for path, typ := range old {
+		if _, ok := merged[path]; !ok && underNull(path, shape) {
+			merged[path] = typ
+		}
+	}
+	return merged
+}
+
+// underNull reports whether an ancestor of path is null or an empty array
+// in shape, in which case the absence of path is not a change.
+func underNull(path string, shape map[string]string) bool {
+	for i := strings.LastIndex(path, "."); i >= 0; i = strings.LastIndex(path[:i], ".") {
+		parent := path[:i]
+		if typ, ok := shape[parent]; ok {
+			return typ == "null" || typ == "array"
+		}
+	}
+	return false
+}
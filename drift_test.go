@@ -0,0 +1,59 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDriftDetection(t *testing.T) {
+	is := is.New(t)
+	responses := []string{
+		`{"data":{"user":{"id":"1","age":30,"tags":["a"],"manager":{"id":"2"}}}}`,
+		`{"data":{"user":{"id":"1","age":null,"tags":[],"manager":null}}}`,
+		`{"data":{"user":{"id":1,"tags":["a"],"email":"x@example.com"}}}`,
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, responses[calls])
+		calls++
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var drifts []ShapeDrift
+	rec := NewShapeRecorder(func(d ShapeDrift) { drifts = append(drifts, d) })
+	var logs bytes.Buffer
+	client := NewClient(srv.URL, WithDriftDetection(rec))
+	client.SetLogger(NewLogger(&logs, "", 0))
+	req := NewRequest("query GetUser { user { id age tags manager { id } } }")
+	is.NoErr(client.Run(ctx, req, nil))
+	is.NoErr(client.Run(ctx, req, nil)) // nulls and empty lists are not drift
+	is.Equal(len(drifts), 0)
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(len(drifts), 1)
+	is.Equal(drifts[0].Operation, "GetUser")
+	is.Equal(drifts[0].Changes, []ShapeChange{
+		{Path: "user.age", Old: "number"},
+		{Path: "user.email", New: "string"},
+		{Path: "user.id", Old: "string", New: "number"},
+		{Path: "user.manager", Old: "object"},
+		{Path: "user.manager.id", Old: "string"},
+	})
+	is.True(bytes.Contains(logs.Bytes(), []byte("WARN [req] response drift in GetUser: field user.id changed from string to number")))
+
+	var saved bytes.Buffer
+	is.NoErr(rec.Save(&saved))
+	loaded := NewShapeRecorder(nil)
+	is.NoErr(loaded.Load(&saved))
+	drift, err := loaded.Observe("GetUser", []byte(`{"user":{"id":1,"tags":["b"],"email":"y"}}`))
+	is.NoErr(err)
+	is.Equal(drift, nil)
+}
@@ -0,0 +1,76 @@
+package gographql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestBodyEncryption(t *testing.T) {
+	is := is.New(t)
+	key := make([]byte, 32)
+	rand.Read(key)
+	keys := SharedKey("k1", key)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, http.MethodPost)
+		is.Equal(r.Header.Get("Content-Type"), "application/jose")
+		b, _ := io.ReadAll(r.Body)
+		plaintext, err := decryptJWE(r.Context(), DefaultCrypto(), keys, b)
+		is.NoErr(err)
+		is.Equal(string(plaintext), `{"query":"{ me }","variables":null}`+"\n")
+		out, err := encryptJWE(r.Context(), DefaultCrypto(), keys, []byte(`{"data":{"me":"Mat"}}`))
+		is.NoErr(err)
+		w.Header().Set("Content-Type", "application/jose")
+		w.Write(out)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithBodyEncryption(keys), UseGET(), WithRequestCompression(0))
+	var resp struct{ Me string }
+	is.NoErr(client.Run(ctx, NewRequest(`{ me }`), &resp))
+	is.Equal(resp.Me, "Mat")
+
+	req := NewRequest(`mutation ($f: Upload!) { upload(file: $f) }`)
+	req.File("f", "a.txt", nil)
+	err := NewClient(srv.URL, WithBodyEncryption(keys), UseMultipartRequestSpec()).Run(ctx, req, nil)
+	is.True(errors.Is(err, ErrEncryption))
+}
+
+func TestJWERSA(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	is.NoErr(err)
+	keys := rsaKeys{priv}
+	jwe, err := encryptJWE(ctx, DefaultCrypto(), keys, []byte("secret"))
+	is.NoErr(err)
+	plaintext, err := decryptJWE(ctx, DefaultCrypto(), keys, jwe)
+	is.NoErr(err)
+	is.Equal(string(plaintext), "secret")
+
+	jwe[len(jwe)-3] ^= 1
+	_, err = decryptJWE(ctx, DefaultCrypto(), keys, jwe)
+	is.True(errors.Is(err, ErrEncryption))
+}
+
+type rsaKeys struct {
+	priv *rsa.PrivateKey
+}
+
+func (k rsaKeys) EncryptionKey(ctx context.Context) (EncryptionKey, error) {
+	return EncryptionKey{ID: "rsa", Key: &k.priv.PublicKey}, nil
+}
+
+func (k rsaKeys) DecryptionKey(ctx context.Context, id string) (EncryptionKey, error) {
+	return EncryptionKey{ID: "rsa", Key: k.priv}, nil
+}
@@ -0,0 +1,115 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrEndpointParamMissing the endpoint has a placeholder for which no value
+// was found in the context or the request variables.
+var ErrEndpointParamMissing = errors.New("endpoint parameter missing")
+
+var endpointParamRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+type endpointParamsKey struct{}
+
+// ContextWithEndpointParams returns a copy of ctx carrying values for the
+// placeholders of templated endpoints. Values already in ctx are kept
+// unless params replaces them.
+//
+//	client := gographql.NewClient("https://{region}.api.example.com/graphql")
+//	ctx = gographql.ContextWithEndpointParams(ctx, map[string]string{"region": "eu"})
+func ContextWithEndpointParams(ctx context.Context, params map[string]string) context.Context {
+	merged := make(map[string]string)
+	if parent, ok := ctx.Value(endpointParamsKey{}).(map[string]string); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, endpointParamsKey{}, merged)
+}
+
+// WithOperationEndpoint sends the operations of type operation, "query",
+// "mutation" or "subscription", to endpoint instead of the client
+// endpoint, for servers exposing a path per operation type. endpoint is
+// either an absolute URL or an absolute path replacing the path of the
+// client endpoint. It may have placeholders like the client endpoint.
+// Subscriptions switch the URL to the ws or wss scheme, and
+// WithSubscriptionEndpoint still takes precedence.
+//
+//	client := gographql.NewClient("https://api.example.com/graphql",
+//	    gographql.WithOperationEndpoint("mutation", "/graphql/write"),
+//	    gographql.WithOperationEndpoint("subscription", "/graphql/ws"))
+func WithOperationEndpoint(operation, endpoint string) ClientOption {
+	return func(client *Client) {
+		switch operation {
+		case "query", "mutation", "subscription":
+		default:
+			client.invalidOption("WithOperationEndpoint: unknown operation type %q", operation)
+			return
+		}
+		if client.opEndpoints == nil {
+			client.opEndpoints = make(map[string]string)
+		}
+		client.opEndpoints[operation] = endpoint
+	}
+}
+
+// endpointFor returns the unresolved endpoint of req, which may be nil.
+func (c *Client) endpointFor(req *Request) string {
+	if req == nil || len(c.opEndpoints) == 0 {
+		return c.Endpoint
+	}
+	kind, _ := operationInfo(req.q)
+	override, ok := c.opEndpoints[kind]
+	if !ok {
+		return c.Endpoint
+	}
+	if !strings.HasPrefix(override, "/") {
+		return override
+	}
+	base := c.Endpoint
+	if i := strings.Index(base, "://"); i >= 0 {
+		if j := strings.IndexAny(base[i+3:], "/?#"); j >= 0 {
+			base = base[:i+3+j]
+		}
+	}
+	return base + override
+}
+
+// endpoint resolves the placeholders of the endpoint of req, such as
+// {region} in https://{region}.api.example.com/graphql, from the context
+// and then from the variables of req, which may be nil. Values are path
+// escaped.
+func (c *Client) endpoint(ctx context.Context, req *Request) (string, error) {
+	endpoint := c.endpointFor(req)
+	if !strings.Contains(endpoint, "{") {
+		return endpoint, nil
+	}
+	params, _ := ctx.Value(endpointParamsKey{}).(map[string]string)
+	var missing []string
+	out := endpointParamRe.ReplaceAllStringFunc(endpoint, func(m string) string {
+		name := m[1 : len(m)-1]
+		if value, ok := params[name]; ok {
+			return url.PathEscape(value)
+		}
+		if req != nil {
+			if value, ok := req.vars[name]; ok && value != nil {
+				return url.PathEscape(fmt.Sprint(value))
+			}
+		}
+		missing = append(missing, name)
+		return m
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrEndpointParamMissing, strings.Join(missing, ", "))
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client, err := NewClientE(srv.URL + "/{region}/graphql/{tenant}")
+	is.NoErr(err)
+	req := NewRequest("query ($tenant: Int) { a }")
+	req.Var("tenant", 7)
+	is.NoErr(client.Run(ContextWithEndpointParams(ctx, map[string]string{"region": "eu west"}), req, nil))
+	is.Equal(paths, []string{"/eu west/graphql/7"})
+
+	err = client.Run(ctx, req, nil)
+	is.True(errors.Is(err, ErrEndpointParamMissing))
+	is.Equal(err.Error(), "endpoint parameter missing: region")
+}
+
+func TestOperationEndpoint(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client, err := NewClientE(srv.URL+"/graphql",
+		WithOperationEndpoint("mutation", "/graphql/{tenant}/write"),
+		WithOperationEndpoint("subscription", "/graphql/ws"))
+	is.NoErr(err)
+	is.NoErr(client.Run(ctx, NewRequest(`{ me }`), nil))
+	mutation := NewRequest(`mutation Save { save }`)
+	mutation.Var("tenant", "acme")
+	is.NoErr(client.Run(ctx, mutation, nil))
+	is.Equal(paths, []string{"/graphql", "/graphql/acme/write"})
+
+	wsURL, err := client.subscriptionURL(ctx, NewRequest(`subscription { ticks }`))
+	is.NoErr(err)
+	is.Equal(wsURL, "ws://"+strings.TrimPrefix(srv.URL, "http://")+"/graphql/ws")
+
+	_, err = NewClientE(srv.URL, WithOperationEndpoint("fragment", "/x"))
+	is.True(errors.Is(err, ErrInvalidOption))
+	_, err = NewClientE(srv.URL, WithOperationEndpoint("query", "graphql/read"))
+	is.True(errors.Is(err, ErrInvalidOption))
+}
@@ -0,0 +1,94 @@
+package gographql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorGroup is a set of GraphQL errors sharing a message and code, such
+// as the errors of a list field failing for every item.
+type ErrorGroup struct {
+	Message string
+	// Code is the extensions.code of the errors, if any.
+	Code string
+	// Errors are the errors of the group, in response order.
+	Errors GraphQLErrors
+}
+
+// Count returns the number of errors in the group.
+func (g ErrorGroup) Count() int {
+	return len(g.Errors)
+}
+
+// Groups groups the errors by message and code, in order of first
+// appearance.
+func (e GraphQLErrors) Groups() []ErrorGroup {
+	var groups []ErrorGroup
+	index := map[[2]string]int{}
+	for _, err := range e {
+		code, _ := err.Extensions["code"].(string)
+		key := [2]string{err.Message, code}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ErrorGroup{Message: err.Message, Code: code})
+		}
+		groups[i].Errors = append(groups[i].Errors, err)
+	}
+	return groups
+}
+
+// GroupedErrors is returned instead of GraphQLErrors by clients created
+// with WithErrorGrouping. Its message lists every distinct error once with
+// its count; the errors themselves are available with errors.As.
+type GroupedErrors struct {
+	Groups []ErrorGroup
+	// Errors are the response errors, without exact duplicates.
+	Errors GraphQLErrors
+}
+
+func (e *GroupedErrors) Error() string {
+	messages := make([]string, 0, len(e.Groups))
+	for _, g := range e.Groups {
+		msg := g.Message
+		if g.Code != "" {
+			msg += " [" + g.Code + "]"
+		}
+		if g.Count() > 1 {
+			msg += fmt.Sprintf(" (x%d)", g.Count())
+		}
+		messages = append(messages, msg)
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+func (e *GroupedErrors) Unwrap() error {
+	return e.Errors
+}
+
+// WithErrorGrouping removes identical errors from responses and groups the
+// remaining ones by message and code, so that a field failing for hundreds
+// of list items reads as one error with a count. Run then returns a
+// *GroupedErrors wrapping the GraphQLErrors.
+func WithErrorGrouping() ClientOption {
+	return func(client *Client) {
+		client.groupErrors = true
+	}
+}
+
+// groupErrors removes the exact duplicates from errs and groups them.
+func groupErrors(errs GraphQLErrors) *GroupedErrors {
+	seen := make(map[string]bool, len(errs))
+	unique := make(GraphQLErrors, 0, len(errs))
+	for _, err := range errs {
+		key, _ := json.Marshal(err)
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		unique = append(unique, err)
+	}
+	return &GroupedErrors{Groups: unique.Groups(), Errors: unique}
+}
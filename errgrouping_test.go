@@ -0,0 +1,44 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestErrorGrouping(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":null,"errors":[
+			{"message":"not allowed","path":["items",0,"price"],"extensions":{"code":"FORBIDDEN"}},
+			{"message":"not allowed","path":["items",1,"price"],"extensions":{"code":"FORBIDDEN"}},
+			{"message":"not allowed","path":["items",1,"price"],"extensions":{"code":"FORBIDDEN"}},
+			{"message":"timeout","path":["stock"]},
+			{"message":"not allowed","path":["items",2,"price"],"extensions":{"code":"FORBIDDEN"}}
+		]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithErrorGrouping())
+	err := client.Run(ctx, NewRequest("query { items { price } stock }"), nil)
+	is.Equal(err.Error(), "graphql: not allowed [FORBIDDEN] (x3); timeout")
+
+	var grouped *GroupedErrors
+	is.True(errors.As(err, &grouped))
+	is.Equal(len(grouped.Groups), 2)
+	is.Equal(grouped.Groups[0].Count(), 3)
+	is.Equal(grouped.Groups[0].Code, "FORBIDDEN")
+	is.Equal(grouped.Groups[1].Message, "timeout")
+
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+	is.Equal(len(gqlErrs), 4) // the exact duplicate was dropped
+}
@@ -0,0 +1,140 @@
+package gographql
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the type of a client Event.
+type EventType int
+
+const (
+	// EventConnectionOpened is sent when a subscription connection was
+	// established.
+	EventConnectionOpened EventType = iota
+	// EventConnectionClosed is sent when a subscription connection was
+	// closed, with the error that dropped it, if any.
+	EventConnectionClosed
+	// EventReconnecting is sent before every attempt to re-establish a
+	// dropped subscription connection, see WithSubscriptionReconnect.
+	EventReconnecting
+	// EventReconnected is sent once the subscriptions were replayed on a
+	// new connection.
+	EventReconnected
+	// EventRetry is sent when a request is sent again: with the query
+	// after an unknown persisted query, uncompressed after the server
+	// rejected a compressed body, or over the HTTP client after an HTTP/3
+	// failure.
+	EventRetry
+	// EventCacheEvict is sent when results or entities were evicted from
+	// the client cache.
+	EventCacheEvict
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventConnectionOpened:
+		return "connection opened"
+	case EventConnectionClosed:
+		return "connection closed"
+	case EventReconnecting:
+		return "reconnecting"
+	case EventReconnected:
+		return "reconnected"
+	case EventRetry:
+		return "retry"
+	case EventCacheEvict:
+		return "cache evict"
+	}
+	return "unknown"
+}
+
+// Event is a notification of the client lifecycle, delivered to the
+// listeners registered with Client.Listen.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// URL is the subscription connection URL of connection events.
+	URL string
+	// Attempt counts the reconnection attempts from one.
+	Attempt int
+	// Err is why a connection closed, a reconnection attempt failed or a
+	// request was retried.
+	Err error
+	// Keys are the evicted cache keys.
+	Keys []string
+}
+
+// eventBus delivers events to listeners. It is shared by clients made
+// with Client.With.
+type eventBus struct {
+	mu        sync.RWMutex
+	listeners map[int]func(Event)
+	nextID    int
+	// cache is the cache whose evictions are forwarded.
+	cache *Cache
+}
+
+// Listen registers fn to be called for every lifecycle event of the
+// client, such as subscription connections dropping and reconnecting, to
+// show a "reconnecting" state without polling. fn is called synchronously
+// from the goroutine where the event happened, so it must not block. The
+// returned function removes the listener. Clients made with With share
+// listeners.
+//
+//	cancel := client.Listen(func(e gographql.Event) {
+//	    if e.Type == gographql.EventReconnecting {
+//	        status.Set("reconnecting")
+//	    }
+//	})
+func (c *Client) Listen(fn func(Event)) (cancel func()) {
+	b := c.events
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.listeners[id] = fn
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		delete(b.listeners, id)
+		b.mu.Unlock()
+	}
+}
+
+// emit delivers e to the listeners.
+func (c *Client) emit(e Event) {
+	b := c.events
+	b.mu.RLock()
+	if len(b.listeners) == 0 {
+		b.mu.RUnlock()
+		return
+	}
+	listeners := make([]func(Event), 0, len(b.listeners))
+	for _, fn := range b.listeners {
+		listeners = append(listeners, fn)
+	}
+	b.mu.RUnlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, fn := range listeners {
+		fn(e)
+	}
+}
+
+// forwardCacheEvictions emits the evictions of the client cache, once per
+// cache.
+func (c *Client) forwardCacheEvictions() {
+	b := c.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.cache == nil || b.cache == c.cache {
+		return
+	}
+	b.cache = c.cache
+	c.cache.Listen(func(e CacheEvent) {
+		if e.Type == CacheEvict {
+			c.emit(Event{Type: EventCacheEvict, Keys: e.Keys})
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package gographql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestListen(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, _ := readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"complete","id":"`+msg.ID+`"}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache(WithCacheMaxResults(1))
+	client := NewClient(srv.URL, WithCache(cache))
+	var mu sync.Mutex
+	var events []Event
+	stop := client.Listen(func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+	payloads, _, err := client.Subscribe(ctx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	for range payloads {
+	}
+	closed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 2
+	}
+	for !closed() && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	// With shares listeners
+	is.NoErr(cache.Write("a", map[string]interface{}{"a": 1}))
+	is.NoErr(client.With().cache.Write("b", map[string]interface{}{"b": 1}))
+	stop()
+	is.NoErr(cache.Write("c", map[string]interface{}{"c": 1}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var types []EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	is.Equal(types, []EventType{EventConnectionOpened, EventConnectionClosed, EventCacheEvict})
+	is.Equal(events[0].URL, "ws"+srv.URL[len("http"):])
+	is.NoErr(events[1].Err)
+	is.Equal(events[2].Keys, []string{"a"})
+	is.Equal(events[2].Type.String(), "cache evict")
+}
@@ -0,0 +1,123 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrGETMutation a mutation was sent with GET.
+var ErrGETMutation = errors.New("mutations cannot be sent with GET")
+
+// maxGETURL is the length of the longest URL sent with GET. Longer
+// requests are sent with POST, as many servers and proxies reject longer
+// URLs.
+const maxGETURL = 8 << 10
+
+// UseGET sends queries as GET requests with the query, variables,
+// operationName and extensions URL parameters of the GraphQL over HTTP
+// specification, so they can be cached by CDNs and proxies. Mutations,
+// requests with files and requests whose URL would exceed 8KiB are sent
+// with POST.
+func UseGET() ClientOption {
+	return func(client *Client) {
+		client.useGET = true
+	}
+}
+
+// SetHTTPMethod overrides the HTTP method used to send the request, GET
+// or POST. An empty method uses the client's default. Sending a mutation
+// with GET fails with ErrGETMutation.
+func (req *Request) SetHTTPMethod(method string) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.method = strings.ToUpper(method)
+}
+
+// usesGET reports whether req is sent with GET.
+func (c *Client) usesGET(req *Request) bool {
+	if c.encryption != nil {
+		// the request would be in the URL, unencrypted
+		return false
+	}
+	if req.method == "" && c.useGET {
+		if c.lacks(func(caps *Capabilities) bool { return caps.GET }) {
+			return false
+		}
+		kind, _ := operationInfo(req.q)
+		return kind == "query"
+	}
+	return req.method == http.MethodGet
+}
+
+// postJSON sends the JSON encoded GraphQL request body, as URL parameters
+// when req should be sent with GET.
+func (c *Client) postJSON(ctx context.Context, req *Request, body *bodyBuffer, resp interface{}, meta *responseMeta) error {
+	if !c.usesGET(req) || !c.useTransport(ctx, req, transportGET) {
+		return c.post(ctx, req, body, "application/json; charset=utf-8", resp, meta)
+	}
+	if kind, _ := operationInfo(req.q); kind == "mutation" {
+		return ErrGETMutation
+	}
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+	u, err := getURL(endpoint, req, body.Bytes())
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	if len(u) > maxGETURL && req.method == "" {
+		return c.post(ctx, req, body, "application/json; charset=utf-8", resp, meta)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", acceptGraphQLResponse)
+	c.setHeaders(ctx, r, req)
+	if meta == nil {
+		meta = &responseMeta{}
+	}
+	err = c.doHTTP(ctx, req, r, resp, meta)
+	if c.fallBack(ctx, req, transportGET, meta.statusCode, err) {
+		return c.post(ctx, req, body, "application/json; charset=utf-8", resp, meta)
+	}
+	if meta.statusCode != 0 {
+		c.transportWorked(ctx, req, transportGET)
+	}
+	return err
+}
+
+// getURL encodes the fields of the JSON request body as URL parameters
+// of endpoint: strings as they are, other values as JSON.
+func getURL(endpoint string, req *Request, body []byte) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for key, value := range fields {
+		if string(value) == "null" {
+			continue
+		}
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			q.Set(key, s)
+			continue
+		}
+		q.Set(key, string(value))
+	}
+	if _, name := operationInfo(req.q); name != "" {
+		q.Set("operationName", name)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,86 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestUseGET(t *testing.T) {
+	is := is.New(t)
+	var method string
+	var params url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		params = r.URL.Query()
+		io.WriteString(w, `{"data":{"a":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL+"?key=1", UseGET())
+	req := NewRequest(`query A($id: ID) { a(id: $id) }`)
+	req.Var("id", "x")
+	var resp struct{ A string }
+	is.NoErr(client.Run(ctx, req, &resp))
+	is.Equal(resp.A, "ok")
+	is.Equal(method, http.MethodGet)
+	is.Equal(params, url.Values{
+		"key":           {"1"},
+		"query":         {`query A($id: ID) { a(id: $id) }`},
+		"variables":     {`{"id":"x"}`},
+		"operationName": {"A"},
+	})
+
+	is.NoErr(client.Run(ctx, NewRequest(`mutation { a }`), nil))
+	is.Equal(method, http.MethodPost)
+
+	long := NewRequest(`{ a }`)
+	long.Var("big", strings.Repeat("x", maxGETURL))
+	is.NoErr(client.Run(ctx, long, nil))
+	is.Equal(method, http.MethodPost)
+
+	req = NewRequest(`{ a }`)
+	req.SetHTTPMethod("post")
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(method, http.MethodPost)
+
+	client = NewClient(srv.URL)
+	req = NewRequest(`{ a }`)
+	req.SetHTTPMethod(http.MethodGet)
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(method, http.MethodGet)
+	is.Equal(params.Get("variables"), "")
+
+	req = NewRequest(`mutation { a }`)
+	req.SetHTTPMethod(http.MethodGet)
+	is.True(errors.Is(client.Run(ctx, req, nil), ErrGETMutation))
+}
+
+func TestUseGETWithPersistedQueries(t *testing.T) {
+	is := is.New(t)
+	var params url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, http.MethodGet)
+		params = r.URL.Query()
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseGET(), WithAutomaticPersistedQueries())
+	is.NoErr(client.Run(ctx, NewRequest(`query A { a }`), nil))
+	is.Equal(params.Get("query"), "")
+	is.Equal(params.Get("operationName"), "A")
+	is.True(strings.Contains(params.Get("extensions"), `"sha256Hash"`))
+}
@@ -0,0 +1,103 @@
+package gographql
+
+import (
+	"context"
+	"sync"
+)
+
+// RunGroup runs several operations concurrently with shared cancellation,
+// in the style of errgroup: the first failure cancels the others and is
+// returned by Wait. Make one with Group.
+type RunGroup struct {
+	client *Client
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// Group returns a RunGroup running operations with client. Operations
+// use a context derived from ctx that is cancelled when one of them
+// fails or when Wait returns.
+//
+//	g := gographql.Group(ctx, client)
+//	g.Go(userReq, &user)
+//	g.Go(ordersReq, &orders)
+//	if err := g.Wait(); err != nil {
+//	    return err
+//	}
+func Group(ctx context.Context, client *Client) *RunGroup {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &RunGroup{client: client, ctx: ctx, cancel: cancel}
+}
+
+// SetLimit limits the number of operations in flight to n. A negative
+// value removes the limit. It must not be called while operations are
+// running.
+func (g *RunGroup) SetLimit(n int) *RunGroup {
+	if n < 0 {
+		g.sem = nil
+		return g
+	}
+	g.sem = make(chan struct{}, n)
+	return g
+}
+
+// Context returns the context shared by the group's operations, so
+// related work can be cancelled with them.
+func (g *RunGroup) Context() context.Context {
+	return g.ctx
+}
+
+// Go runs req in a new goroutine, decoding the data into resp. It blocks
+// while the limit set by SetLimit is reached. Operations are not started
+// once the group is cancelled.
+func (g *RunGroup) Go(req *Request, resp interface{}) {
+	g.GoFunc(func(ctx context.Context) error {
+		return g.client.Run(ctx, req, resp)
+	})
+}
+
+// GoFunc runs fn in a new goroutine like Go, for dependent calls that
+// need the results of earlier ones or several requests in sequence.
+func (g *RunGroup) GoFunc(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.fail(context.Cause(g.ctx))
+			return
+		}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		if err := g.ctx.Err(); err != nil {
+			g.fail(context.Cause(g.ctx))
+			return
+		}
+		if err := fn(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait blocks until all operations have returned, then returns the first
+// error, if any.
+func (g *RunGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
+
+func (g *RunGroup) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
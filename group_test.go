@@ -0,0 +1,67 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestGroup(t *testing.T) {
+	is := is.New(t)
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		var body struct{ Variables map[string]int }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Variables["n"] == 3 {
+			io.WriteString(w, `{"errors":[{"message":"boom"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"n":1}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	g := Group(ctx, client).SetLimit(2)
+	results := make([]struct{ N int }, 3)
+	for i := range results {
+		req := NewRequest("query ($n: Int) { n }")
+		req.Var("n", i)
+		g.Go(req, &results[i])
+	}
+	is.NoErr(g.Wait())
+	is.Equal(results[2].N, 1)
+	is.True(maxInFlight <= 2)
+	is.True(g.Context().Err() != nil) // cancelled after Wait
+
+	g = Group(ctx, client)
+	bad := NewRequest("query ($n: Int) { n }")
+	bad.Var("n", 3)
+	g.Go(bad, nil)
+	var cancelled error
+	g.GoFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		cancelled = ctx.Err()
+		return nil
+	})
+	err := g.Wait()
+	is.Equal(err.Error(), "graphql: boom")
+	is.Equal(cancelled, context.Canceled)
+}
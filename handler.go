@@ -0,0 +1,153 @@
+package gographql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// defaultHandlerMaxBody is the default size limit of the request bodies
+// accepted by Handler.
+const defaultHandlerMaxBody = 1 << 20
+
+// HandlerOption configures Handler.
+type HandlerOption func(*handler)
+
+// MaxRequestBody limits the request bodies accepted by Handler to n bytes,
+// 1 MiB by default. Larger requests fail with 413 Request Entity Too
+// Large.
+func MaxRequestBody(n int64) HandlerOption {
+	return func(h *handler) {
+		h.maxBody = n
+	}
+}
+
+// ForwardHeaders passes the named headers of incoming requests on to the
+// upstream server, in addition to the headers set by the client.
+func ForwardHeaders(names ...string) HandlerOption {
+	return func(h *handler) {
+		h.forward = append(h.forward, names...)
+	}
+}
+
+// Handler returns an http.Handler serving GraphQL over HTTP by running
+// the incoming operations with client, so they go through its
+// authentication, retries and caching. It accepts POST requests with a
+// JSON body, and GET requests with query, variables and operationName
+// parameters for queries. When operationName is set, only that operation
+// of the document, with the fragments it uses, is sent. Responses from
+// the upstream server are written as they are, GraphQL errors included;
+// other failures are reported as a 502 with a GraphQL error.
+//
+//	http.Handle("/graphql", gographql.Handler(client, gographql.ForwardHeaders("Accept-Language")))
+func Handler(client *Client, opts ...HandlerOption) http.Handler {
+	h := &handler{client: client, maxBody: defaultHandlerMaxBody}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type handler struct {
+	client  *Client
+	forward []string
+	maxBody int64
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables"`
+		OperationName string                 `json:"operationName"`
+	}
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBody)).Decode(&body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeHandlerError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			writeHandlerError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+	case http.MethodGet:
+		q := r.URL.Query()
+		body.Query = q.Get("query")
+		body.OperationName = q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &body.Variables); err != nil {
+				writeHandlerError(w, http.StatusBadRequest, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeHandlerError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if body.Query == "" {
+		writeHandlerError(w, http.StatusBadRequest, "missing query")
+		return
+	}
+	if body.OperationName != "" {
+		query, err := selectOperation(body.Query, body.OperationName)
+		if err != nil {
+			writeHandlerError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		body.Query = query
+	}
+	if kind, _ := operationInfo(body.Query); r.Method == http.MethodGet && kind != "query" {
+		w.Header().Set("Allow", http.MethodPost)
+		writeHandlerError(w, http.StatusMethodNotAllowed, "only queries may be sent with GET")
+		return
+	}
+
+	req := NewRequest(body.Query)
+	for key, value := range body.Variables {
+		req.Var(key, value)
+	}
+	for _, name := range h.forward {
+		for _, value := range r.Header.Values(name) {
+			req.AddHeader(name, value)
+		}
+	}
+	resp, err := h.client.RunRaw(r.Context(), req)
+	var gqlErrs GraphQLErrors
+	if err != nil && (len(resp) == 0 || !errors.As(err, &gqlErrs)) {
+		writeHandlerError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(resp)
+}
+
+// selectOperation returns the document made of the operation called name
+// in query and the fragments it uses.
+func selectOperation(query, name string) (string, error) {
+	doc, err := ParseDocument(query)
+	if err != nil {
+		return "", err
+	}
+	op := doc.Operation(name)
+	if op == nil {
+		return "", errors.New("unknown operation " + name)
+	}
+	var fragments []*Definition
+	for _, def := range doc.Definitions {
+		if def.Kind == "fragment" {
+			fragments = append(fragments, def)
+		}
+	}
+	selected := &Document{Definitions: append([]*Definition{op}, usedFragments(op, fragments)...)}
+	return selected.String(), nil
+}
+
+func writeHandlerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
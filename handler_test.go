@@ -0,0 +1,104 @@
+package gographql
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestHandler(t *testing.T) {
+	is := is.New(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer client")
+		is.Equal(r.Header.Get("Accept-Language"), "fr")
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		if body.Variables["id"] == "missing" {
+			io.WriteString(w, `{"data":{"user":null},"errors":[{"message":"not found"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"user":{"id":"`+body.Variables["id"].(string)+`"}}}`)
+	}))
+	defer upstream.Close()
+	client := NewClient(upstream.URL, WithDefaultHeaders(http.Header{"Authorization": {"Bearer client"}}))
+	srv := httptest.NewServer(Handler(client, ForwardHeaders("Accept-Language")))
+	defer srv.Close()
+
+	do := func(method, target, body string) (int, string) {
+		r, err := http.NewRequest(method, target, strings.NewReader(body))
+		is.NoErr(err)
+		r.Header.Set("Accept-Language", "fr")
+		res, err := http.DefaultClient.Do(r)
+		is.NoErr(err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		is.NoErr(err)
+		return res.StatusCode, string(b)
+	}
+	const query = `query ($id: ID!) { user(id: $id) { id } }`
+	status, body := do(http.MethodPost, srv.URL, `{"query":"`+query+`","variables":{"id":"1"}}`)
+	is.Equal(status, http.StatusOK)
+	is.Equal(body, `{"data":{"user":{"id":"1"}}}`)
+
+	status, body = do(http.MethodGet, srv.URL+"?"+url.Values{"query": {query}, "variables": {`{"id":"missing"}`}}.Encode(), "")
+	is.Equal(status, http.StatusOK)
+	is.Equal(body, `{"data":{"user":null},"errors":[{"message":"not found"}]}`)
+
+	status, _ = do(http.MethodGet, srv.URL+"?"+url.Values{"query": {"mutation { a }"}}.Encode(), "")
+	is.Equal(status, http.StatusMethodNotAllowed)
+	status, _ = do(http.MethodPost, srv.URL, `{`)
+	is.Equal(status, http.StatusBadRequest)
+
+	upstream.Close()
+	status, body = do(http.MethodPost, srv.URL, `{"query":"{ a }"}`)
+	is.Equal(status, http.StatusBadGateway)
+	is.True(strings.HasPrefix(body, `{"errors":[{"message":`))
+}
+
+func TestHandlerOperationName(t *testing.T) {
+	is := is.New(t)
+	var sent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Query string }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		sent = body.Query
+		io.WriteString(w, `{"data":{"b":1}}`)
+	}))
+	defer upstream.Close()
+	srv := httptest.NewServer(Handler(NewClient(upstream.URL), MaxRequestBody(512)))
+	defer srv.Close()
+
+	const doc = `query A { a } query B { b ...F } mutation C { c } fragment F on Query { f } fragment G on Query { g }`
+	res, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"query":"`+doc+`","operationName":"B"}`))
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.True(strings.HasPrefix(sent, "query B"))
+	is.True(strings.Contains(sent, "fragment F"))
+	is.True(!strings.Contains(sent, "query A"))
+	is.True(!strings.Contains(sent, "fragment G"))
+
+	res, err = http.Get(srv.URL + "?" + url.Values{"query": {doc}, "operationName": {"C"}}.Encode())
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusMethodNotAllowed) // the mutation cannot be sent with GET
+
+	res, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"query":"`+doc+`","operationName":"D"}`))
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusBadRequest)
+
+	res, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"query":"{ a }","variables":{"s":"`+strings.Repeat("x", 512)+`"}}`))
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusRequestEntityTooLarge)
+}
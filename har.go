@@ -0,0 +1,331 @@
+package gographql
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HARRecorder records the HTTP traffic of a Client as an HTTP Archive
+// (HAR 1.2) log, suitable for sharing with API vendors or loading into
+// browser devtools.
+//
+//	rec := gographql.NewHARRecorder()
+//	client := gographql.NewClient(endpoint, gographql.WithHARRecorder(rec))
+//	// ... run requests ...
+//	rec.WriteTo(f)
+//
+// A HARRecorder is safe for concurrent use and may be shared by several clients.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder makes a new, empty HARRecorder.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// WithHARRecorder records every HTTP exchange made by the client into rec.
+// Credential headers, such as Authorization and cookies, are recorded as
+// "[REDACTED]", and only the size of bodies larger than 64 KiB is
+// recorded.
+func WithHARRecorder(rec *HARRecorder) ClientOption {
+	return func(client *Client) {
+		client.har = rec
+	}
+}
+
+// Len returns the number of recorded entries.
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset discards all recorded entries.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// WriteTo writes the recorded entries to w as a HAR document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	doc := harDocument{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "gographql", Version: "1"},
+			Entries: append([]harEntry{}, r.entries...),
+		},
+	}
+	r.mu.Unlock()
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (r *HARRecorder) add(e harEntry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+}
+
+// maxHARBody is the size of the largest body whose content is recorded;
+// only the size of larger bodies is.
+const maxHARBody = 64 << 10
+
+// harClient is an HTTPClient that records exchanges made through next.
+// Bodies are recorded as they are read, so streamed uploads and responses
+// are not held in memory, and an exchange is recorded once its response
+// body is closed.
+type harClient struct {
+	next HTTPClient
+	rec  *HARRecorder
+}
+
+func (h *harClient) Do(r *http.Request) (*http.Response, error) {
+	var reqBody *harBody
+	if r.Body != nil && r.Body != http.NoBody {
+		reqBody = &harBody{buf: cappedBuffer{limit: maxHARBody}}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(r.Body, reqBody), r.Body}
+	}
+	started := time.Now()
+	res, err := h.next.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Since(started)
+	resBody := &harBody{buf: cappedBuffer{limit: maxHARBody}}
+	res.Body = &harResponseBody{
+		ReadCloser: res.Body,
+		tee:        io.TeeReader(res.Body, resBody),
+		done: func() {
+			h.rec.add(harRecord(r, reqBody, res, resBody, started, wait))
+		},
+	}
+	return res, nil
+}
+
+// harRecord returns the entry of an exchange whose response body was
+// read.
+func harRecord(r *http.Request, reqBody *harBody, res *http.Response, resBody *harBody, started time.Time, wait time.Duration) harEntry {
+	receive := time.Since(started) - wait
+	text, size := resBody.content()
+	entry := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            millis(wait + receive),
+		Request: harRequest{
+			Method:      r.Method,
+			URL:         r.URL.String(),
+			HTTPVersion: r.Proto,
+			Headers:     harHeaders(r.Header),
+			QueryString: harQueryString(r),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+		},
+		Response: harResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Headers:     harHeaders(res.Header),
+			Cookies:     []harNameValue{},
+			Content: harContent{
+				Size:     size,
+				MimeType: res.Header.Get("Content-Type"),
+				Text:     text,
+			},
+			HeadersSize: -1,
+			BodySize:    size,
+		},
+		Cache: struct{}{},
+		Timings: harTimings{
+			Send:    0,
+			Wait:    millis(wait),
+			Receive: millis(receive),
+		},
+	}
+	if size > maxHARBody {
+		entry.Response.Content.Comment = "content larger than 64 KiB not recorded"
+	}
+	if reqBody != nil {
+		text, size := reqBody.content()
+		entry.Request.BodySize = size
+		entry.Request.PostData = &harPostData{
+			MimeType: r.Header.Get("Content-Type"),
+			Text:     text,
+		}
+		if size > maxHARBody {
+			entry.Request.PostData.Comment = "content larger than 64 KiB not recorded"
+		}
+	}
+	return entry
+}
+
+// harBody records a body as it is read, keeping its content up to
+// maxHARBody bytes. It is written by the transport while the exchange is
+// recorded, hence the lock.
+type harBody struct {
+	mu   sync.Mutex
+	buf  cappedBuffer
+	size int
+}
+
+func (b *harBody) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size += len(p)
+	return b.buf.Write(p)
+}
+
+// content returns the recorded content, empty if the body was too large,
+// and the size of the body.
+func (b *harBody) content() (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String(), b.size
+}
+
+// harResponseBody records the exchange once the response body was read
+// to the end or closed.
+type harResponseBody struct {
+	io.ReadCloser
+	tee  io.Reader
+	once sync.Once
+	done func()
+}
+
+func (b *harResponseBody) Read(p []byte) (int, error) {
+	n, err := b.tee.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *harResponseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harRedactedHeaders are the headers whose values are never recorded, as
+// HAR files are meant to be shared.
+var harRedactedHeaders = append([]string{"Set-Cookie"}, redactedHeaders...)
+
+// harHeaders returns h, with the values of credential headers replaced by
+// "[REDACTED]".
+func harHeaders(h http.Header) []harNameValue {
+	out := make([]harNameValue, 0, len(h))
+	for name, values := range h {
+		redacted := false
+		for _, credential := range harRedactedHeaders {
+			if strings.EqualFold(name, credential) {
+				redacted = true
+			}
+		}
+		for _, value := range values {
+			if redacted {
+				value = "[REDACTED]"
+			}
+			out = append(out, harNameValue{Name: name, Value: value})
+		}
+	}
+	return out
+}
+
+func harQueryString(r *http.Request) []harNameValue {
+	out := make([]harNameValue, 0)
+	for name, values := range r.URL.Query() {
+		for _, value := range values {
+			out = append(out, harNameValue{Name: name, Value: value})
+		}
+	}
+	return out
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
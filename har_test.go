@@ -0,0 +1,131 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestHARRecorder(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(b), `{"query":"query {}","variables":null}`+"\n")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"something":"yes"}}`)
+	}))
+	defer srv.Close()
+
+	rec := NewHARRecorder()
+	client := NewClient(srv.URL, WithHARRecorder(rec))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	var responseData map[string]interface{}
+	err := client.Run(ctx, NewRequest("query {}"), &responseData)
+	is.NoErr(err)
+	is.Equal(responseData["something"], "yes")
+	is.Equal(rec.Len(), 1)
+
+	var buf bytes.Buffer
+	_, err = rec.WriteTo(&buf)
+	is.NoErr(err)
+	var doc struct {
+		Log struct {
+			Version string
+			Entries []struct {
+				Request struct {
+					Method   string
+					URL      string
+					PostData struct {
+						Text string
+					}
+				}
+				Response struct {
+					Status  int
+					Content struct {
+						MimeType string
+						Text     string
+					}
+				}
+			}
+		}
+	}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &doc))
+	is.Equal(doc.Log.Version, "1.2")
+	is.Equal(len(doc.Log.Entries), 1)
+	e := doc.Log.Entries[0]
+	is.Equal(e.Request.Method, http.MethodPost)
+	is.Equal(e.Request.URL, srv.URL)
+	is.Equal(e.Request.PostData.Text, `{"query":"query {}","variables":null}`+"\n")
+	is.Equal(e.Response.Status, http.StatusOK)
+	is.Equal(e.Response.Content.MimeType, "application/json")
+	is.Equal(e.Response.Content.Text, `{"data":{"something":"yes"}}`)
+
+	rec.Reset()
+	is.Equal(rec.Len(), 0)
+}
+
+func TestHARRecorderRedactsAndCaps(t *testing.T) {
+	is := is.New(t)
+	large := `{"data":{"blob":"` + strings.Repeat("x", maxHARBody) + `"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret"})
+		io.WriteString(w, large)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	rec := NewHARRecorder()
+	client := NewClient(srv.URL, WithHARRecorder(rec))
+	req := NewRequest("query { blob }")
+	req.SetHeader("Authorization", "Bearer s3cret")
+	var resp struct{ Blob string }
+	is.NoErr(client.Run(ctx, req, &resp))
+	is.Equal(len(resp.Blob), maxHARBody) // the response is read whole
+	is.Equal(rec.Len(), 1)
+
+	var buf bytes.Buffer
+	_, err := rec.WriteTo(&buf)
+	is.NoErr(err)
+	is.True(!strings.Contains(buf.String(), "s3cret"))
+	is.True(!strings.Contains(buf.String(), "xxxx"))
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					Headers []harNameValue
+				}
+				Response struct {
+					Headers []harNameValue
+					Content harContent
+				}
+			}
+		}
+	}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &doc))
+	e := doc.Log.Entries[0]
+	is.True(containsHeader(e.Request.Headers, "Authorization", "[REDACTED]"))
+	is.True(containsHeader(e.Response.Headers, "Set-Cookie", "[REDACTED]"))
+	is.Equal(e.Response.Content.Size, len(large))
+	is.Equal(e.Response.Content.Text, "")
+}
+
+func containsHeader(headers []harNameValue, name, value string) bool {
+	for _, h := range headers {
+		if h.Name == name && h.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package gographql
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// http3RetryAfter is how long HTTP/3 is skipped after a failure.
+const http3RetryAfter = time.Minute
+
+// WithHTTP3 sends requests with rt, an HTTP/3 round tripper such as the
+// http3.Transport of github.com/quic-go/quic-go, which this package does
+// not depend on. When a request fails over HTTP/3, for instance because
+// UDP is blocked, it is sent again with the HTTP client, usually over
+// HTTP/2, and HTTP/3 is skipped for a minute. Bodies are only resent when
+// they can be reopened, which is the case for every request made by the
+// client. Subscriptions keep using the HTTP client.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithHTTP3(&http3.Transport{}))
+func WithHTTP3(rt http.RoundTripper) ClientOption {
+	return func(client *Client) {
+		client.http3 = rt
+	}
+}
+
+// http3Client is an HTTPClient sending requests over HTTP/3, falling back
+// to next.
+type http3Client struct {
+	h3   *http.Client
+	next HTTPClient
+	// emit reports fallbacks.
+	emit func(Event)
+	// brokenUntil is the Unix time in nanoseconds until which HTTP/3 is
+	// skipped.
+	brokenUntil atomic.Int64
+}
+
+func newHTTP3Client(rt http.RoundTripper, next HTTPClient, emit func(Event)) *http3Client {
+	h3 := &http.Client{Transport: rt}
+	if hc, ok := next.(*http.Client); ok {
+		h3.CheckRedirect = hc.CheckRedirect
+		h3.Jar = hc.Jar
+		h3.Timeout = hc.Timeout
+	}
+	return &http3Client{h3: h3, next: next, emit: emit}
+}
+
+func (h *http3Client) Do(r *http.Request) (*http.Response, error) {
+	if time.Now().UnixNano() < h.brokenUntil.Load() {
+		return h.next.Do(r)
+	}
+	res, err := h.h3.Do(r)
+	if err == nil || r.Context().Err() != nil {
+		return res, err
+	}
+	h.brokenUntil.Store(time.Now().Add(http3RetryAfter).UnixNano())
+	h.emit(Event{Type: EventRetry, Err: err})
+	if r.Body != nil && r.Body != http.NoBody {
+		if r.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := r.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		r = r.Clone(r.Context())
+		r.Body = body
+	}
+	return h.next.Do(r)
+}
@@ -0,0 +1,49 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestHTTP3(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		is.Equal(string(b), `{"query":"{ me }","variables":null}`+"\n")
+		io.WriteString(w, `{"data":{"me":"Mat"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var h3Calls atomic.Int32
+	client := NewClient(srv.URL, WithHTTP3(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		h3Calls.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})))
+	var resp struct{ Me string }
+	is.NoErr(client.Run(ctx, NewRequest(`{ me }`), &resp))
+	is.Equal(resp.Me, "Mat")
+	is.Equal(h3Calls.Load(), int32(1))
+
+	// a failure over HTTP/3 falls back and skips HTTP/3 afterwards
+	h3Calls.Store(0)
+	client = NewClient(srv.URL, WithHTTP3(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		h3Calls.Add(1)
+		io.ReadAll(r.Body)
+		return nil, errors.New("no recent network activity")
+	})))
+	resp.Me = ""
+	is.NoErr(client.Run(ctx, NewRequest(`{ me }`), &resp))
+	is.Equal(resp.Me, "Mat")
+	is.NoErr(client.Run(ctx, NewRequest(`{ me }`), nil))
+	is.Equal(h3Calls.Load(), int32(1))
+}
@@ -0,0 +1,130 @@
+package gographql
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// IDGenerator generates client-side identifiers, typically used as
+// placeholder IDs for optimistic mutations until the server assigns
+// the real ones.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts an ordinary function to the IDGenerator interface.
+type IDGeneratorFunc func() string
+
+// NewID calls f.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// UUIDv7 returns an IDGenerator producing time-ordered RFC 9562 version 7 UUIDs.
+func UUIDv7() IDGenerator {
+	return IDGeneratorFunc(func() string {
+		var b [16]byte
+		putTimestamp(b[:6])
+		rand.Read(b[6:])
+		b[6] = (b[6] & 0x0f) | 0x70
+		b[8] = (b[8] & 0x3f) | 0x80
+		var out [36]byte
+		hex.Encode(out[0:8], b[0:4])
+		out[8] = '-'
+		hex.Encode(out[9:13], b[4:6])
+		out[13] = '-'
+		hex.Encode(out[14:18], b[6:8])
+		out[18] = '-'
+		hex.Encode(out[19:23], b[8:10])
+		out[23] = '-'
+		hex.Encode(out[24:], b[10:])
+		return string(out[:])
+	})
+}
+
+// ULID returns an IDGenerator producing lexicographically sortable ULIDs.
+func ULID() IDGenerator {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	return IDGeneratorFunc(func() string {
+		var b [16]byte
+		putTimestamp(b[:6])
+		rand.Read(b[6:])
+		hi := binary.BigEndian.Uint64(b[:8])
+		lo := binary.BigEndian.Uint64(b[8:])
+		var out [26]byte
+		for i := 25; i >= 0; i-- {
+			out[i] = alphabet[lo&0x1f]
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+		return string(out[:])
+	})
+}
+
+// putTimestamp writes the current unix time in milliseconds as a 48 bit
+// big endian integer into b.
+func putTimestamp(b []byte) {
+	ms := uint64(time.Now().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// WithIDGenerator sets the generator used by Client.NewOptimisticID.
+// UUIDv7 is used by default.
+func WithIDGenerator(gen IDGenerator) ClientOption {
+	return func(client *Client) {
+		client.idGenerator = gen
+	}
+}
+
+// NewOptimisticID returns a new client-side ID from the client's IDGenerator.
+func (c *Client) NewOptimisticID() string {
+	return c.idGenerator.NewID()
+}
+
+// IDs returns the map of optimistic client-side IDs to the IDs
+// later returned by the server.
+func (c *Client) IDs() *IDMap {
+	return c.ids
+}
+
+// IDMap maps optimistic client-side IDs to the IDs assigned by the server.
+// It is safe for concurrent use.
+type IDMap struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// NewIDMap makes a new, empty IDMap.
+func NewIDMap() *IDMap {
+	return &IDMap{m: make(map[string]string)}
+}
+
+// Set records that clientID was assigned serverID by the server.
+func (m *IDMap) Set(clientID, serverID string) {
+	m.mu.Lock()
+	m.m[clientID] = serverID
+	m.mu.Unlock()
+}
+
+// Resolve returns the server ID for id if one was recorded, or id itself.
+func (m *IDMap) Resolve(id string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if serverID, ok := m.m[id]; ok {
+		return serverID
+	}
+	return id
+}
+
+// Delete forgets the mapping for clientID.
+func (m *IDMap) Delete(clientID string) {
+	m.mu.Lock()
+	delete(m.m, clientID)
+	m.mu.Unlock()
+}
@@ -0,0 +1,35 @@
+package gographql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUUIDv7(t *testing.T) {
+	is := is.New(t)
+	gen := UUIDv7()
+	a, b := gen.NewID(), gen.NewID()
+	is.True(a != b)
+	is.True(regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(a))
+}
+
+func TestULID(t *testing.T) {
+	is := is.New(t)
+	id := ULID().NewID()
+	is.Equal(len(id), 26)
+	is.True(regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`).MatchString(id))
+}
+
+func TestClientOptimisticIDs(t *testing.T) {
+	is := is.New(t)
+	client := NewClient("", WithIDGenerator(IDGeneratorFunc(func() string { return "tmp-1" })))
+	id := client.NewOptimisticID()
+	is.Equal(id, "tmp-1")
+	is.Equal(client.IDs().Resolve(id), "tmp-1")
+	client.IDs().Set(id, "42")
+	is.Equal(client.IDs().Resolve(id), "42")
+	client.IDs().Delete(id)
+	is.Equal(client.IDs().Resolve(id), "tmp-1")
+}
@@ -0,0 +1,82 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ErrIdleTimeout nothing was received on a stream for longer than the
+// timeout set with WithStreamIdleTimeout.
+var ErrIdleTimeout = errors.New("stream idle timeout")
+
+// WithStreamIdleTimeout fails streams on which nothing was received for
+// d, independently of the deadline of their context, to detect
+// connections left half-dead by NATs and proxies. Subscription
+// connections are closed, ending their subscriptions with ErrIdleTimeout
+// or reconnecting with WithSubscriptionReconnect; any message counts,
+// including pings, so the server must send some while there are no
+// events. RunIncremental fails with ErrIdleTimeout once the response body
+// stalls.
+func WithStreamIdleTimeout(d time.Duration) ClientOption {
+	return func(client *Client) {
+		if d <= 0 {
+			client.invalidOption("WithStreamIdleTimeout: timeout must be positive, got %v", d)
+		}
+		client.idleTimeout = d
+	}
+}
+
+// watchIdle closes the connection once nothing was read for d, until the
+// connection is closed.
+func (conn *subConn) watchIdle(d time.Duration) {
+	ticker := time.NewTicker(max(d/4, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+		if time.Since(time.Unix(0, conn.ws.lastRead.Load())) >= d {
+			conn.idle.Store(true)
+			conn.ws.rwc.Close()
+			return
+		}
+	}
+}
+
+// idleReader cancels the request whose body it reads once no byte was
+// read for its timeout.
+type idleReader struct {
+	r     io.Reader
+	d     time.Duration
+	timer *time.Timer
+	fired atomic.Bool
+}
+
+// newIdleReader returns an idleReader reading r and calling cancel after
+// d without reading.
+func newIdleReader(r io.Reader, d time.Duration, cancel context.CancelFunc) *idleReader {
+	ir := &idleReader{r: r, d: d}
+	ir.timer = time.AfterFunc(d, func() {
+		ir.fired.Store(true)
+		cancel()
+	})
+	return ir
+}
+
+func (ir *idleReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	if n > 0 {
+		ir.timer.Reset(ir.d)
+	}
+	return n, err
+}
+
+// stop stops the timer.
+func (ir *idleReader) stop() {
+	ir.timer.Stop()
+}
@@ -0,0 +1,59 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestStreamIdleTimeoutSubscription(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		for {
+			if _, err := readWSMessage(conn); err != nil {
+				return // connected but silent
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithStreamIdleTimeout(30*time.Millisecond))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	for range payloads {
+	}
+	is.True(errors.Is(<-errs, ErrIdleTimeout))
+	is.NoErr(ctx.Err())
+}
+
+func TestStreamIdleTimeoutIncremental(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="-"; deferSpec=20220824`)
+		io.WriteString(w, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+`{"data":{"a":1},"hasNext":true}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // stalls
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var got int
+	err := NewClient(srv.URL, WithStreamIdleTimeout(30*time.Millisecond)).RunIncremental(ctx, NewRequest("{ a ... @defer { b } }"), func(p IncrementalPayload) error {
+		got++
+		return nil
+	})
+	is.True(errors.Is(err, ErrIdleTimeout))
+	is.Equal(got, 1)
+	is.NoErr(ctx.Err())
+}
@@ -0,0 +1,79 @@
+package gographql
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultImpersonationHeader is the header used to send the impersonated
+// user when Impersonation.Header is not set.
+const DefaultImpersonationHeader = "X-Impersonate-User"
+
+// ImpersonationAudit is called before every operation run on behalf of a
+// user. Returning an error aborts the operation.
+type ImpersonationAudit func(ctx context.Context, user string, req *Request) error
+
+// Impersonation configures acting on behalf of other users, for admin
+// tooling: the user carried by the context, see ContextWithImpersonation,
+// is sent on an act-as header.
+type Impersonation struct {
+	// Header is the header carrying the user, DefaultImpersonationHeader
+	// by default.
+	Header string
+	// Audit, if not nil, records impersonated operations. The context
+	// carries the Operation.
+	Audit ImpersonationAudit
+}
+
+// WithImpersonation enables impersonation for the client. Requests made
+// with a context carrying no user are sent as usual.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithImpersonation(gographql.Impersonation{
+//	    Audit: func(ctx context.Context, user string, req *gographql.Request) error {
+//	        op, _ := gographql.OperationFromContext(ctx)
+//	        return audit.Record(ctx, admin, user, op.Name)
+//	    },
+//	}))
+//	ctx = gographql.ContextWithImpersonation(ctx, "user-42")
+//	err := client.Run(ctx, req, &resp)
+func WithImpersonation(cfg Impersonation) ClientOption {
+	return func(client *Client) {
+		client.impersonation = &cfg
+	}
+}
+
+type impersonationKey struct{}
+
+// ContextWithImpersonation returns a copy of ctx in which operations are
+// run on behalf of user by clients created with WithImpersonation.
+func ContextWithImpersonation(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, user)
+}
+
+// ImpersonationFromContext returns the user ctx acts on behalf of.
+func ImpersonationFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(impersonationKey{}).(string)
+	return user, ok && user != ""
+}
+
+func (cfg *Impersonation) header() string {
+	if cfg.Header != "" {
+		return cfg.Header
+	}
+	return DefaultImpersonationHeader
+}
+
+// audit records the operation req if ctx impersonates a user.
+func (cfg *Impersonation) audit(ctx context.Context, req *Request) error {
+	user, ok := ImpersonationFromContext(ctx)
+	if !ok || cfg.Audit == nil {
+		return nil
+	}
+	return cfg.Audit(ctx, user, req)
+}
+
+func (cfg *Impersonation) attach(ctx context.Context, h http.Header) {
+	if user, ok := ImpersonationFromContext(ctx); ok {
+		h.Set(cfg.header(), user)
+	}
+}
@@ -0,0 +1,46 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestImpersonation(t *testing.T) {
+	is := is.New(t)
+	var users []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		users = append(users, r.Header.Get("X-Act-As"))
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var audited []string
+	denied := errors.New("denied")
+	client := NewClient(srv.URL, WithImpersonation(Impersonation{
+		Header: "X-Act-As",
+		Audit: func(ctx context.Context, user string, req *Request) error {
+			op, _ := OperationFromContext(ctx)
+			audited = append(audited, user+":"+op.Name)
+			if user == "root" {
+				return denied
+			}
+			return nil
+		},
+	}))
+	is.NoErr(client.Run(ctx, NewRequest("query Plain { ok }"), nil))
+	is.NoErr(client.Run(ContextWithImpersonation(ctx, "user-42"), NewRequest("query Me { ok }"), nil))
+	err := client.Run(ContextWithImpersonation(ctx, "root"), NewRequest("mutation Drop { ok }"), nil)
+	is.True(errors.Is(err, denied))
+
+	is.Equal(users, []string{"", "user-42"}) // denied operation not sent
+	is.Equal(audited, []string{"user-42:Me", "root:Drop"})
+}
@@ -0,0 +1,155 @@
+package gographql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// IncrementalPayload is one payload of a response delivered
+// incrementally with @defer and @stream: the initial result, a deferred
+// fragment or streamed list items.
+type IncrementalPayload struct {
+	// Data is the initial data, or the data of a deferred fragment to
+	// merge at Path.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Items are streamed list items to append to the list at Path.
+	Items json.RawMessage `json:"items,omitempty"`
+	// Path is the location of the deferred or streamed data, empty for
+	// the initial result.
+	Path []interface{} `json:"path,omitempty"`
+	// Label is the label argument of the @defer or @stream directive.
+	Label      string                 `json:"label,omitempty"`
+	Errors     GraphQLErrors          `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	// HasNext is the hasNext field of the part carrying the payload. It
+	// may be true for the last payload if the server ends the response
+	// with an empty part.
+	HasNext bool `json:"hasNext"`
+}
+
+// incrementalPart is a part of a multipart/mixed incremental response,
+// either the initial result or a list of incremental payloads.
+type incrementalPart struct {
+	IncrementalPayload
+	Incremental []IncrementalPayload `json:"incremental"`
+}
+
+// RunIncremental executes req, whose operation uses @defer or @stream,
+// and calls handler with the initial result and then with every
+// incremental payload as the server sends them in a multipart/mixed
+// response. Servers answering with a single JSON response result in one
+// call. GraphQL errors are delivered with the payloads, not returned;
+// an error returned by handler stops the response and is returned.
+//
+//	err := client.RunIncremental(ctx, req, func(p gographql.IncrementalPayload) error {
+//	    if len(p.Path) == 0 {
+//	        return json.Unmarshal(p.Data, &page)
+//	    }
+//	    return mergeAt(&page, p.Path, p.Data)
+//	})
+func (c *Client) RunIncremental(ctx context.Context, req *Request, handler func(IncrementalPayload) error) (err error) {
+	req = req.Clone()
+	body, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{req.q, req.vars})
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Accept", "multipart/mixed; deferSpec=20220824, "+acceptGraphQLResponse)
+	c.setHeaders(ctx, r, req)
+	if c.debug(ctx) {
+		c.log.Debugf("query: %s", req.q)
+	}
+	var cancel context.CancelFunc
+	if c.idleTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var resBody io.Reader = res.Body
+	if c.idleTimeout > 0 {
+		idle := newIdleReader(res.Body, c.idleTimeout, cancel)
+		defer func() {
+			idle.stop()
+			if err != nil && idle.fired.Load() {
+				err = ErrIdleTimeout
+			}
+		}()
+		resBody = idle
+	}
+	mediaType, params, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "multipart/mixed" {
+		var p IncrementalPayload
+		if err := json.NewDecoder(resBody).Decode(&p); err != nil {
+			if res.StatusCode != http.StatusOK {
+				return fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
+			}
+			return errors.Join(ErrDecodingResponse, err)
+		}
+		p.HasNext = false
+		return handler(p)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		boundary = "-"
+	}
+	mr := multipart.NewReader(bufio.NewReader(resBody), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+		var ip incrementalPart
+		err = json.NewDecoder(part).Decode(&ip)
+		part.Close()
+		if err == io.EOF {
+			continue // heartbeat
+		}
+		if err != nil {
+			return errors.Join(ErrDecodingResponse, err)
+		}
+		var payloads []IncrementalPayload
+		if ip.Data != nil || ip.Items != nil || len(ip.Errors) > 0 {
+			payloads = append(payloads, ip.IncrementalPayload)
+		}
+		payloads = append(payloads, ip.Incremental...)
+		for _, p := range payloads {
+			p.HasNext = ip.HasNext
+			if err := handler(p); err != nil {
+				return err
+			}
+		}
+		if !ip.HasNext {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRunIncremental(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.True(strings.HasPrefix(r.Header.Get("Accept"), "multipart/mixed"))
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="-"; deferSpec=20220824`)
+		part := func(body string) {
+			io.WriteString(w, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+body)
+			w.(http.Flusher).Flush()
+		}
+		part(`{"data":{"user":{"id":"1"}},"hasNext":true}`)
+		part(`{"incremental":[{"data":{"bio":"hi"},"path":["user"],"label":"Bio"},{"items":[{"id":"2"}],"path":["user","friends",0]}],"hasNext":true}`)
+		part(`{"hasNext":false}`)
+		io.WriteString(w, "\r\n-----\r\n")
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var got []IncrementalPayload
+	err := NewClient(srv.URL).RunIncremental(ctx, NewRequest("{ user { id ... @defer(label: \"Bio\") { bio } friends @stream { id } } }"), func(p IncrementalPayload) error {
+		got = append(got, p)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+	is.Equal(string(got[0].Data), `{"user":{"id":"1"}}`)
+	is.Equal(len(got[0].Path), 0)
+	is.True(got[0].HasNext)
+	is.Equal(string(got[1].Data), `{"bio":"hi"}`)
+	is.Equal(got[1].Path, []interface{}{"user"})
+	is.Equal(got[1].Label, "Bio")
+	is.Equal(string(got[2].Items), `[{"id":"2"}]`)
+	is.Equal(got[2].Path, []interface{}{"user", "friends", float64(0)})
+}
+
+func TestRunIncrementalSingleResponse(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"user":{"id":"1","bio":"hi"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	calls := 0
+	stop := errors.New("stop")
+	err := NewClient(srv.URL).RunIncremental(ctx, NewRequest("{ user { id bio } }"), func(p IncrementalPayload) error {
+		calls++
+		is.Equal(string(p.Data), `{"user":{"id":"1","bio":"hi"}}`)
+		is.True(!p.HasNext)
+		return stop
+	})
+	is.True(errors.Is(err, stop))
+	is.Equal(calls, 1)
+}
@@ -0,0 +1,110 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrInFlightBytesExceeded the bytes buffered by the operations in flight
+// are over the limit set with WithMaxInFlightBytes, which rejects new
+// runs.
+var ErrInFlightBytesExceeded = errors.New("too many bytes in flight")
+
+// InFlightLimit caps the memory buffered by the operations of a client.
+type InFlightLimit struct {
+	// MaxBytes is the number of request and response body bytes the
+	// operations in flight may buffer before new runs are held back.
+	MaxBytes int64
+	// Reject makes new runs fail with ErrInFlightBytesExceeded instead of
+	// waiting for operations to finish.
+	Reject bool
+}
+
+// WithMaxInFlightBytes tracks the request and response bodies buffered
+// by the operations in flight, responses as they are read, and holds back
+// new runs while they total limit.MaxBytes or more, protecting
+// memory-constrained services when the server slows down and operations
+// pile up. Runs already started may take the total over the limit.
+// Streamed file uploads are not counted.
+//
+//	gographql.WithMaxInFlightBytes(gographql.InFlightLimit{MaxBytes: 64 << 20})
+func WithMaxInFlightBytes(limit InFlightLimit) ClientOption {
+	return func(client *Client) {
+		if limit.MaxBytes <= 0 {
+			client.invalidOption("WithMaxInFlightBytes: MaxBytes must be positive, got %d", limit.MaxBytes)
+		}
+		client.inFlightBytes = &inFlightBytes{limit: limit, freed: make(chan struct{})}
+	}
+}
+
+// inFlightBytes counts the bytes buffered by the operations in flight.
+type inFlightBytes struct {
+	limit InFlightLimit
+
+	mu   sync.Mutex
+	used int64
+	// freed is closed and replaced when bytes are released.
+	freed chan struct{}
+}
+
+// runBytesKey is the context key of the *runBytes of a run.
+type runBytesKey struct{}
+
+// runBytes are the bytes buffered by a run.
+type runBytes struct {
+	mu sync.Mutex
+	n  int64
+}
+
+// admit waits until the bytes in flight are under the limit, or rejects
+// the run, and returns the context in which the run accounts its bytes,
+// along with the function releasing them.
+func (f *inFlightBytes) admit(ctx context.Context) (context.Context, func(), error) {
+	for {
+		f.mu.Lock()
+		if f.used < f.limit.MaxBytes {
+			f.mu.Unlock()
+			break
+		}
+		freed := f.freed
+		f.mu.Unlock()
+		if f.limit.Reject {
+			return ctx, nil, ErrInFlightBytesExceeded
+		}
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx, nil, ctx.Err()
+		}
+	}
+	rb := &runBytes{}
+	return context.WithValue(ctx, runBytesKey{}, rb), func() {
+		rb.mu.Lock()
+		n := rb.n
+		rb.n = 0
+		rb.mu.Unlock()
+		f.mu.Lock()
+		f.used -= n
+		close(f.freed)
+		f.freed = make(chan struct{})
+		f.mu.Unlock()
+	}, nil
+}
+
+// trackBytes accounts n bytes buffered by the run of ctx.
+func (c *Client) trackBytes(ctx context.Context, n int) {
+	if c.inFlightBytes == nil {
+		return
+	}
+	rb, ok := ctx.Value(runBytesKey{}).(*runBytes)
+	if !ok {
+		return
+	}
+	rb.mu.Lock()
+	rb.n += int64(n)
+	rb.mu.Unlock()
+	c.inFlightBytes.mu.Lock()
+	c.inFlightBytes.used += int64(n)
+	c.inFlightBytes.mu.Unlock()
+}
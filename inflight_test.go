@@ -0,0 +1,124 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestMaxInFlightBytes(t *testing.T) {
+	is := is.New(t)
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		received <- struct{}{}
+		<-release
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithMaxInFlightBytes(InFlightLimit{MaxBytes: 10}))
+	first := make(chan error, 1)
+	go func() {
+		first <- client.Run(ctx, NewRequest(`query { ok }`), nil)
+	}()
+	<-received
+
+	// the first request body is over the limit, so the second waits
+	second := make(chan error, 1)
+	go func() {
+		second <- client.Run(ctx, NewRequest(`query { ok }`), nil)
+	}()
+	select {
+	case <-received:
+		t.Fatal("second run was not held back")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release <- struct{}{}
+	is.NoErr(<-first)
+	<-received
+	release <- struct{}{}
+	is.NoErr(<-second)
+	is.Equal(client.inFlightBytes.used, int64(0))
+}
+
+func TestMaxInFlightBytesReject(t *testing.T) {
+	is := is.New(t)
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		received <- struct{}{}
+		<-release
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithMaxInFlightBytes(InFlightLimit{MaxBytes: 10, Reject: true}))
+	first := make(chan error, 1)
+	go func() {
+		first <- client.Run(ctx, NewRequest(`query { ok }`), nil)
+	}()
+	<-received
+	err := client.Run(ctx, NewRequest(`query { ok }`), nil)
+	is.True(errors.Is(err, ErrInFlightBytesExceeded))
+	close(release)
+	is.NoErr(<-first)
+
+	// the bytes are released once the run is done
+	is.NoErr(client.Run(ctx, NewRequest(`query { ok }`), nil))
+}
+
+func TestMaxInFlightBytesCountsResponseAsRead(t *testing.T) {
+	is := is.New(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"blob":"`+strings.Repeat("x", 4096))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		io.WriteString(w, `"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithMaxInFlightBytes(InFlightLimit{MaxBytes: 1024, Reject: true}))
+	first := make(chan error, 1)
+	go func() {
+		first <- client.Run(ctx, NewRequest(`query { blob }`), nil)
+	}()
+	// the response being read is counted before it is complete
+	for {
+		client.inFlightBytes.mu.Lock()
+		used := client.inFlightBytes.used
+		client.inFlightBytes.mu.Unlock()
+		if used >= 1024 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("response bytes were not counted while read")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	err := client.Run(ctx, NewRequest(`query { blob }`), nil)
+	is.True(errors.Is(err, ErrInFlightBytesExceeded))
+	close(release)
+	is.NoErr(<-first)
+	is.Equal(client.inFlightBytes.used, int64(0))
+}
@@ -0,0 +1,215 @@
+package gographql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJournalResponse is the size of the largest response body kept in a
+// journal entry.
+const maxJournalResponse = 64 << 10
+
+// redactedFields are the substrings of the variable and response field
+// names, matched ignoring case, whose values are never journaled.
+var redactedFields = []string{"password", "secret", "token", "apikey", "api_key", "credential"}
+
+// JournalEntry is a redacted record of an operation run by a Client, which
+// can be run again with Request. The values of the variables and response
+// fields named like credentials, such as password or token, are replaced
+// by "[REDACTED]", as are credential headers. Response bodies larger than
+// 64 KiB are left out.
+type JournalEntry struct {
+	Time      time.Time              `json:"time"`
+	Operation string                 `json:"operation,omitempty"`
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Header    http.Header            `json:"header,omitempty"`
+	// StatusCode is the HTTP status code of the response, zero if none
+	// was received.
+	StatusCode int `json:"statusCode,omitempty"`
+	// ResponseSize is the size of the response body in bytes.
+	ResponseSize int `json:"responseSize,omitempty"`
+	// Response is the response body, if it was JSON of at most 64 KiB.
+	Response json.RawMessage `json:"response,omitempty"`
+	Duration time.Duration   `json:"duration"`
+	// Error is the error returned by Run, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Request returns a request running the journaled operation again, with
+// its query, variables and headers. Redacted headers are left out, so the
+// credentials of the client running it apply, and redacted variables must
+// be set again with Var.
+func (e JournalEntry) Request() *Request {
+	req := NewRequest(e.Query)
+	for key, value := range e.Variables {
+		req.Var(key, value)
+	}
+	for key, values := range e.Header {
+		if len(values) == 1 && values[0] == "[REDACTED]" {
+			continue
+		}
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return req
+}
+
+// journal is a ring buffer of the latest entries.
+type journal struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+	next    int
+	full    bool
+}
+
+// WithJournal keeps a journal of the last size operations run by the
+// client, so that what was sent before a failure can be inspected with
+// Client.Journal or Client.DumpJournal, or dumped on SIGUSR1 with
+// Client.DumpJournalOnSignal. Clients made with Client.With share the
+// journal.
+func WithJournal(size int) ClientOption {
+	return func(client *Client) {
+		if size <= 0 {
+			client.invalidOption("WithJournal: size must be positive, got %d", size)
+			return
+		}
+		client.journal = &journal{entries: make([]JournalEntry, size)}
+	}
+}
+
+// Journal returns the journaled operations, oldest first, or nil if the
+// client was not created with WithJournal.
+func (c *Client) Journal() []JournalEntry {
+	if c.journal == nil {
+		return nil
+	}
+	return c.journal.list()
+}
+
+// DumpJournal writes the journaled operations to w, oldest first, as JSON
+// lines.
+func (c *Client) DumpJournal(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range c.Journal() {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) recordJournal(start time.Time, elapsed time.Duration, op *Operation, req *Request, meta *responseMeta, err error) {
+	redacted := redactRequest(req)
+	e := JournalEntry{
+		Time:         start,
+		Operation:    op.Name,
+		Query:        redacted.q,
+		Header:       redacted.Header,
+		StatusCode:   meta.statusCode,
+		ResponseSize: int(meta.size),
+		Duration:     elapsed,
+	}
+	if vars, ok := redactFields(req.vars).(map[string]interface{}); ok {
+		e.Variables = vars
+	}
+	body := meta.captured
+	if body == nil && len(meta.body) <= maxJournalResponse {
+		// the body kept for a caller, such as that of a shared request
+		body = meta.body
+	}
+	if len(body) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if dec.Decode(&v) == nil {
+			e.Response, _ = json.Marshal(redactFields(v))
+		}
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	c.journal.add(e)
+}
+
+// redactFields returns a generic copy of the JSON value of v, with the
+// values of the fields in redactedFields replaced by "[REDACTED]".
+func redactFields(v interface{}) interface{} {
+	switch v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}, []interface{}, string, bool, json.Number:
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil
+		}
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = redactFields(value)
+			lower := strings.ToLower(key)
+			for _, name := range redactedFields {
+				if strings.Contains(lower, name) {
+					out[key] = "[REDACTED]"
+				}
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = redactFields(v[i])
+		}
+		return out
+	}
+	return v
+}
+
+// cappedBuffer keeps what is written to it as long as it fits in limit
+// bytes.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || b.Len()+len(p) > b.limit {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+func (j *journal) add(e JournalEntry) {
+	j.mu.Lock()
+	j.entries[j.next] = e
+	j.next++
+	if j.next == len(j.entries) {
+		j.next = 0
+		j.full = true
+	}
+	j.mu.Unlock()
+}
+
+func (j *journal) list() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.full {
+		return append([]JournalEntry(nil), j.entries[:j.next]...)
+	}
+	return append(append([]JournalEntry(nil), j.entries[j.next:]...), j.entries[:j.next]...)
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package gographql
+
+import "io"
+
+// DumpJournalOnSignal writes the journal to w with DumpJournal every time
+// the process receives SIGUSR1, until stop is called. It is a no-op on
+// platforms without SIGUSR1.
+func (c *Client) DumpJournalOnSignal(w io.Writer) (stop func()) {
+	return func() {}
+}
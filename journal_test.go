@@ -0,0 +1,109 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestJournal(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), "Fail") {
+			w.WriteHeader(http.StatusBadGateway)
+			io.WriteString(w, `{"errors":[{"message":"upstream down"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithJournal(2))
+	for _, q := range []string{"query A { ok }", "query B { ok }", "mutation Fail($token: String!) { ok }"} {
+		req := NewRequest(q)
+		req.Var("token", "s3cret")
+		req.SetHeader("Authorization", "Bearer secret")
+		client.Run(ctx, req, nil)
+	}
+	entries := client.Journal()
+	is.Equal(len(entries), 2)
+	is.Equal(entries[0].Operation, "B")
+	is.Equal(entries[0].StatusCode, http.StatusOK)
+	is.Equal(entries[0].Error, "")
+	is.Equal(string(entries[0].Response), `{"data":{"ok":true}}`)
+	is.Equal(entries[1].Operation, "Fail")
+	is.Equal(entries[1].StatusCode, http.StatusBadGateway)
+	is.Equal(entries[1].Error, "graphql: upstream down")
+	is.Equal(entries[1].Variables["token"], "[REDACTED]")
+	is.Equal(entries[1].Header.Get("Authorization"), "[REDACTED]")
+
+	var buf bytes.Buffer
+	is.NoErr(client.DumpJournal(&buf))
+	is.True(!strings.Contains(buf.String(), "s3cret"))
+	dec := json.NewDecoder(&buf)
+	var e JournalEntry
+	is.NoErr(dec.Decode(&e))
+	is.Equal(e.Operation, "B")
+	is.NoErr(dec.Decode(&e))
+	is.Equal(e.Operation, "Fail")
+
+	is.Equal(NewClient(srv.URL).Journal(), nil)
+}
+
+func TestJournalReplay(t *testing.T) {
+	is := is.New(t)
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		is.Equal(r.Header.Get("X-Tenant"), "acme")
+		is.Equal(r.Header.Get("Authorization"), "Bearer replayer")
+		io.WriteString(w, `{"data":{"login":{"user":"mat","sessionToken":"abc"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithJournal(1))
+	req := NewRequest(`mutation Login($input: LoginInput!, $attempt: Int!) { login(input: $input) { user sessionToken } }`)
+	req.Var("input", struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}{"mat", "hunter2"})
+	req.Var("attempt", 12345678901)
+	req.SetHeader("X-Tenant", "acme")
+	req.SetHeader("Authorization", "Bearer replayer")
+	is.NoErr(client.Run(ctx, req, nil))
+
+	e := client.Journal()[0]
+	is.Equal(e.Variables["input"], map[string]interface{}{"user": "mat", "password": "[REDACTED]"})
+	is.Equal(string(e.Response), `{"data":{"login":{"sessionToken":"[REDACTED]","user":"mat"}}}`)
+
+	// a dumped entry runs again, with the redacted values set back
+	var buf bytes.Buffer
+	is.NoErr(client.DumpJournal(&buf))
+	is.True(!strings.Contains(buf.String(), "hunter2"))
+	is.NoErr(json.NewDecoder(&buf).Decode(&e))
+	replay := e.Request()
+	is.Equal(replay.Header.Get("Authorization"), "") // redacted
+	replay.SetHeader("Authorization", "Bearer replayer")
+	replay.Var("input", map[string]interface{}{"user": "mat", "password": "hunter2"})
+	is.NoErr(NewClient(srv.URL).Run(ctx, replay, nil))
+	is.Equal(len(bodies), 2)
+	var sent, replayed interface{}
+	is.NoErr(json.Unmarshal([]byte(bodies[0]), &sent))
+	is.NoErr(json.Unmarshal([]byte(bodies[1]), &replayed))
+	is.Equal(replayed, sent)
+	is.True(strings.Contains(bodies[1], `"attempt":12345678901`))
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package gographql
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// DumpJournalOnSignal writes the journal to w with DumpJournal every time
+// the process receives SIGUSR1, until stop is called. It is a no-op on
+// platforms without SIGUSR1.
+//
+//	stop := client.DumpJournalOnSignal(os.Stderr)
+//	defer stop()
+func (c *Client) DumpJournalOnSignal(w io.Writer) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGUSR1)
+	go func() {
+		for {
+			select {
+			case <-sig:
+				if err := c.DumpJournal(w); err != nil {
+					c.log.Errorf("dump journal: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// syncBuffer is a strings.Builder safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestDumpJournalOnSignal(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithJournal(10))
+	is.NoErr(client.Run(ctx, NewRequest("query Dumped { ok }"), nil))
+	var out syncBuffer
+	stop := client.DumpJournalOnSignal(&out)
+	defer stop()
+	is.NoErr(syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	for !strings.Contains(out.String(), `"operation":"Dumped"`) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("journal not dumped: %q", out.String())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package gographql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOp is an operation of a JSON Patch, see RFC 6902.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies patch to doc, a decoded JSON value which is
+// modified, and returns the result.
+func applyJSONPatch(doc interface{}, patch json.RawMessage) (interface{}, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("json patch: %w", err)
+	}
+	for _, op := range ops {
+		path, err := jsonPointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Value != nil {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("json patch: %s %s: %w", op.Op, op.Path, err)
+			}
+		}
+		switch op.Op {
+		case "add":
+			doc, err = pointerAdd(doc, path, value)
+		case "remove":
+			doc, _, err = pointerRemove(doc, path)
+		case "replace":
+			if doc, _, err = pointerRemove(doc, path); err == nil {
+				doc, err = pointerAdd(doc, path, value)
+			}
+		case "move", "copy":
+			var from []string
+			if from, err = jsonPointer(op.From); err != nil {
+				return nil, err
+			}
+			if op.Op == "move" {
+				doc, value, err = pointerRemove(doc, from)
+			} else if value, err = pointerGet(doc, from); err == nil {
+				value, err = deepCopyJSON(value)
+			}
+			if err == nil {
+				doc, err = pointerAdd(doc, path, value)
+			}
+		case "test":
+			var current interface{}
+			if current, err = pointerGet(doc, path); err == nil && !reflect.DeepEqual(current, value) {
+				err = fmt.Errorf("test failed")
+			}
+		default:
+			err = fmt.Errorf("unknown operation")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("json patch: %s %s: %w", op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointer splits a JSON Pointer, see RFC 6901, into its tokens.
+func jsonPointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("json patch: invalid pointer %q", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerIndex(token string, n int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n {
+		return 0, fmt.Errorf("invalid index %q", token)
+	}
+	return i, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := pointerIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("no member %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// pointerAdd adds value at path in doc and returns the new doc.
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("no member %q", token)
+		}
+		child, err := pointerAdd(child, rest, value)
+		node[token] = child
+		return node, err
+	case []interface{}:
+		if len(rest) == 0 {
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := pointerIndex(token, len(node)+1)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		i, err := pointerIndex(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		child, err := pointerAdd(node[i], rest, value)
+		node[i] = child
+		return node, err
+	}
+	return nil, fmt.Errorf("no member %q", token)
+}
+
+// pointerRemove removes the value at path in doc and returns the new doc
+// and the removed value.
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	token, rest := path[0], path[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("no member %q", token)
+		}
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := pointerRemove(child, rest)
+		node[token] = child
+		return node, removed, err
+	case []interface{}:
+		i, err := pointerIndex(token, len(node))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := node[i]
+			return append(node[:i], node[i+1:]...), removed, nil
+		}
+		child, removed, err := pointerRemove(node[i], rest)
+		node[i] = child
+		return node, removed, err
+	}
+	return nil, nil, fmt.Errorf("no member %q", token)
+}
+
+func deepCopyJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
@@ -0,0 +1,54 @@
+package gographql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{`{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{`{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{`{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{`{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{`{"a":[1,2]}`, `[{"op":"replace","path":"/a/0","value":9}]`, `{"a":[9,2]}`},
+		{`{"a":{"b":1},"c":{}}`, `[{"op":"move","from":"/a/b","path":"/c/d"}]`, `{"a":{},"c":{"d":1}}`},
+		{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{`{"a":1}`, `[{"op":"test","path":"/a","value":1},{"op":"replace","path":"","value":[]}]`, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			is := is.New(t)
+			var doc interface{}
+			is.NoErr(json.Unmarshal([]byte(tt.doc), &doc))
+			out, err := applyJSONPatch(doc, json.RawMessage(tt.patch))
+			is.NoErr(err)
+			b, _ := json.Marshal(out)
+			is.Equal(string(b), tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	for _, patch := range []string{
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"replace","path":"/a/5","value":1}]`,
+		`[{"op":"test","path":"/b","value":2}]`,
+		`[{"op":"frobnicate","path":"/b"}]`,
+		`[{"op":"add","path":"b","value":1}]`,
+	} {
+		t.Run(patch, func(t *testing.T) {
+			is := is.New(t)
+			var doc interface{}
+			is.NoErr(json.Unmarshal([]byte(`{"a":[1],"b":1}`), &doc))
+			_, err := applyJSONPatch(doc, json.RawMessage(patch))
+			is.True(err != nil)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrOperationDisabled the operation was disabled by the client's kill switch.
+var ErrOperationDisabled = errors.New("operation disabled")
+
+// OperationMode is the state of an operation in a KillSwitch.
+type OperationMode int
+
+const (
+	// OperationEnabled lets the operation run normally.
+	OperationEnabled OperationMode = iota
+	// OperationDisabled fails the operation with ErrOperationDisabled
+	// without contacting the server.
+	OperationDisabled
+	// OperationServeCached answers the operation from the client's cache
+	// without contacting the server, failing with ErrOperationDisabled
+	// when there is no cached result.
+	OperationServeCached
+)
+
+// FlagProvider returns the mode of op, typically by consulting a feature
+// flag service. It is called before every operation and should be fast.
+type FlagProvider func(ctx context.Context, op *Operation) OperationMode
+
+// KillSwitch is a registry of operations disabled at runtime, so a
+// misbehaving query can be stopped without a deploy. Operations are
+// identified by name. It is safe for concurrent use.
+type KillSwitch struct {
+	mu       sync.RWMutex
+	modes    map[string]OperationMode
+	provider FlagProvider
+}
+
+// NewKillSwitch makes a new KillSwitch. provider, if not nil, is consulted
+// for operations that were not set with Disable.
+func NewKillSwitch(provider FlagProvider) *KillSwitch {
+	return &KillSwitch{
+		modes:    make(map[string]OperationMode),
+		provider: provider,
+	}
+}
+
+// WithKillSwitch checks every operation against k before it is sent.
+func WithKillSwitch(k *KillSwitch) ClientOption {
+	return func(client *Client) {
+		client.killSwitch = k
+	}
+}
+
+// Disable sets the mode of the named operation, overriding the provider.
+func (k *KillSwitch) Disable(operation string, mode OperationMode) {
+	k.mu.Lock()
+	k.modes[operation] = mode
+	k.mu.Unlock()
+}
+
+// Enable removes the override for the named operation.
+func (k *KillSwitch) Enable(operation string) {
+	k.mu.Lock()
+	delete(k.modes, operation)
+	k.mu.Unlock()
+}
+
+// Mode returns the mode of op.
+func (k *KillSwitch) Mode(ctx context.Context, op *Operation) OperationMode {
+	k.mu.RLock()
+	mode, ok := k.modes[op.Name]
+	k.mu.RUnlock()
+	if ok {
+		return mode
+	}
+	if k.provider != nil {
+		return k.provider(ctx, op)
+	}
+	return OperationEnabled
+}
+
+// killed reports whether op was stopped by the kill switch, answering it
+// from the cache when allowed.
+func (c *Client) killed(ctx context.Context, req *Request, op *Operation, resp interface{}) (bool, error) {
+	switch c.killSwitch.Mode(ctx, op) {
+	case OperationDisabled:
+		return true, fmt.Errorf("%w: %s", ErrOperationDisabled, op.Name)
+	case OperationServeCached:
+		if c.cache != nil {
+			key := c.cacheKey(ctx, req)
+			if resp == nil {
+				if _, ok := c.cache.Read(key); ok {
+					return true, nil
+				}
+			} else if ok, err := c.cache.ReadInto(key, resp); ok {
+				return true, err
+			}
+		}
+		return true, fmt.Errorf("%w: %s: no cached result", ErrOperationDisabled, op.Name)
+	}
+	return false, nil
+}
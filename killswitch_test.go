@@ -0,0 +1,52 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestKillSwitch(t *testing.T) {
+	is := is.New(t)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"data":{"feed":"fresh"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	flags := map[string]OperationMode{"Search": OperationDisabled}
+	k := NewKillSwitch(func(ctx context.Context, op *Operation) OperationMode {
+		return flags[op.Name]
+	})
+	client := NewClient(srv.URL, WithKillSwitch(k), WithCache(NewCache()))
+	feed := NewRequest("query Feed { feed }")
+	var resp struct{ Feed string }
+	is.NoErr(client.Run(ctx, feed, &resp))
+	is.Equal(calls, 1)
+
+	err := client.Run(ctx, NewRequest("query Search { feed }"), &resp)
+	is.True(errors.Is(err, ErrOperationDisabled))
+	is.Equal(calls, 1)
+
+	k.Disable("Feed", OperationServeCached)
+	resp.Feed = ""
+	is.NoErr(client.Run(ctx, feed, &resp))
+	is.Equal(resp.Feed, "fresh")
+	is.Equal(calls, 1)
+	feed.Var("page", 2) // not cached
+	err = client.Run(ctx, feed, &resp)
+	is.True(errors.Is(err, ErrOperationDisabled))
+
+	k.Enable("Feed")
+	is.NoErr(client.Run(ctx, feed, &resp))
+	is.Equal(calls, 2)
+}
@@ -0,0 +1,143 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// TransportFallback configures WithTransportFallback.
+type TransportFallback struct {
+	// After is the number of consecutive failures of a transport after
+	// which an endpoint stops using it, 3 by default.
+	After int
+}
+
+// WithTransportFallback falls back down a ladder of transports when the
+// preferred one fails, and stops using a transport for an endpoint once
+// it failed there After times in a row:
+//
+//   - queries sent with GET (UseGET) are sent again with POST when the
+//     server or a proxy rejects them with 405, 414 or 431;
+//   - Automatic Persisted Queries are sent again with the query when the
+//     hash-only request fails with an HTTP error;
+//   - subscriptions whose WebSocket handshake is refused, as by proxies
+//     blocking upgrades, are made over Server-Sent Events, with the
+//     distinct connections mode of the GraphQL over SSE protocol.
+//
+// The request that failed is sent again right away, so callers do not see
+// the failure. Subscriptions have no rung below SSE: they cannot be
+// polled as queries.
+func WithTransportFallback(fallback TransportFallback) ClientOption {
+	return func(client *Client) {
+		if fallback.After < 0 {
+			client.invalidOption("WithTransportFallback: After must not be negative, got %d", fallback.After)
+		}
+		if fallback.After <= 0 {
+			fallback.After = 3
+		}
+		client.ladder = &transportLadder{after: fallback.After, endpoints: make(map[string]*endpointTransports)}
+	}
+}
+
+// transport is a rung of the ladder that can be given up.
+type transport int
+
+const (
+	transportGET transport = iota
+	transportAPQ
+	transportWebSocket
+	transportCount
+)
+
+// transportLadder remembers the transports working with each endpoint.
+type transportLadder struct {
+	after int
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointTransports
+}
+
+// endpointTransports are the consecutive failures of the transports of
+// an endpoint.
+type endpointTransports struct {
+	failures [transportCount]int
+}
+
+// use reports whether t should be tried with endpoint.
+func (l *transportLadder) use(endpoint string, t transport) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.endpoints[endpoint]
+	return !ok || e.failures[t] < l.after
+}
+
+// fail records a failure of t with endpoint.
+func (l *transportLadder) fail(endpoint string, t transport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.endpoints[endpoint]
+	if !ok {
+		e = &endpointTransports{}
+		l.endpoints[endpoint] = e
+	}
+	e.failures[t]++
+}
+
+// succeed records that t worked with endpoint.
+func (l *transportLadder) succeed(endpoint string, t transport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.endpoints[endpoint]; ok {
+		e.failures[t] = 0
+	}
+}
+
+// useTransport reports whether t should be tried for req, which is
+// always the case without WithTransportFallback.
+func (c *Client) useTransport(ctx context.Context, req *Request, t transport) bool {
+	if c.ladder == nil {
+		return true
+	}
+	endpoint, err := c.endpoint(ctx, req)
+	return err != nil || c.ladder.use(endpoint, t)
+}
+
+// transportWorked records that t worked for req.
+func (c *Client) transportWorked(ctx context.Context, req *Request, t transport) {
+	if c.ladder == nil {
+		return
+	}
+	if endpoint, err := c.endpoint(ctx, req); err == nil {
+		c.ladder.succeed(endpoint, t)
+	}
+}
+
+// fallBack reports whether the failure err of t for req, whose response
+// had the HTTP status, if any, calls for the next rung, recording it.
+func (c *Client) fallBack(ctx context.Context, req *Request, t transport, status int, err error) bool {
+	if c.ladder == nil || err == nil || ctx.Err() != nil {
+		return false
+	}
+	var failed bool
+	switch t {
+	case transportGET:
+		failed = status == http.StatusMethodNotAllowed || status == http.StatusRequestURITooLong ||
+			status == http.StatusRequestHeaderFieldsTooLarge
+	case transportAPQ:
+		failed = errors.Is(err, ErrGraphqlServerError)
+	case transportWebSocket:
+		failed = errors.Is(err, ErrWebSocketHandshake)
+	}
+	endpoint, endpointErr := c.endpoint(ctx, req)
+	if !failed || endpointErr != nil {
+		return false
+	}
+	c.ladder.fail(endpoint, t)
+	if c.debug(ctx) {
+		c.log.Debugf("transport failed with %s, falling back: %v", endpoint, err)
+	}
+	c.emit(Event{Type: EventRetry, URL: endpoint, Err: err})
+	return true
+}
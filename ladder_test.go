@@ -0,0 +1,122 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTransportFallbackGET(t *testing.T) {
+	is := is.New(t)
+	var mu sync.Mutex
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusRequestURITooLong)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseGET(), WithTransportFallback(TransportFallback{After: 2}))
+	for i := 0; i < 3; i++ {
+		var resp struct{ OK bool }
+		is.NoErr(client.Run(ctx, NewRequest(`{ ok }`), &resp))
+		is.True(resp.OK)
+	}
+	// GET is given up after failing twice
+	is.Equal(methods, []string{"GET", "POST", "GET", "POST", "POST"})
+}
+
+func TestTransportFallbackAPQ(t *testing.T) {
+	is := is.New(t)
+	var mu sync.Mutex
+	var queries []bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query      string
+			Extensions map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		queries = append(queries, body.Query != "")
+		mu.Unlock()
+		if body.Extensions != nil {
+			// a proxy rejecting the extensions
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, "bad request")
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithAutomaticPersistedQueries(), WithTransportFallback(TransportFallback{After: 1}))
+	var events []EventType
+	client.Listen(func(e Event) { events = append(events, e.Type) })
+	is.NoErr(client.Run(ctx, NewRequest(`{ ok }`), nil))
+	is.NoErr(client.Run(ctx, NewRequest(`{ ok }`), nil))
+	is.Equal(queries, []bool{false, true, true})
+	is.Equal(events, []EventType{EventRetry})
+
+	// other endpoints still use persisted queries
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		is.True(!strings.Contains(string(b), `"query"`))
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer other.Close()
+	otherClient := client.With()
+	otherClient.Endpoint = other.URL
+	is.NoErr(otherClient.Run(ctx, NewRequest(`{ ok }`), nil))
+}
+
+func TestTransportFallbackSubscription(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			http.Error(w, "upgrades are blocked", http.StatusForbidden)
+			return
+		}
+		is.Equal(r.Header.Get("Accept"), "text/event-stream")
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 2; i++ {
+			io.WriteString(w, "event: next\ndata: {\"data\":{\"count\":"+string(rune('0'+i))+"}}\n\n")
+		}
+		io.WriteString(w, "event: complete\ndata:\n\n")
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithTransportFallback(TransportFallback{After: 1}))
+	for i := 0; i < 2; i++ {
+		req := NewRequest(`subscription { count }`)
+		req.SetHeader("Authorization", "Bearer token")
+		payloads, errs, err := client.Subscribe(ctx, req)
+		is.NoErr(err)
+		var got []string
+		for p := range payloads {
+			got = append(got, string(p.Data))
+		}
+		is.NoErr(<-errs)
+		is.Equal(got, []string{`{"count":1}`, `{"count":2}`})
+	}
+}
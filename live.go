@@ -0,0 +1,101 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// LiveResult is a revision of the result of a live query.
+type LiveResult struct {
+	// Data is the whole result, patches applied. It is nil when the
+	// server sent errors only, which leave the previous result in place
+	// for later patches.
+	Data   json.RawMessage
+	Errors GraphQLErrors
+	// Revision numbers the results from 1, unless the server numbers
+	// them itself.
+	Revision int
+}
+
+// Live runs the live query req, marked with the @live directive, over
+// the subscription transport, and returns the channel of the revisions
+// of its result and a channel receiving at most one error, as Subscribe
+// does. Servers may send every revision whole, or as a JSON Patch
+// (RFC 6902) of the previous one in the patch field of the payload;
+// results are delivered whole either way.
+//
+//	req := gographql.NewRequest(`query Dashboard @live { stats { online } }`)
+//	results, errs, err := client.Live(ctx, req)
+//	for r := range results {
+//	    render(r.Data)
+//	}
+func (c *Client) Live(ctx context.Context, req *Request) (<-chan LiveResult, <-chan error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	payloads, errs, err := c.Subscribe(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	results := make(chan LiveResult)
+	liveErrs := make(chan error, 1)
+	go func() {
+		defer close(liveErrs)
+		defer cancel()
+		var current interface{}
+		var failed error
+		revision := 0
+		for p := range payloads {
+			if failed != nil {
+				continue // drain until the subscription ends
+			}
+			r, err := nextRevision(&current, p)
+			if err != nil {
+				failed = err
+				cancel()
+				continue
+			}
+			revision++
+			if p.Revision > 0 {
+				revision = p.Revision
+			}
+			r.Revision = revision
+			select {
+			case results <- r:
+			case <-ctx.Done():
+			}
+		}
+		close(results)
+		if err := <-errs; err != nil {
+			liveErrs <- err
+		} else if failed != nil {
+			liveErrs <- failed
+		}
+	}()
+	return results, liveErrs, nil
+}
+
+// nextRevision updates current, the decoded result, with p.
+func nextRevision(current *interface{}, p SubscriptionPayload) (LiveResult, error) {
+	r := LiveResult{Data: p.Data, Errors: p.Errors}
+	if p.Patch == nil && len(p.Data) == 0 && len(p.Errors) > 0 {
+		return r, nil
+	}
+	if p.Patch == nil {
+		*current = nil
+		if err := json.Unmarshal(p.Data, current); err != nil {
+			return r, errors.Join(ErrDecodingResponse, err)
+		}
+		return r, nil
+	}
+	if *current == nil {
+		return r, errors.New("live query: patch without a previous result")
+	}
+	next, err := applyJSONPatch(*current, p.Patch)
+	if err != nil {
+		return r, err
+	}
+	*current = next
+	r.Data, err = json.Marshal(next)
+	return r, err
+}
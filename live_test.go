@@ -0,0 +1,93 @@
+package gographql
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestLive(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		id := msg.ID
+		writeWSMessage(conn, `{"id":"`+id+`","type":"next","payload":{"data":{"stats":{"online":1,"users":["a"]}},"revision":1}}`)
+		writeWSMessage(conn, `{"id":"`+id+`","type":"next","payload":{"patch":[{"op":"replace","path":"/stats/online","value":2},{"op":"add","path":"/stats/users/-","value":"b"}],"revision":2}}`)
+		writeWSMessage(conn, `{"id":"`+id+`","type":"next","payload":{"data":{"stats":{"online":0,"users":[]}}}}`)
+		writeWSMessage(conn, `{"id":"`+id+`","type":"complete"}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	results, errs, err := NewClient(srv.URL).Live(ctx, NewRequest("query Dashboard @live { stats { online users } }"))
+	is.NoErr(err)
+	var got []LiveResult
+	for r := range results {
+		got = append(got, r)
+	}
+	is.NoErr(<-errs)
+	is.Equal(len(got), 3)
+	is.Equal(string(got[0].Data), `{"stats":{"online":1,"users":["a"]}}`)
+	is.Equal(got[0].Revision, 1)
+	is.Equal(string(got[1].Data), `{"stats":{"online":2,"users":["a","b"]}}`)
+	is.Equal(got[1].Revision, 2)
+	is.Equal(string(got[2].Data), `{"stats":{"online":0,"users":[]}}`)
+	is.Equal(got[2].Revision, 3)
+}
+
+func TestLivePatchWithoutResult(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, _ := readWSMessage(conn)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"patch":[{"op":"replace","path":"/a","value":2}]}}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	results, errs, err := NewClient(srv.URL).Live(ctx, NewRequest("query @live { a }"))
+	is.NoErr(err)
+	for range results {
+	}
+	is.True(<-errs != nil)
+}
+
+func TestLiveErrorsOnly(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, _ := readWSMessage(conn)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"a":1}}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"errors":[{"message":"stale"}]}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"patch":[{"op":"replace","path":"/a","value":2}]}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"complete"}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	results, errs, err := NewClient(srv.URL).Live(ctx, NewRequest("query @live { a }"))
+	is.NoErr(err)
+	var got []LiveResult
+	for r := range results {
+		got = append(got, r)
+	}
+	is.NoErr(<-errs)
+	is.Equal(len(got), 3)
+	is.Equal(len(got[1].Data), 0)
+	is.Equal(got[1].Errors.Error(), "graphql: stale")
+	is.Equal(string(got[2].Data), `{"a":2}`)
+}
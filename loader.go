@@ -0,0 +1,173 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound the batch function of a Loader returned no value for the
+// key.
+var ErrKeyNotFound = errors.New("key not found")
+
+// LoaderFunc fetches the values of keys, usually with a single request
+// taking the keys as a list variable. Keys missing from the returned map
+// fail with ErrKeyNotFound.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, c *Client, keys []K) (map[K]V, error)
+
+// LoaderOption configures a Loader.
+type LoaderOption func(*loaderConfig)
+
+type loaderConfig struct {
+	window   time.Duration
+	maxBatch int
+}
+
+// WithBatchWindow sets how long a Loader collects keys after the first
+// one before fetching them, 2ms by default.
+func WithBatchWindow(d time.Duration) LoaderOption {
+	return func(cfg *loaderConfig) {
+		cfg.window = d
+	}
+}
+
+// WithMaxBatchSize fetches the keys collected by a Loader as soon as
+// there are n of them, without waiting for the end of the window.
+func WithMaxBatchSize(n int) LoaderOption {
+	return func(cfg *loaderConfig) {
+		cfg.maxBatch = n
+	}
+}
+
+// Loader collects the keys looked up concurrently within a short window
+// and fetches them together with a LoaderFunc, so resolving many entities
+// by ID takes one request instead of one per entity. A Loader is safe for
+// concurrent use.
+//
+//	users := gographql.NewLoader(client, gographql.BatchByIDs[User](
+//	    `query ($ids: [ID!]!) { users(ids: $ids) { id name } }`, "data.users",
+//	    func(u User) string { return u.ID }))
+//	user, err := users.Load(ctx, "42")
+type Loader[K comparable, V any] struct {
+	client *Client
+	fetch  LoaderFunc[K, V]
+	cfg    loaderConfig
+
+	mu      sync.Mutex
+	pending *loaderBatch[K, V]
+}
+
+// loaderBatch is a set of keys fetched together, whose results are set
+// once done is closed.
+type loaderBatch[K comparable, V any] struct {
+	ctx    context.Context
+	keys   []K
+	seen   map[K]bool
+	timer  *time.Timer
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// NewLoader returns a Loader fetching keys with fetch.
+func NewLoader[K comparable, V any](c *Client, fetch LoaderFunc[K, V], opts ...LoaderOption) *Loader[K, V] {
+	l := &Loader[K, V]{client: c, fetch: fetch, cfg: loaderConfig{window: 2 * time.Millisecond}}
+	for _, optionFunc := range opts {
+		optionFunc(&l.cfg)
+	}
+	return l
+}
+
+// Load returns the value of key, fetched along with the other keys loaded
+// within the same window. The batch is fetched with the context of its
+// first key, whose cancellation fails the whole batch; other callers only
+// stop waiting when their context is done.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	var zero V
+	l.mu.Lock()
+	b := l.pending
+	if b == nil {
+		b = &loaderBatch[K, V]{ctx: ctx, seen: make(map[K]bool), done: make(chan struct{})}
+		l.pending = b
+		b.timer = time.AfterFunc(l.cfg.window, func() { l.dispatch(b) })
+	}
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	if l.cfg.maxBatch > 0 && len(b.keys) >= l.cfg.maxBatch && b.timer.Stop() {
+		l.pending = nil
+		go l.dispatch(b)
+	}
+	l.mu.Unlock()
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if b.err != nil {
+		return zero, b.err
+	}
+	v, ok := b.values[key]
+	if !ok {
+		return zero, fmt.Errorf("%w: %v", ErrKeyNotFound, key)
+	}
+	return v, nil
+}
+
+// LoadMany returns the values of keys, in the same order.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(ctx, key)
+		}()
+	}
+	wg.Wait()
+	return values, errors.Join(errs...)
+}
+
+// dispatch fetches the keys of b, which no longer accepts keys.
+func (l *Loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+	b.values, b.err = l.fetch(b.ctx, l.client, b.keys)
+	close(b.done)
+}
+
+// BatchByIDs returns a LoaderFunc running query with the keys as its $ids
+// variable and reading the list of items at path, which uses the syntax
+// of RawResponse.GetPath. id returns the key of an item.
+func BatchByIDs[V any](query, path string, id func(V) string) LoaderFunc[string, V] {
+	return func(ctx context.Context, c *Client, keys []string) (map[string]V, error) {
+		req := NewRequest(query)
+		req.Var("ids", keys)
+		raw, err := c.RunRaw(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(raw.GetPath(path))
+		if err != nil {
+			return nil, err
+		}
+		var items []V
+		if err := json.Unmarshal(b, &items); err != nil {
+			return nil, errors.Join(ErrDecodingResponse, err)
+		}
+		values := make(map[string]V, len(items))
+		for _, item := range items {
+			values[id(item)] = item
+		}
+		return values, nil
+	}
+}
@@ -0,0 +1,77 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestLoader(t *testing.T) {
+	is := is.New(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var body struct{ Variables struct{ IDs []string } }
+		json.NewDecoder(r.Body).Decode(&body)
+		var users []string
+		for _, id := range body.Variables.IDs {
+			if id != "404" {
+				users = append(users, fmt.Sprintf(`{"id":%q,"name":"user %s"}`, id, id))
+			}
+		}
+		fmt.Fprintf(w, `{"data":{"users":[%s]}}`, strings.Join(users, ","))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	type user struct {
+		ID   string
+		Name string
+	}
+	users := NewLoader(NewClient(srv.URL), BatchByIDs(`query ($ids: [ID!]!) { users(ids: $ids) { id name } }`, "data.users",
+		func(u user) string { return u.ID }), WithBatchWindow(20*time.Millisecond))
+	got, err := users.LoadMany(ctx, []string{"1", "2", "1", "3"})
+	is.NoErr(err)
+	is.Equal(calls.Load(), int32(1))
+	is.Equal(len(got), 4)
+	is.Equal(got[0].Name, "user 1")
+	is.Equal(got[2].Name, "user 1")
+	is.Equal(got[3].Name, "user 3")
+
+	_, err = users.Load(ctx, "404")
+	is.True(errors.Is(err, ErrKeyNotFound))
+	is.Equal(calls.Load(), int32(2))
+}
+
+func TestLoaderMaxBatchSize(t *testing.T) {
+	is := is.New(t)
+	var batches atomic.Int32
+	loader := NewLoader(nil, func(ctx context.Context, c *Client, keys []int) (map[int]int, error) {
+		batches.Add(1)
+		if len(keys) > 2 {
+			return nil, errors.New("batch too large")
+		}
+		values := make(map[int]int)
+		for _, k := range keys {
+			values[k] = k * k
+		}
+		return values, nil
+	}, WithBatchWindow(time.Hour), WithMaxBatchSize(2))
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	got, err := loader.LoadMany(ctx, []int{1, 2, 3, 4})
+	is.NoErr(err)
+	is.Equal(got, []int{1, 4, 9, 16})
+	is.Equal(batches.Load(), int32(2))
+}
@@ -8,12 +8,89 @@ import (
 
 // Logger is the abstract logging interface, gives control to
 // the Req users, choice of the logger.
+// Loggers may also implement InfoLogger to receive informational
+// messages, which are otherwise logged at debug level.
 type Logger interface {
 	Errorf(format string, v ...interface{})
 	Warnf(format string, v ...interface{})
 	Debugf(format string, v ...interface{})
 }
 
+// InfoLogger is implemented by loggers supporting the info level.
+type InfoLogger interface {
+	Infof(format string, v ...interface{})
+}
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+// Log levels, from least to most severe.
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// WithLogLevel drops log messages less severe than level, whichever
+// logger is used. Debug messages are also only written when debug
+// logging is enabled.
+func WithLogLevel(level LogLevel) ClientOption {
+	return func(client *Client) {
+		client.logLevel = level
+	}
+}
+
+// levelLogger filters the messages of a Logger by level.
+type levelLogger struct {
+	next Logger
+	min  LogLevel
+}
+
+// withLevel returns l filtered by min.
+func withLevel(l Logger, min LogLevel) Logger {
+	if ll, ok := l.(*levelLogger); ok {
+		l = ll.next
+	}
+	if min <= LevelDebug {
+		return l
+	}
+	return &levelLogger{next: l, min: min}
+}
+
+func (l *levelLogger) Errorf(format string, v ...interface{}) {
+	if l.min <= LevelError {
+		l.next.Errorf(format, v...)
+	}
+}
+
+func (l *levelLogger) Warnf(format string, v ...interface{}) {
+	if l.min <= LevelWarn {
+		l.next.Warnf(format, v...)
+	}
+}
+
+func (l *levelLogger) Infof(format string, v ...interface{}) {
+	if l.min <= LevelInfo {
+		infof(l.next, format, v...)
+	}
+}
+
+func (l *levelLogger) Debugf(format string, v ...interface{}) {
+	if l.min <= LevelDebug {
+		l.next.Debugf(format, v...)
+	}
+}
+
+// infof logs at info level, or at debug level if l does not support it.
+func infof(l Logger, format string, v ...interface{}) {
+	if il, ok := l.(InfoLogger); ok {
+		il.Infof(format, v...)
+		return
+	}
+	l.Debugf(format, v...)
+}
+
 // NewLogger create a Logger wraps the *log.Logger.
 func NewLogger(outputf io.Writer, prefix string, flag int) Logger {
 	return &logger{l: log.New(outputf, prefix, flag)}
@@ -33,6 +110,7 @@ type disableLogger struct{}
 
 func (l *disableLogger) Errorf(format string, v ...interface{}) {}
 func (l *disableLogger) Warnf(format string, v ...interface{})  {}
+func (l *disableLogger) Infof(format string, v ...interface{})  {}
 func (l *disableLogger) Debugf(format string, v ...interface{}) {}
 
 type logger struct {
@@ -47,6 +125,10 @@ func (l *logger) Warnf(format string, v ...interface{}) {
 	l.outputf("WARN", format, v...)
 }
 
+func (l *logger) Infof(format string, v ...interface{}) {
+	l.outputf("INFO", format, v...)
+}
+
 func (l *logger) Debugf(format string, v ...interface{}) {
 	l.outputf("DEBUG", format, v...)
 }
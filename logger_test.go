@@ -16,3 +16,24 @@ func TestLogger(t *testing.T) {
 	c.Run(context.Background(), &Request{}, nil)
 	assert.Contains(t, buf.String(), "DEBUG")
 }
+
+func TestLogLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := NewClient("/test", WithLogLevel(LevelWarn)).SetLogger(NewLogger(buf, "", 0)).EnableDebugLog()
+	c.Run(context.Background(), &Request{}, nil)
+	l := c.GetLogger()
+	l.Warnf("warn")
+	l.(InfoLogger).Infof("info")
+	l.Errorf("error")
+	assert.NotContains(t, buf.String(), "DEBUG")
+	assert.NotContains(t, buf.String(), "INFO")
+	assert.Contains(t, buf.String(), "WARN [req] warn\nERROR [req] error\n")
+}
+
+func TestNoLogsWithoutDebug(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := NewClient("http://127.0.0.1:1/graphql").SetLogger(NewLogger(buf, "", 0))
+	err := c.Run(context.Background(), NewRequest("{ a }"), nil)
+	assert.Error(t, err)
+	assert.Empty(t, buf.String())
+}
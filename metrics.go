@@ -0,0 +1,95 @@
+package gographql
+
+import (
+	"context"
+	"time"
+)
+
+// Operation describes the GraphQL operation being executed. It is
+// available from the context passed to the HTTPClient and to the Metrics
+// recorder, so tracing and logging middleware can use the same labels.
+type Operation struct {
+	// Name is the operation name, empty for anonymous operations.
+	Name string
+	// Type is "query", "mutation" or "subscription".
+	Type string
+	// Labels are the custom labels computed by the client's Labeler.
+	Labels map[string]string
+	// TraceID is the W3C trace ID of the trace context carried by the
+	// request context, if any.
+	TraceID string
+	// Timings is the latency breakdown of the HTTP exchange, set once
+	// the response was read when the client was created with
+	// WithLatencyBreakdown.
+	Timings *Timings
+}
+
+// Exemplar returns the labels of an exemplar linking a measurement of op
+// to its trace, or nil if op is not traced. The labels can be passed to
+// Prometheus as they are:
+//
+//	func (m *metrics) ObserveOperation(ctx context.Context, op *gographql.Operation, d time.Duration, err error) {
+//	    obs := m.latency.WithLabelValues(op.Type, op.Name)
+//	    if exemplar := op.Exemplar(); exemplar != nil {
+//	        obs.(prometheus.ExemplarObserver).ObserveWithExemplar(d.Seconds(), exemplar)
+//	        return
+//	    }
+//	    obs.Observe(d.Seconds())
+//	}
+func (op *Operation) Exemplar() map[string]string {
+	if op.TraceID == "" {
+		return nil
+	}
+	return map[string]string{"trace_id": op.TraceID}
+}
+
+// Labeler derives custom labels (e.g. team or feature) for a request.
+type Labeler func(ctx context.Context, req *Request) map[string]string
+
+// Metrics records measurements of executed operations.
+type Metrics interface {
+	// ObserveOperation is called once per Run with the operation,
+	// its duration and the resulting error, if any.
+	ObserveOperation(ctx context.Context, op *Operation, duration time.Duration, err error)
+}
+
+// WithLabeler sets the function used to derive custom labels for every
+// request. Labels are attached to the Operation in the request context,
+// passed to the Metrics recorder and included in debug logs.
+func WithLabeler(labeler Labeler) ClientOption {
+	return func(client *Client) {
+		client.labeler = labeler
+	}
+}
+
+// WithMetrics sets the recorder notified after every operation.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(client *Client) {
+		client.metrics = metrics
+	}
+}
+
+type operationKey struct{}
+
+// OperationFromContext returns the operation being executed by the
+// Client that made ctx, if any.
+func OperationFromContext(ctx context.Context) (*Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(*Operation)
+	return op, ok
+}
+
+// operation describes req and attaches the description to ctx.
+func (c *Client) operation(ctx context.Context, req *Request) (context.Context, *Operation) {
+	op := &Operation{}
+	op.Type, op.Name = operationInfo(req.q)
+	if tc, ok := TraceContextFromContext(ctx); ok {
+		op.TraceID = tc.TraceID()
+	}
+	if c.labeler != nil {
+		op.Labels = c.labeler(ctx, req)
+	}
+	if c.debug(ctx) {
+		c.log.Debugf("operation: %s %s labels: %v", op.Type, op.Name, op.Labels)
+	}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
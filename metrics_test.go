@@ -0,0 +1,84 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type metricsFunc func(ctx context.Context, op *Operation, duration time.Duration, err error)
+
+func (fn metricsFunc) ObserveOperation(ctx context.Context, op *Operation, duration time.Duration, err error) {
+	fn(ctx, op, duration, err)
+}
+
+func TestLabelerAndMetrics(t *testing.T) {
+	is := is.New(t)
+	var transportOp *Operation
+	testClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			transportOp, _ = OperationFromContext(req.Context())
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data":{}}`)),
+			}, nil
+		}),
+	}
+	var observed *Operation
+	client := NewClient("http://example.com",
+		WithHTTPClient(testClient),
+		WithLabeler(func(ctx context.Context, req *Request) map[string]string {
+			return map[string]string{"team": req.Header.Get("X-Team")}
+		}),
+		WithMetrics(metricsFunc(func(ctx context.Context, op *Operation, duration time.Duration, err error) {
+			observed = op
+		})),
+	)
+	req := NewRequest(`fragment F on User { id } mutation CreateUser { createUser { ...F } }`)
+	req.Header.Set("X-Team", "growth")
+	is.NoErr(client.Run(context.Background(), req, nil))
+	is.Equal(observed.Type, "mutation")
+	is.Equal(observed.Name, "CreateUser")
+	is.Equal(observed.Labels, map[string]string{"team": "growth"})
+	is.Equal(transportOp, observed)
+}
+
+func TestExemplar(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	var observed *Operation
+	client := NewClient(srv.URL, WithMetrics(metricsFunc(func(ctx context.Context, op *Operation, duration time.Duration, err error) {
+		observed = op
+	})))
+	is.NoErr(client.Run(context.Background(), NewRequest("{ a }"), nil))
+	is.Equal(observed.Exemplar(), nil)
+	ctx := ContextWithTraceContext(context.Background(), TraceContext{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.Equal(observed.Exemplar(), map[string]string{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"})
+}
+
+func TestOperationInfo(t *testing.T) {
+	is := is.New(t)
+	for src, want := range map[string][2]string{
+		`{ user { id } }`:                              {"query", ""},
+		`query { a }`:                                  {"query", ""},
+		`query Q($a: Int = 1) { a }`:                   {"query", "Q"},
+		`# comment` + "\n" + `subscription S { a }`:    {"subscription", "S"},
+		`fragment F on T { a { b } } mutation M { a }`: {"mutation", "M"},
+		``: {"", ""},
+	} {
+		kind, name := operationInfo(src)
+		is.Equal([2]string{kind, name}, want)
+	}
+}
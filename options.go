@@ -0,0 +1,65 @@
+package gographql
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidOption NewClientE was given an invalid endpoint or an invalid
+// or conflicting option.
+var ErrInvalidOption = errors.New("invalid client option")
+
+// NewClientE is like NewClient but reports invalid and conflicting
+// options, such as a malformed endpoint URL or an invalid field transform
+// selector, instead of failing at the first Run. The returned error wraps
+// ErrInvalidOption and joins every problem found.
+func NewClientE(endpoint string, opts ...ClientOption) (*Client, error) {
+	c := NewClient(endpoint, opts...)
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// invalidOption records a problem with an option for NewClientE.
+func (c *Client) invalidOption(format string, v ...interface{}) {
+	c.optionErrs = append(c.optionErrs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidOption}, v...)...))
+}
+
+func (c *Client) validate() error {
+	invalid := c.invalidOption
+	// placeholders of templated endpoints are resolved per request
+	endpoint := endpointParamRe.ReplaceAllString(c.Endpoint, "placeholder")
+	if u, err := url.Parse(endpoint); err != nil {
+		invalid("endpoint: %v", err)
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("endpoint %q: must be an absolute http or https URL", c.Endpoint)
+	}
+	for op, override := range c.opEndpoints {
+		if strings.HasPrefix(override, "/") {
+			continue
+		}
+		u, err := url.Parse(endpointParamRe.ReplaceAllString(override, "placeholder"))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("%s endpoint %q: must be an absolute URL or path", op, override)
+		}
+	}
+	if c.deadlines != nil {
+		for p, d := range c.deadlines.MinRemaining {
+			if d < 0 {
+				invalid("deadline policy: negative MinRemaining %v for priority %d", d, p)
+			}
+		}
+		for p, share := range c.deadlines.Share {
+			if share < 0 || share > 1 {
+				invalid("deadline policy: Share %v for priority %d is not between 0 and 1", share, p)
+			}
+		}
+	}
+	if c.consistency != nil && c.consistency.ResponseHeader == "" && c.consistency.Extension == "" {
+		invalid("consistency tokens: ResponseHeader or Extension must be set")
+	}
+	return errors.Join(c.optionErrs...)
+}
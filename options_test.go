@@ -0,0 +1,30 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestNewClientE(t *testing.T) {
+	is := is.New(t)
+	client, err := NewClientE("https://api.example.com/graphql", WithDefaultHeaders(nil))
+	is.NoErr(err)
+	is.True(client != nil)
+
+	_, err = NewClientE("api.example.com/graphql",
+		WithFieldTransform("users..ssn", func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error) {
+			return value, nil
+		}),
+		WithDeadlinePolicy(DeadlinePolicy{MinRemaining: map[Priority]time.Duration{PriorityLow: -time.Second}}),
+		WithConsistencyTokens(ConsistencyTokens{}),
+	)
+	is.True(errors.Is(err, ErrInvalidOption))
+	for _, want := range []string{"WithFieldTransform", "endpoint", "MinRemaining", "consistency tokens"} {
+		is.True(strings.Contains(err.Error(), want))
+	}
+}
@@ -0,0 +1,121 @@
+package gographql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNoPageInfo no pageInfo object was found in the response.
+var ErrNoPageInfo = errors.New("no pageInfo in response")
+
+// PageInfo is the pageInfo object of a Relay style cursor connection.
+// Embed it in response structs rather than declaring it again:
+//
+//	var resp struct {
+//	    Issues struct {
+//	        Nodes    []Issue
+//	        PageInfo gographql.PageInfo
+//	    }
+//	}
+type PageInfo struct {
+	HasNextPage     bool   `json:"hasNextPage"`
+	HasPreviousPage bool   `json:"hasPreviousPage"`
+	StartCursor     string `json:"startCursor"`
+	EndCursor       string `json:"endCursor"`
+}
+
+// PageInfo returns the pageInfo of the connection at path, which uses the
+// syntax of GetPath and may also point at the pageInfo object itself. With
+// an empty path, the response data is searched for a pageInfo object at
+// any depth, and finding several is an error.
+//
+//	info, err := resp.PageInfo("data.repository.issues")
+//	info, err := resp.PageInfo("")
+func (r RawResponse) PageInfo(path string) (PageInfo, error) {
+	v, err := decodeValue(r)
+	if err != nil {
+		return PageInfo{}, errors.Join(ErrDecodingResponse, err)
+	}
+	var found interface{}
+	if path == "" {
+		match, err := findOnePageInfo(v)
+		if err != nil {
+			return PageInfo{}, err
+		}
+		found = match.value
+	} else {
+		segs, err := parsePath(path)
+		if err != nil {
+			return PageInfo{}, err
+		}
+		value, _ := getPath(v, segs)
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return PageInfo{}, fmt.Errorf("%w at %s", ErrNoPageInfo, path)
+		}
+		if pi, ok := obj["pageInfo"]; ok {
+			found = pi
+		} else if _, ok := obj["hasNextPage"]; ok {
+			found = obj
+		} else {
+			return PageInfo{}, fmt.Errorf("%w at %s", ErrNoPageInfo, path)
+		}
+	}
+	b, err := json.Marshal(found)
+	if err != nil {
+		return PageInfo{}, err
+	}
+	var info PageInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		return PageInfo{}, errors.Join(ErrDecodingResponse, err)
+	}
+	return info, nil
+}
+
+type pageInfoMatch struct {
+	path  string
+	value interface{}
+	// conn is the connection holding the pageInfo.
+	conn interface{}
+}
+
+// findOnePageInfo returns the only pageInfo object in the data of the
+// response v.
+func findOnePageInfo(v interface{}) (pageInfoMatch, error) {
+	root, _ := v.(map[string]interface{})
+	matches := findPageInfo(root["data"], "data", nil)
+	switch len(matches) {
+	case 0:
+		return pageInfoMatch{}, ErrNoPageInfo
+	case 1:
+		return matches[0], nil
+	}
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.path
+	}
+	sort.Strings(paths)
+	return pageInfoMatch{}, fmt.Errorf("several pageInfo objects in response, choose a path: %s", strings.Join(paths, ", "))
+}
+
+// findPageInfo returns the pageInfo objects in v, whose path is path.
+func findPageInfo(v interface{}, path string, matches []pageInfoMatch) []pageInfoMatch {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if _, ok := value.(map[string]interface{}); ok && key == "pageInfo" {
+				matches = append(matches, pageInfoMatch{path: path + ".pageInfo", value: value, conn: node})
+				continue
+			}
+			matches = findPageInfo(value, path+"."+key, matches)
+		}
+	case []interface{}:
+		for i, value := range node {
+			matches = findPageInfo(value, fmt.Sprintf("%s.%d", path, i), matches)
+		}
+	}
+	return matches
+}
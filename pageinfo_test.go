@@ -0,0 +1,55 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRawResponsePageInfo(t *testing.T) {
+	is := is.New(t)
+	resp := RawResponse(`{"data":{"viewer":{"repositories":{"nodes":[],"pageInfo":{"hasNextPage":true,"endCursor":"abc","startCursor":"aaa"}}}}}`)
+
+	info, err := resp.PageInfo("")
+	is.NoErr(err)
+	is.Equal(info, PageInfo{HasNextPage: true, StartCursor: "aaa", EndCursor: "abc"})
+
+	info, err = resp.PageInfo("data.viewer.repositories")
+	is.NoErr(err)
+	is.Equal(info.EndCursor, "abc")
+	info, err = resp.PageInfo("data.viewer.repositories.pageInfo")
+	is.NoErr(err)
+	is.Equal(info.EndCursor, "abc")
+
+	_, err = resp.PageInfo("data.viewer")
+	is.True(errors.Is(err, ErrNoPageInfo))
+	_, err = RawResponse(`{"data":{"a":1}}`).PageInfo("")
+	is.True(errors.Is(err, ErrNoPageInfo))
+
+	two := RawResponse(`{"data":{"a":{"pageInfo":{}},"b":[{"pageInfo":{}}]}}`)
+	_, err = two.PageInfo("")
+	is.Equal(err.Error(), "several pageInfo objects in response, choose a path: data.a.pageInfo, data.b.0.pageInfo")
+}
+
+func TestPaginateFindsConnection(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"org":{"team":{"members":{"nodes":[{"id":1}],"pageInfo":{"hasNextPage":false,"endCursor":"1"}}}}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	p := NewClient(srv.URL).Paginate(NewRequest(`{ org { team { members { nodes { id } pageInfo { hasNextPage endCursor } } } } }`), "")
+	is.True(p.Next(ctx))
+	is.Equal(len(p.Nodes()), 1)
+	is.Equal(p.PageInfo().EndCursor, "1")
+	is.True(!p.Next(ctx))
+	is.NoErr(p.Err())
+}
@@ -0,0 +1,348 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPaginationLimit pagination stopped at a limit set with WithMaxItems,
+// WithMaxPages or WithMaxDuration before reaching the last page.
+var ErrPaginationLimit = errors.New("pagination limit reached")
+
+// PaginationLimitError reports the limit that stopped a Paginator. It
+// matches ErrPaginationLimit with errors.Is.
+type PaginationLimitError struct {
+	// Limit is "items", "pages" or "duration".
+	Limit   string
+	Pages   int
+	Items   int
+	Elapsed time.Duration
+}
+
+func (e *PaginationLimitError) Error() string {
+	return fmt.Sprintf("%v: %s, after %d pages and %d items in %v", ErrPaginationLimit, e.Limit, e.Pages, e.Items, e.Elapsed)
+}
+
+func (e *PaginationLimitError) Unwrap() error {
+	return ErrPaginationLimit
+}
+
+// PaginateOption configures Client.Paginate.
+type PaginateOption func(*Paginator)
+
+// WithCursorVariable sets the variable receiving the end cursor of the
+// previous page, "after" by default.
+func WithCursorVariable(name string) PaginateOption {
+	return func(p *Paginator) {
+		p.cursorVar = name
+	}
+}
+
+// WithMaxItems stops pagination after n items. The page reaching the
+// limit is truncated to it.
+func WithMaxItems(n int) PaginateOption {
+	return func(p *Paginator) {
+		p.maxItems = n
+	}
+}
+
+// WithMaxPages stops pagination after n pages.
+func WithMaxPages(n int) PaginateOption {
+	return func(p *Paginator) {
+		p.maxPages = n
+	}
+}
+
+// WithMaxDuration stops pagination once d has elapsed since the first
+// page was requested, cancelling a page in flight.
+func WithMaxDuration(d time.Duration) PaginateOption {
+	return func(p *Paginator) {
+		p.maxDuration = d
+	}
+}
+
+// WithPrefetch fetches up to n pages ahead in the background while the
+// current page is processed, hiding the latency of each request in long
+// exports. Prefetching uses the context of the first call to Next; call
+// Paginator.Close when leaving the loop early.
+func WithPrefetch(n int) PaginateOption {
+	return func(p *Paginator) {
+		p.prefetch = n
+	}
+}
+
+// Paginator iterates over the pages of a Relay style cursor connection.
+// Make one with Client.Paginate.
+type Paginator struct {
+	client      *Client
+	req         *Request
+	path        string
+	cursorVar   string
+	maxItems    int
+	maxPages    int
+	maxDuration time.Duration
+	prefetch    int
+
+	start   time.Time
+	pages   int
+	items   int
+	cursor  string
+	hasNext bool
+	resp    RawResponse
+	info    PageInfo
+	nodes   []json.RawMessage
+	err     error
+	// ahead receives the prefetched pages.
+	ahead       chan page
+	stopFetcher context.CancelFunc
+}
+
+// page is a fetched page of the connection.
+type page struct {
+	raw  RawResponse
+	conn *connection
+	err  error
+}
+
+// Paginate returns a Paginator running req once per page of the
+// connection at path, with the cursor variable set to the end cursor of
+// the previous page. Paths use the syntax of RawResponse.GetPath, so
+// most begin with "data"; an empty path finds the connection holding the
+// only pageInfo of the response. The connection must select
+// pageInfo { hasNextPage endCursor }, and its items as nodes or
+// edges { node }. Once a limit is reached, Next returns false and Err an
+// error matching ErrPaginationLimit.
+//
+//	p := client.Paginate(req, "data.repository.issues", gographql.WithMaxPages(50))
+//	for p.Next(ctx) {
+//	    for _, node := range p.Nodes() {
+//	        ...
+//	    }
+//	}
+//	if err := p.Err(); err != nil {
+//	    ...
+//	}
+func (c *Client) Paginate(req *Request, path string, opts ...PaginateOption) *Paginator {
+	p := &Paginator{client: c, req: req, path: path, cursorVar: "after", hasNext: true}
+	for _, optionFunc := range opts {
+		optionFunc(p)
+	}
+	return p
+}
+
+// Next fetches the next page, and reports whether there was one.
+func (p *Paginator) Next(ctx context.Context) bool {
+	p.resp, p.info, p.nodes = nil, PageInfo{}, nil
+	if p.err != nil || !p.hasNext {
+		p.Close()
+		return false
+	}
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	if limit := p.limit(); limit != "" {
+		p.err = p.limitError(limit)
+		p.Close()
+		return false
+	}
+	var pg page
+	if p.prefetch > 0 {
+		if p.ahead == nil {
+			p.startFetcher(ctx)
+		}
+		var ok bool
+		if pg, ok = <-p.ahead; !ok {
+			pg.err = errors.New("pagination: prefetching stopped")
+		}
+	} else {
+		ctx, cancel := p.deadline(ctx)
+		pg = p.fetch(ctx, p.cursor, p.pages == 0)
+		cancel()
+	}
+	if pg.err != nil {
+		if errors.Is(pg.err, context.DeadlineExceeded) && p.limit() == "duration" {
+			pg.err = p.limitError("duration")
+		}
+		p.err = pg.err
+		p.Close()
+		return false
+	}
+	nodes := pg.conn.items()
+	truncated := false
+	if p.maxItems > 0 && p.items+len(nodes) > p.maxItems {
+		nodes = nodes[:p.maxItems-p.items]
+		truncated = true
+	}
+	p.pages++
+	p.items += len(nodes)
+	p.hasNext = pg.conn.PageInfo.HasNextPage || truncated
+	if err := pg.conn.check(p.path); err != nil {
+		p.err = err
+		p.hasNext = false
+	}
+	p.cursor = pg.conn.PageInfo.EndCursor
+	p.resp, p.info, p.nodes = pg.raw, pg.conn.PageInfo, nodes
+	return true
+}
+
+// Close stops prefetching. It is only needed when leaving the loop
+// before Next returns false.
+func (p *Paginator) Close() {
+	if p.stopFetcher != nil {
+		p.stopFetcher()
+	}
+}
+
+// deadline bounds ctx by the WithMaxDuration limit, if any.
+func (p *Paginator) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.maxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, p.start.Add(p.maxDuration))
+}
+
+// fetch runs the request for the page after cursor, or the first page.
+func (p *Paginator) fetch(ctx context.Context, cursor string, first bool) page {
+	req := p.req.Clone()
+	if !first {
+		req.Var(p.cursorVar, cursor)
+	}
+	raw, err := p.client.RunRaw(ctx, req)
+	if err != nil {
+		return page{err: err}
+	}
+	conn, err := decodeConnection(raw, p.path)
+	return page{raw: raw, conn: conn, err: err}
+}
+
+// startFetcher fetches pages in order into p.ahead, staying within the
+// limits, until the last page or an error.
+func (p *Paginator) startFetcher(ctx context.Context) {
+	ctx, cancel := p.deadline(ctx)
+	p.stopFetcher = cancel
+	p.ahead = make(chan page, p.prefetch)
+	cursor, first := p.cursor, p.pages == 0
+	pages, items := p.pages, p.items
+	go func() {
+		defer close(p.ahead)
+		for {
+			pg := p.fetch(ctx, cursor, first)
+			select {
+			case p.ahead <- pg:
+			case <-ctx.Done():
+				return
+			}
+			if pg.err != nil || !pg.conn.PageInfo.HasNextPage || pg.conn.check(p.path) != nil {
+				return
+			}
+			pages++
+			items += len(pg.conn.items())
+			if (p.maxPages > 0 && pages >= p.maxPages) || (p.maxItems > 0 && items >= p.maxItems) {
+				return
+			}
+			cursor, first = pg.conn.PageInfo.EndCursor, false
+		}
+	}()
+}
+
+// Nodes returns the items of the current page.
+func (p *Paginator) Nodes() []json.RawMessage {
+	return p.nodes
+}
+
+// Response returns the whole response of the current page.
+func (p *Paginator) Response() RawResponse {
+	return p.resp
+}
+
+// PageInfo returns the pageInfo of the current page.
+func (p *Paginator) PageInfo() PageInfo {
+	return p.info
+}
+
+// Err returns the error that stopped pagination, if any.
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+// limit returns the limit reached, if any.
+func (p *Paginator) limit() string {
+	switch {
+	case p.maxItems > 0 && p.items >= p.maxItems:
+		return "items"
+	case p.maxPages > 0 && p.pages >= p.maxPages:
+		return "pages"
+	case p.maxDuration > 0 && time.Since(p.start) >= p.maxDuration:
+		return "duration"
+	}
+	return ""
+}
+
+func (p *Paginator) limitError(limit string) error {
+	return &PaginationLimitError{Limit: limit, Pages: p.pages, Items: p.items, Elapsed: time.Since(p.start)}
+}
+
+// connection is a Relay style cursor connection.
+type connection struct {
+	PageInfo PageInfo          `json:"pageInfo"`
+	Nodes    []json.RawMessage `json:"nodes"`
+	Edges    []struct {
+		Node json.RawMessage `json:"node"`
+	} `json:"edges"`
+}
+
+// items returns the nodes of the connection.
+func (conn *connection) items() []json.RawMessage {
+	if conn.Nodes != nil {
+		return conn.Nodes
+	}
+	var nodes []json.RawMessage
+	for _, edge := range conn.Edges {
+		nodes = append(nodes, edge.Node)
+	}
+	return nodes
+}
+
+// check reports a connection that cannot be followed.
+func (conn *connection) check(path string) error {
+	if conn.PageInfo.HasNextPage && conn.PageInfo.EndCursor == "" {
+		return fmt.Errorf("pagination: no endCursor at %s.pageInfo", path)
+	}
+	return nil
+}
+
+func decodeConnection(raw RawResponse, path string) (*connection, error) {
+	v, err := decodeValue(raw)
+	if err != nil {
+		return nil, errors.Join(ErrDecodingResponse, err)
+	}
+	var value interface{}
+	if path == "" {
+		match, err := findOnePageInfo(v)
+		if err != nil {
+			return nil, fmt.Errorf("pagination: %w", err)
+		}
+		value = match.conn
+	} else {
+		segs, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if value, ok = getPath(v, segs); !ok || value == nil {
+			return nil, fmt.Errorf("pagination: no connection at %s", path)
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var conn connection
+	if err := json.Unmarshal(b, &conn); err != nil {
+		return nil, errors.Join(ErrDecodingResponse, err)
+	}
+	return &conn, nil
+}
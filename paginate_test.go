@@ -0,0 +1,166 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// connectionServer serves a connection of total items, size per page,
+// with the cursor being the index of the next item.
+func connectionServer(t *testing.T, total, size int, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		var body struct {
+			Variables map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		start := 0
+		if after, ok := body.Variables["after"].(string); ok {
+			fmt.Sscan(after, &start)
+		}
+		var edges []string
+		end := start
+		for ; end < total && end < start+size; end++ {
+			edges = append(edges, fmt.Sprintf(`{"node":{"id":%d}}`, end))
+		}
+		fmt.Fprintf(w, `{"data":{"items":{"edges":[%s],"pageInfo":{"hasNextPage":%v,"endCursor":"%d"}}}}`, strings.Join(edges, ","), end < total, end)
+	}))
+	return srv, &requests
+}
+
+func TestPaginate(t *testing.T) {
+	is := is.New(t)
+	srv, requests := connectionServer(t, 5, 2, 0)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	p := NewClient(srv.URL).Paginate(NewRequest("query($after: String) { items(after: $after) { edges { node { id } } pageInfo { hasNextPage endCursor } } }"), "data.items")
+	var ids []string
+	for p.Next(ctx) {
+		for _, node := range p.Nodes() {
+			ids = append(ids, string(node))
+		}
+	}
+	is.NoErr(p.Err())
+	is.Equal(ids, []string{`{"id":0}`, `{"id":1}`, `{"id":2}`, `{"id":3}`, `{"id":4}`})
+	is.Equal(requests.Load(), int32(3))
+}
+
+func TestPaginateLimits(t *testing.T) {
+	is := is.New(t)
+	srv, _ := connectionServer(t, 100, 3, 0)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+	req := NewRequest("query($after: String) { items(after: $after) { ... } }")
+
+	count := func(p *Paginator) int {
+		n := 0
+		for p.Next(ctx) {
+			n += len(p.Nodes())
+		}
+		return n
+	}
+
+	p := client.Paginate(req, "data.items", WithMaxItems(7))
+	is.Equal(count(p), 7)
+	var limitErr *PaginationLimitError
+	is.True(errors.As(p.Err(), &limitErr))
+	is.Equal(limitErr.Limit, "items")
+	is.Equal(limitErr.Pages, 3)
+	is.True(errors.Is(p.Err(), ErrPaginationLimit))
+
+	p = client.Paginate(req, "data.items", WithMaxPages(2))
+	is.Equal(count(p), 6)
+	is.True(errors.As(p.Err(), &limitErr))
+	is.Equal(limitErr.Limit, "pages")
+
+	// the last page reaches the limit exactly: no error
+	p = client.Paginate(req, "data.items", WithMaxItems(100))
+	is.Equal(count(p), 100)
+	is.NoErr(p.Err())
+}
+
+func TestPaginateMaxDuration(t *testing.T) {
+	is := is.New(t)
+	srv, _ := connectionServer(t, 100, 1, 20*time.Millisecond)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	p := NewClient(srv.URL).Paginate(NewRequest("query { items { ... } }"), "data.items", WithMaxDuration(50*time.Millisecond))
+	n := 0
+	for p.Next(ctx) {
+		n++
+	}
+	is.True(n >= 1 && n <= 3)
+	var limitErr *PaginationLimitError
+	is.True(errors.As(p.Err(), &limitErr))
+	is.Equal(limitErr.Limit, "duration")
+}
+
+func TestPaginatePrefetch(t *testing.T) {
+	is := is.New(t)
+	srv, requests := connectionServer(t, 10, 2, 0)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+	req := NewRequest("query($after: String) { items(after: $after) { ... } }")
+
+	p := client.Paginate(req, "data.items", WithPrefetch(2))
+	is.True(p.Next(ctx))
+	for requests.Load() < 3 { // pages 2 and 3 are fetched while page 1 is processed
+		select {
+		case <-ctx.Done():
+			t.Fatal("pages not prefetched")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	n := len(p.Nodes())
+	for p.Next(ctx) {
+		n += len(p.Nodes())
+	}
+	is.NoErr(p.Err())
+	is.Equal(n, 10)
+	is.Equal(requests.Load(), int32(5))
+
+	requests.Store(0)
+	p = client.Paginate(req, "data.items", WithPrefetch(5), WithMaxPages(2))
+	n = 0
+	for p.Next(ctx) {
+		n += len(p.Nodes())
+	}
+	is.True(errors.Is(p.Err(), ErrPaginationLimit))
+	is.Equal(n, 4)
+	is.Equal(requests.Load(), int32(2))
+}
+
+func TestPaginateMissingConnection(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"other":{}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	p := NewClient(srv.URL).Paginate(NewRequest("query { other }"), "data.items")
+	is.True(!p.Next(ctx))
+	is.True(p.Err() != nil)
+}
@@ -0,0 +1,48 @@
+package gographql
+
+import "errors"
+
+// PartialDataError is returned instead of the GraphQL errors of a
+// response that also has data, when partial data is allowed. The data was
+// decoded into the response object, and the errors concern the fields
+// that could not be resolved, usually set to null.
+type PartialDataError struct {
+	// Errors are the errors of the response.
+	Errors GraphQLErrors
+	err    error
+}
+
+func (e *PartialDataError) Error() string {
+	return "partial data: " + e.err.Error()
+}
+
+// Unwrap returns the GraphQLErrors, or the *GroupedErrors with
+// WithErrorGrouping.
+func (e *PartialDataError) Unwrap() error {
+	return e.err
+}
+
+// IsPartialData reports whether err only reports errors alongside data,
+// so the response can still be used.
+func IsPartialData(err error) bool {
+	var partial *PartialDataError
+	return errors.As(err, &partial)
+}
+
+// AllowPartialData makes Run return a *PartialDataError, rather than the
+// GraphQL errors, when a response has both data and errors, so callers
+// can tell partial results, which they may use, from failed operations.
+// The data is decoded into the response object in both cases.
+func AllowPartialData() ClientOption {
+	return func(client *Client) {
+		client.partialData = true
+	}
+}
+
+// AllowPartialData is like the AllowPartialData client option for this
+// request only.
+func (req *Request) AllowPartialData() {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.partialData = true
+}
@@ -0,0 +1,55 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestAllowPartialData(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if string(b) == `{"query":"{ fail }","variables":null}`+"\n" {
+			io.WriteString(w, `{"data":null,"errors":[{"message":"down"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"user":{"name":"Mat","avatar":null}},"errors":[{"message":"avatar unavailable","path":["user","avatar"]}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp struct {
+		User struct{ Name string }
+	}
+	client := NewClient(srv.URL, AllowPartialData())
+	err := client.Run(ctx, NewRequest(`{ user { name avatar } }`), &resp)
+	is.True(IsPartialData(err))
+	is.Equal(err.Error(), "partial data: graphql: avatar unavailable")
+	is.Equal(resp.User.Name, "Mat")
+	var partial *PartialDataError
+	is.True(errors.As(err, &partial))
+	is.Equal(partial.Errors[0].Message, "avatar unavailable")
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+
+	// no data is not partial
+	err = client.Run(ctx, NewRequest(`{ fail }`), nil)
+	is.True(!IsPartialData(err))
+	is.Equal(err.Error(), "graphql: down")
+
+	// per request
+	req := NewRequest(`{ user { name avatar } }`)
+	err = NewClient(srv.URL).Run(ctx, req, nil)
+	is.True(!IsPartialData(err))
+	req.AllowPartialData()
+	err = NewClient(srv.URL).Run(ctx, req, nil)
+	is.True(IsPartialData(err))
+}
@@ -0,0 +1,115 @@
+package gographql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Paths select values inside a decoded JSON document. A path is a dot
+// separated list of segments, each being an object key, an array index,
+// "#" for every element of an array or "*" for every value of an object:
+//
+//	user.email
+//	users.#.ssn
+//	orders.0.lines.#.price
+type pathSegment struct {
+	key      string
+	index    int
+	wildcard bool
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, nil
+	}
+	parts := strings.Split(path, ".")
+	segs := make([]pathSegment, len(parts))
+	for i, part := range parts {
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("invalid path %q: empty segment", path)
+		case part == "#" || part == "*":
+			segs[i] = pathSegment{wildcard: true}
+		default:
+			segs[i] = pathSegment{key: part, index: -1}
+			if n, err := strconv.Atoi(part); err == nil && n >= 0 {
+				segs[i].index = n
+			}
+		}
+	}
+	return segs, nil
+}
+
+// decodeValue decodes the JSON document b into its generic
+// representation for the path functions. Numbers are kept as json.Number
+// rather than rounded through float64.
+func decodeValue(b []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// transformPath calls fn for every value in v matched by segs, replacing
+// the value with the result. prefix is the response path leading to v.
+func transformPath(v interface{}, segs []pathSegment, prefix []interface{}, fn func(path []interface{}, v interface{}) (interface{}, error)) (interface{}, error) {
+	if len(segs) == 0 {
+		return fn(append([]interface{}{}, prefix...), v)
+	}
+	seg, rest := segs[0], segs[1:]
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if !seg.wildcard && key != seg.key {
+				continue
+			}
+			out, err := transformPath(value, rest, append(prefix, key), fn)
+			if err != nil {
+				return nil, err
+			}
+			node[key] = out
+		}
+	case []interface{}:
+		for i, value := range node {
+			if !seg.wildcard && i != seg.index {
+				continue
+			}
+			out, err := transformPath(value, rest, append(prefix, i), fn)
+			if err != nil {
+				return nil, err
+			}
+			node[i] = out
+		}
+	}
+	return v, nil
+}
+
+// getPath returns the value in v matched by segs. Paths containing
+// wildcards return every match as a slice.
+func getPath(v interface{}, segs []pathSegment) (interface{}, bool) {
+	wildcard := false
+	for _, seg := range segs {
+		wildcard = wildcard || seg.wildcard
+	}
+	var matches []interface{}
+	transformPath(v, segs, nil, func(_ []interface{}, value interface{}) (interface{}, error) {
+		matches = append(matches, value)
+		return value, nil
+	})
+	if wildcard {
+		if matches == nil {
+			matches = []interface{}{}
+		}
+		return matches, true
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
@@ -0,0 +1,107 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrPipeValueMissing the path piped into the next request matched no value.
+var ErrPipeValueMissing = errors.New("piped value missing")
+
+// Pipeline is a sequence of dependent requests where a value from each
+// response is passed as a variable to the next. Make one with Pipe and
+// run it with Client.RunPipeline.
+type Pipeline struct {
+	first *Request
+	steps []pipeStep
+}
+
+type pipeStep struct {
+	path     string
+	req      *Request
+	variable string
+}
+
+// PipelineError reports the step of a pipeline that failed. Step 0 is the
+// first request.
+type PipelineError struct {
+	Step int
+	Err  error
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("pipeline step %d: %v", e.Step, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// Pipe returns a pipeline running first, then next with variable set to
+// the value at path in the first response. Paths use the syntax of
+// RawResponse.GetPath, so most begin with "data":
+//
+//	p := gographql.Pipe(userReq, "data.user.id", ordersReq, "userId").
+//	    Then("data.orders.#.id", invoicesReq, "orderIds")
+//	err := client.RunPipeline(ctx, p, &invoices)
+func Pipe(first *Request, path string, next *Request, variable string) *Pipeline {
+	p := &Pipeline{first: first}
+	return p.Then(path, next, variable)
+}
+
+// Then appends next to the pipeline, with variable set to the value at
+// path in the response of the previous request.
+func (p *Pipeline) Then(path string, next *Request, variable string) *Pipeline {
+	p.steps = append(p.steps, pipeStep{path: path, req: next, variable: variable})
+	return p
+}
+
+// RunPipeline runs the requests of p in order and decodes the data of the
+// last response into resp. It stops at the first failed request or
+// missing value, returning a *PipelineError. The requests of p are not
+// modified.
+func (c *Client) RunPipeline(ctx context.Context, p *Pipeline, resp interface{}) error {
+	req := p.first
+	for i := 0; ; i++ {
+		var out interface{}
+		if i == len(p.steps) {
+			out = resp
+		}
+		meta := responseMeta{keepBody: true}
+		if err := c.run(ctx, req, out, &meta); err != nil {
+			return &PipelineError{Step: i, Err: err}
+		}
+		if i == len(p.steps) {
+			return nil
+		}
+		step := p.steps[i]
+		value, err := pipeValue(meta.body, step.path)
+		if err != nil {
+			return &PipelineError{Step: i, Err: err}
+		}
+		req = step.req.Clone()
+		req.Var(step.variable, value)
+	}
+}
+
+func pipeValue(body []byte, path string) (interface{}, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	// numbers are kept as they are, so large IDs keep their precision
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Join(ErrDecodingResponse, err)
+	}
+	value, ok := getPath(v, segs)
+	if !ok || value == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPipeValueMissing, path)
+	}
+	return value, nil
+}
@@ -0,0 +1,80 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestPipeline(t *testing.T) {
+	is := is.New(t)
+	var vars []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		vars = append(vars, body.Variables)
+		switch {
+		case strings.Contains(body.Query, "me"):
+			io.WriteString(w, `{"data":{"me":{"id":"u1","manager":null}}}`)
+		case strings.Contains(body.Query, "orders"):
+			io.WriteString(w, `{"data":{"orders":[{"id":"o1"},{"id":"o2"}]}}`)
+		default:
+			io.WriteString(w, `{"data":{"total":42}}`)
+		}
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	orders := NewRequest("query ($userId: ID!) { orders(userId: $userId) { id } }")
+	p := Pipe(NewRequest("{ me { id manager { id } } }"), "data.me.id", orders, "userId").
+		Then("data.orders.#.id", NewRequest("query ($ids: [ID!]) { total(ids: $ids) }"), "ids")
+	var resp struct{ Total int }
+	is.NoErr(client.RunPipeline(ctx, p, &resp))
+	is.Equal(resp.Total, 42)
+	is.Equal(vars[1]["userId"], "u1")
+	is.Equal(vars[2]["ids"], []interface{}{"o1", "o2"})
+	is.Equal(len(orders.Vars()), 0) // requests are not modified
+
+	vars = nil
+	p = Pipe(NewRequest("{ me { id manager { id } } }"), "data.me.manager.id", orders, "userId")
+	err := client.RunPipeline(ctx, p, nil)
+	is.True(errors.Is(err, ErrPipeValueMissing))
+	var perr *PipelineError
+	is.True(errors.As(err, &perr))
+	is.Equal(perr.Step, 0)
+	is.Equal(len(vars), 1) // short-circuited
+}
+
+func TestPipelineLargeNumbers(t *testing.T) {
+	is := is.New(t)
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		if strings.Contains(string(b), "account") {
+			sent = string(b)
+		}
+		io.WriteString(w, `{"data":{"me":{"accountId":9007199254740993}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	p := Pipe(NewRequest("{ me { accountId } }"), "data.me.accountId",
+		NewRequest("query ($id: ID!) { account(id: $id) { id } }"), "id")
+	is.NoErr(NewClient(srv.URL).RunPipeline(ctx, p, nil))
+	is.True(strings.Contains(sent, `"id":9007199254740993`)) // not rounded to a float64
+}
@@ -0,0 +1,88 @@
+package gographql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not returned to
+// their pool, so a few large bodies do not pin memory.
+const maxPooledBuffer = 256 << 10
+
+// bodyBufferPool holds the buffers request bodies are encoded into.
+var bodyBufferPool = sync.Pool{
+	New: func() interface{} { return new(bodyBuffer) },
+}
+
+// bodyBuffer is a pooled request body buffer. It goes back to the pool
+// once released by its owner and closed by every request body reading
+// it, since transports may read a body after the request completed.
+type bodyBuffer struct {
+	bytes.Buffer
+	refs atomic.Int32
+}
+
+// getBodyBuffer returns an empty buffer from the pool, owned by the
+// caller until released.
+func getBodyBuffer() *bodyBuffer {
+	b := bodyBufferPool.Get().(*bodyBuffer)
+	b.refs.Store(1)
+	return b
+}
+
+// release drops a reference to b, returning it to the pool with the last.
+func (b *bodyBuffer) release() {
+	if b.refs.Add(-1) != 0 || b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bodyBufferPool.Put(b)
+}
+
+// reader returns a request body reading b, which holds a reference to b
+// until closed.
+func (b *bodyBuffer) reader() io.ReadCloser {
+	b.refs.Add(1)
+	return &bodyReader{Reader: bytes.NewReader(b.Bytes()), buf: b}
+}
+
+// bodyReader is a request body over a bodyBuffer.
+type bodyReader struct {
+	*bytes.Reader
+	buf  *bodyBuffer
+	once sync.Once
+}
+
+func (r *bodyReader) Close() error {
+	r.once.Do(r.buf.release)
+	return nil
+}
+
+// responseBufferPool holds the buffers responses are read into when
+// they are not decoded as they are read.
+var responseBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getResponseBuffer returns an empty buffer from the pool.
+func getResponseBuffer() *bytes.Buffer {
+	return responseBufferPool.Get().(*bytes.Buffer)
+}
+
+// putResponseBuffer returns buf to the pool. It must not be used after.
+func putResponseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	responseBufferPool.Put(buf)
+}
+
+// gzipWriterPool holds the writers compressing request bodies, which are
+// expensive to allocate.
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
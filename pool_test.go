@@ -0,0 +1,26 @@
+package gographql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBodyBuffer(t *testing.T) {
+	is := is.New(t)
+	buf := getBodyBuffer()
+	buf.WriteString(`{"query":"{ a }"}`)
+	r := buf.reader()
+	buf.release()
+
+	// the request body still reads the buffer after it is released
+	is.Equal(buf.refs.Load(), int32(1))
+	b, err := io.ReadAll(r)
+	is.NoErr(err)
+	is.Equal(string(b), `{"query":"{ a }"}`)
+	is.NoErr(r.Close())
+	is.NoErr(r.Close()) // closing again does not release it twice
+	is.Equal(buf.refs.Load(), int32(0))
+	is.Equal(buf.Len(), 0)
+}
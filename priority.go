@@ -0,0 +1,83 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBudgetExceeded not enough time is left before the context deadline to
+// start an operation of the request's priority.
+var ErrBudgetExceeded = errors.New("deadline budget exceeded")
+
+// Priority ranks operations when the time left to serve an upstream
+// request is short. The zero value is PriorityNormal.
+type Priority int
+
+// Priorities, from least to most important.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+type priorityKey struct{}
+
+// ContextWithPriority returns a copy of ctx carrying the priority of the
+// operations run with it.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority carried by ctx, PriorityNormal
+// if none.
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// DeadlinePolicy decides how operations use the time left before their
+// context deadline, by priority. Contexts without a deadline are not
+// affected.
+type DeadlinePolicy struct {
+	// MinRemaining is the least time that must be left for an operation
+	// of the priority to start. Operations with less time left fail
+	// with ErrBudgetExceeded instead of starting a doomed request.
+	MinRemaining map[Priority]time.Duration
+	// Share is the fraction, between 0 and 1, of the time left that an
+	// operation of the priority may use, leaving the rest for the
+	// caller's more important work. Zero means all of it.
+	Share map[Priority]float64
+}
+
+// WithDeadlinePolicy shrinks or skips operations according to p when the
+// caller's deadline is short.
+//
+//	client := gographql.NewClient(endpoint, gographql.WithDeadlinePolicy(gographql.DeadlinePolicy{
+//	    MinRemaining: map[gographql.Priority]time.Duration{gographql.PriorityLow: 200 * time.Millisecond},
+//	    Share:        map[gographql.Priority]float64{gographql.PriorityLow: 0.5},
+//	}))
+//	err := client.Run(gographql.ContextWithPriority(ctx, gographql.PriorityLow), req, &resp)
+func WithDeadlinePolicy(p DeadlinePolicy) ClientOption {
+	return func(client *Client) {
+		client.deadlines = &p
+	}
+}
+
+// budget applies the policy to ctx.
+func (p *DeadlinePolicy) budget(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}, nil
+	}
+	priority := PriorityFromContext(ctx)
+	remaining := time.Until(deadline)
+	if remaining < p.MinRemaining[priority] {
+		return ctx, func() {}, ErrBudgetExceeded
+	}
+	if share := p.Share[priority]; share > 0 && share < 1 {
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(float64(remaining)*share))
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
@@ -0,0 +1,45 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDeadlinePolicy(t *testing.T) {
+	is := is.New(t)
+	var budgets []time.Duration
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		deadline, _ := r.Context().Deadline()
+		budgets = append(budgets, time.Until(deadline))
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client := NewClient(srv.URL, WithHTTPClient(&http.Client{Transport: transport}), WithDeadlinePolicy(DeadlinePolicy{
+		MinRemaining: map[Priority]time.Duration{PriorityLow: 500 * time.Millisecond},
+		Share:        map[Priority]float64{PriorityLow: 0.5},
+	}))
+	req := NewRequest("query { a }")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err := client.Run(ContextWithPriority(ctx, PriorityLow), req, nil)
+	is.True(errors.Is(err, ErrBudgetExceeded))
+	is.NoErr(client.Run(ctx, req, nil)) // normal priority is not skipped
+	is.Equal(len(budgets), 1)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	is.NoErr(client.Run(ContextWithPriority(ctx, PriorityLow), req, nil))
+	is.Equal(len(budgets), 2)
+	is.True(budgets[1] <= 500*time.Millisecond) // low priority gets half the time left
+}
@@ -0,0 +1,62 @@
+package gographql
+
+import (
+	"io"
+	"os"
+)
+
+// UploadProgress is called as the content of file is sent, with the
+// number of bytes written so far and the size of the file, or -1 if it is
+// not known.
+type UploadProgress func(file File, written, size int64)
+
+// OnUploadProgress registers fn to report the progress of the file
+// uploads of the request. It is called from the goroutine writing the
+// request body.
+//
+//	req.OnUploadProgress(func(f gographql.File, written, size int64) {
+//	    bar.Set(f.Name, written, size)
+//	})
+func (req *Request) OnUploadProgress(fn UploadProgress) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.progress = fn
+}
+
+// progressReader reports the bytes read from r.
+type progressReader struct {
+	r        io.Reader
+	file     File
+	size     int64
+	written  int64
+	progress UploadProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.written += int64(n)
+		p.progress(p.file, p.written, p.size)
+	}
+	return n, err
+}
+
+// readerSize returns the number of bytes left in r, or -1 if it cannot be
+// known without reading it.
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
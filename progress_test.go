@@ -0,0 +1,44 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestUploadProgress(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(r.ParseMultipartForm(1 << 20))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	last := map[string][2]int64{}
+	client := NewClient(srv.URL, UseMultipartForm())
+	req := NewRequest("query {}")
+	req.File("a", "a.txt", strings.NewReader(strings.Repeat("a", 100_000)))
+	req.FileFunc("b", "b.txt", func() (io.ReadCloser, error) {
+		return io.NopCloser(io.LimitReader(zeros{}, 1234)), nil
+	})
+	req.OnUploadProgress(func(f File, written, size int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		last[f.Name] = [2]int64{written, size}
+	})
+	is.NoErr(client.Run(ctx, req, nil))
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(last["a.txt"], [2]int64{100_000, 100_000})
+	is.Equal(last["b.txt"], [2]int64{1234, -1})
+}
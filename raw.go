@@ -0,0 +1,47 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RawResponse is the undecoded body of a GraphQL response.
+type RawResponse json.RawMessage
+
+// RunRaw executes the query and returns the raw response body, for quick
+// scripts that query it with GetPath instead of declaring structs.
+// The raw response is also returned alongside GraphQL errors.
+func (c *Client) RunRaw(ctx context.Context, req *Request) (RawResponse, error) {
+	meta := responseMeta{keepBody: true}
+	err := c.run(ctx, req, nil, &meta)
+	return RawResponse(meta.body), err
+}
+
+// GetPath returns the value at path in the response, decoded into its
+// generic JSON representation, with numbers as json.Number so that large
+// integers keep their precision. Paths are dot separated and start at the
+// top of the response, so most begin with "data". "#" selects every
+// element of an array, and paths containing it return a slice of matches:
+//
+//	resp.GetPath("data.user.name")         // "Mat"
+//	resp.GetPath("data.user.orders.#.id")  // []interface{}{"1", "2"}
+//	resp.GetPath("errors.0.message")
+//
+// GetPath returns nil if nothing matches or the path is invalid.
+func (r RawResponse) GetPath(path string) interface{} {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil
+	}
+	v, err := decodeValue(r)
+	if err != nil {
+		return nil
+	}
+	out, _ := getPath(v, segs)
+	return out
+}
+
+// String returns the response body.
+func (r RawResponse) String() string {
+	return string(r)
+}
@@ -0,0 +1,48 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRunRaw(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"name":"Mat","balance":9007199254740993,"orders":[{"id":"1"},{"id":"2"}]}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	resp, err := client.RunRaw(ctx, NewRequest("query {}"))
+	is.NoErr(err)
+	is.Equal(resp.GetPath("data.user.name"), "Mat")
+	is.Equal(resp.GetPath("data.user.orders.#.id"), []interface{}{"1", "2"})
+	is.Equal(resp.GetPath("data.user.orders.1.id"), "2")
+	is.Equal(resp.GetPath("data.user.balance"), json.Number("9007199254740993"))
+	is.Equal(resp.GetPath("data.user.missing"), nil)
+	is.Equal(resp.GetPath("data..user"), nil)
+}
+
+func TestRunRawErrors(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"errors":[{"message":"boom"}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	resp, err := client.RunRaw(ctx, NewRequest("query {}"))
+	is.Equal(err.Error(), "graphql: boom")
+	is.Equal(resp.GetPath("errors.0.message"), "boom")
+}
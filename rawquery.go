@@ -0,0 +1,56 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UseRawQueryBody posts the query document as the request body with the
+// application/graphql content type, and the variables and operationName
+// as URL parameters, for servers accepting only this form. Requests sent
+// with GET are not affected, and bodies are never compressed.
+func UseRawQueryBody() ClientOption {
+	return func(client *Client) {
+		client.rawQueryBody = true
+	}
+}
+
+// runWithRawQuery posts the query of req as an application/graphql body.
+func (c *Client) runWithRawQuery(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	if len(req.vars) > 0 {
+		vars, err := json.Marshal(req.vars)
+		if err != nil {
+			return errors.Join(ErrEncodingRequestBody, err)
+		}
+		q.Set("variables", string(vars))
+	}
+	if _, name := operationInfo(req.q); name != "" {
+		q.Set("operationName", name)
+	}
+	u.RawQuery = q.Encode()
+	if c.debug(ctx) {
+		c.log.Debugf("variables: %+v", req.vars)
+		c.log.Debugf("query: %s", req.q)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(req.q))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/graphql; charset=utf-8")
+	r.Header.Set("Accept", acceptGraphQLResponse)
+	c.setHeaders(ctx, r, req)
+	return c.doHTTP(ctx, req, r, resp, meta)
+}
@@ -0,0 +1,36 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestUseRawQueryBody(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, http.MethodPost)
+		is.Equal(r.Header.Get("Content-Type"), "application/graphql; charset=utf-8")
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(b), `query Q($id: ID) { a(id: $id) }`)
+		is.Equal(r.URL.Query().Get("variables"), `{"id":"1"}`)
+		is.Equal(r.URL.Query().Get("operationName"), "Q")
+		io.WriteString(w, `{"data":{"a":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseRawQueryBody())
+	req := NewRequest(`query Q($id: ID) { a(id: $id) }`)
+	req.Var("id", "1")
+	var resp struct{ A string }
+	is.NoErr(client.Run(ctx, req, &resp))
+	is.Equal(resp.A, "ok")
+}
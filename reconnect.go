@@ -0,0 +1,165 @@
+package gographql
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// ReconnectPolicy configures how dropped subscription connections are
+// re-established, see WithSubscriptionReconnect.
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of reconnection attempts after a drop
+	// before the subscriptions end with the error. Zero means no limit.
+	MaxAttempts int
+	// MinBackoff is the delay before the first attempt, doubled after
+	// every failed one up to MaxBackoff. They default to one and thirty
+	// seconds. Delays are randomized down to half their value, so that
+	// clients dropped together don't reconnect together.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnEvent, if not nil, is called when the connection drops and after
+	// every reconnection attempt, before the subscriptions are replayed.
+	OnEvent func(ReconnectEvent)
+}
+
+// ReconnectEvent reports a dropped subscription connection or an attempt
+// to re-establish it.
+type ReconnectEvent struct {
+	// Attempt is zero when the connection dropped, and then counts the
+	// reconnection attempts from one.
+	Attempt int
+	// Err is why the connection dropped or the attempt failed, nil once
+	// reconnected.
+	Err error
+	// Subscriptions is the number of active subscriptions to replay.
+	Subscriptions int
+}
+
+// WithSubscriptionReconnect makes subscriptions survive dropped
+// connections: the client reconnects with exponential backoff and
+// replays the active subscriptions on the new connection, without closing
+// their channels. Results sent by the server while disconnected are lost.
+// Errors the server reports itself, GraphQL errors and close codes 4400
+// to 4499 such as 4403 Forbidden, still end the subscriptions.
+//
+//	gographql.WithSubscriptionReconnect(gographql.ReconnectPolicy{
+//	    MaxAttempts: 10,
+//	    OnEvent: func(e gographql.ReconnectEvent) {
+//	        log.Printf("subscriptions: attempt %d: %v", e.Attempt, e.Err)
+//	    },
+//	})
+func WithSubscriptionReconnect(policy ReconnectPolicy) ClientOption {
+	return func(client *Client) {
+		if policy.MinBackoff <= 0 {
+			policy.MinBackoff = time.Second
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = 30 * time.Second
+		}
+		client.reconnect = &policy
+	}
+}
+
+// reconnectable reports whether a connection failing with err may be
+// re-established.
+func reconnectable(err error) bool {
+	var gqlErrs GraphQLErrors
+	if errors.As(err, &gqlErrs) {
+		return false
+	}
+	var closeErr *WebSocketCloseError
+	if errors.As(err, &closeErr) {
+		return closeErr.Code < 4400 || closeErr.Code > 4499
+	}
+	return true
+}
+
+func (p *ReconnectPolicy) event(e ReconnectEvent) {
+	if p.OnEvent != nil {
+		p.OnEvent(e)
+	}
+}
+
+// delay returns the randomized delay before the given attempt.
+func (p *ReconnectPolicy) delay(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// resubscribe dials new connections until subs, the subscriptions of a
+// connection that dropped with cause, can be replayed.
+func (c *Client) resubscribe(subs map[string]*subscription, cause error) {
+	p := c.reconnect
+	p.event(ReconnectEvent{Err: cause, Subscriptions: len(subs)})
+	for attempt := 1; ; attempt++ {
+		live := liveSubscriptions(subs)
+		if len(live) == 0 {
+			return
+		}
+		if p.MaxAttempts > 0 && attempt > p.MaxAttempts {
+			endSubscriptions(live, cause)
+			return
+		}
+		timer := time.NewTimer(p.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-live[0].ctx.Done():
+			timer.Stop()
+			attempt--
+			continue
+		}
+		c.emit(Event{Type: EventReconnecting, Attempt: attempt, Err: cause})
+		conn, err := c.subscriptionConn(live[0].ctx, live[0].req)
+		if err == nil {
+			p.event(ReconnectEvent{Attempt: attempt, Subscriptions: len(live)})
+			if err = conn.resume(live); err == nil {
+				c.emit(Event{Type: EventReconnected, Attempt: attempt, URL: conn.url})
+				return
+			}
+		} else {
+			p.event(ReconnectEvent{Attempt: attempt, Err: err, Subscriptions: len(live)})
+		}
+		cause = err
+		if !reconnectable(err) {
+			endSubscriptions(live, err)
+			return
+		}
+	}
+}
+
+// resume starts subs on the connection. If the connection fails while
+// they are sent, its read loop replays them again.
+func (conn *subConn) resume(subs []*subscription) error {
+	ids, err := conn.register(subs...)
+	if err != nil {
+		return err
+	}
+	for i, sub := range subs {
+		if err := conn.sendSubscribe(sub.ctx, ids[i], sub.req); err != nil {
+			conn.ws.rwc.Close()
+			break
+		}
+	}
+	return nil
+}
+
+func liveSubscriptions(subs map[string]*subscription) []*subscription {
+	var live []*subscription
+	for _, sub := range subs {
+		if sub.ctx.Err() == nil {
+			live = append(live, sub)
+		}
+	}
+	return live
+}
+
+func endSubscriptions(subs []*subscription, err error) {
+	for _, sub := range subs {
+		sub.end(err)
+	}
+}
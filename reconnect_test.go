@@ -0,0 +1,116 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSubscribeReconnect(t *testing.T) {
+	is := is.New(t)
+	var mu sync.Mutex
+	connections := 0
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		mu.Lock()
+		connections++
+		n := connections
+		mu.Unlock()
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(msg.Type, "subscribe")
+		if n == 1 {
+			writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"n":1}}}`)
+			return // drop the connection
+		}
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"n":2}}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"complete"}`)
+		readWSMessage(conn) // until closed
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var events []ReconnectEvent
+	client := NewClient(srv.URL, WithSubscriptionReconnect(ReconnectPolicy{
+		MinBackoff: time.Millisecond,
+		OnEvent: func(e ReconnectEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	}))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { n }"))
+	is.NoErr(err)
+	var got []string
+	for p := range payloads {
+		got = append(got, string(p.Data))
+	}
+	is.NoErr(<-errs)
+	is.Equal(got, []string{`{"n":1}`, `{"n":2}`})
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(len(events), 2)
+	is.Equal(events[0].Attempt, 0)
+	is.True(events[0].Err != nil)
+	is.Equal(events[0].Subscriptions, 1)
+	is.Equal(events[1].Attempt, 1)
+	is.NoErr(events[1].Err)
+}
+
+func TestSubscribeReconnectGivesUp(t *testing.T) {
+	is := is.New(t)
+	var mu sync.Mutex
+	connections := 0
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		mu.Lock()
+		connections++
+		n := connections
+		mu.Unlock()
+		if n > 1 {
+			conn.close(4403, "Forbidden")
+			return
+		}
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var attempts int
+	client := NewClient(srv.URL, WithSubscriptionReconnect(ReconnectPolicy{
+		MinBackoff: time.Millisecond,
+		OnEvent: func(e ReconnectEvent) {
+			mu.Lock()
+			attempts = e.Attempt
+			mu.Unlock()
+		},
+	}))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { n }"))
+	is.NoErr(err)
+	for range payloads {
+	}
+	var closeErr *WebSocketCloseError
+	is.True(errors.As(<-errs, &closeErr))
+	is.Equal(closeErr.Code, 4403)
+	mu.Lock()
+	is.Equal(attempts, 1)
+	mu.Unlock()
+}
+
+func TestReconnectDelay(t *testing.T) {
+	is := is.New(t)
+	p := ReconnectPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		d := p.delay(attempt)
+		is.True(d >= max/2 && d <= max)
+	}
+}
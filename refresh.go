@@ -0,0 +1,110 @@
+package gographql
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RefreshHandler is an http.Handler bridging webhooks into the client's
+// cache: every notification evicts the cached results built from the
+// entities it names and re-runs the queries registered for its topic, so
+// watchers see fresh data. Notifications are JSON objects:
+//
+//	{"topic": "orders", "entities": ["Order:42"]}
+//
+// The handler replies 204 No Content once the queries were re-run, or
+// 502 Bad Gateway if one of them failed so that the sender retries; the
+// failure itself is logged rather than sent back.
+//
+// Without Secret the endpoint is unauthenticated: anyone able to reach
+// it can evict cache entries and make the client re-run queries. Set
+// Secret, or protect the endpoint otherwise, whenever it is exposed.
+type RefreshHandler struct {
+	client *Client
+	// Secret, if set, is the key of the HMAC-SHA256 signature of the
+	// body required in the X-Signature-256 header, as "sha256=<hex>".
+	Secret []byte
+
+	mu     sync.RWMutex
+	topics map[string][]*Request
+}
+
+// NewRefreshHandler makes a RefreshHandler refreshing the cache of
+// client.
+//
+//	h := gographql.NewRefreshHandler(client)
+//	h.Register("orders", ordersReq)
+//	http.Handle("/webhooks/graphql", h)
+func NewRefreshHandler(client *Client) *RefreshHandler {
+	return &RefreshHandler{
+		client: client,
+		topics: make(map[string][]*Request),
+	}
+}
+
+// Register re-runs reqs for every notification about topic. Their cached
+// results are replaced even when no entities are named.
+func (h *RefreshHandler) Register(topic string, reqs ...*Request) {
+	h.mu.Lock()
+	h.topics[topic] = append(h.topics[topic], reqs...)
+	h.mu.Unlock()
+}
+
+func (h *RefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(h.Secret) > 0 && !h.verify(body, r.Header.Get("X-Signature-256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var n struct {
+		Topic    string   `json:"topic"`
+		Entities []string `json:"entities"`
+	}
+	if err := json.Unmarshal(body, &n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.client.cache != nil && len(n.Entities) > 0 {
+		h.client.cache.EvictEntities(n.Entities...)
+	}
+	h.mu.RLock()
+	reqs := h.topics[n.Topic]
+	h.mu.RUnlock()
+	var errs []error
+	for _, req := range reqs {
+		if err := h.client.Run(r.Context(), req, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		h.client.log.Warnf("refresh %s: %v", n.Topic, err)
+		http.Error(w, "refresh failed", http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RefreshHandler) verify(body []byte, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := h.client.crypto.HMACSHA256(h.Secret)
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
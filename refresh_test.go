@@ -0,0 +1,88 @@
+package gographql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRefreshHandler(t *testing.T) {
+	is := is.New(t)
+	status := "pending"
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), "order") {
+			io.WriteString(w, `{"data":{"order":{"__typename":"Order","id":"42","status":"`+status+`"}}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"user":{"__typename":"User","id":"1"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	cache := NewCache()
+	client := NewClient(srv.URL, WithCache(cache))
+	order := NewRequest("{ order { id status } }")
+	user := NewRequest("{ user { id } }")
+	is.NoErr(client.Run(ctx, order, nil))
+	is.NoErr(client.Run(ctx, user, nil))
+
+	h := NewRefreshHandler(client)
+	h.Secret = []byte("s3cret")
+	h.Register("orders", order)
+	body := `{"topic":"orders","entities":["Order:42"]}`
+	mac := hmac.New(sha256.New, h.Secret)
+	mac.Write([]byte(body))
+
+	status = "shipped"
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	h.ServeHTTP(rec, r)
+	is.Equal(rec.Code, http.StatusUnauthorized)
+
+	rec = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	h.ServeHTTP(rec, r)
+	is.Equal(rec.Code, http.StatusNoContent)
+	is.Equal(calls, 3)
+	entity, ok := cache.Entity("Order:42")
+	is.True(ok)
+	is.Equal(entity["status"], "shipped")
+	_, ok = cache.Read(cache.Key(user)) // unrelated results are kept
+	is.True(ok)
+
+	srv.Close()
+	h.Secret = nil
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	is.Equal(rec.Code, http.StatusBadGateway)
+	is.Equal(rec.Body.String(), "refresh failed\n") // no upstream details
+}
+
+func TestEvictEntities(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache()
+	is.NoErr(cache.Write("a", map[string]interface{}{
+		"user": map[string]interface{}{"__typename": "User", "id": "1"},
+	}))
+	is.NoErr(cache.Write("b", map[string]interface{}{
+		"users": []interface{}{map[string]interface{}{"__typename": "User", "id": "2"}},
+	}))
+	is.Equal(cache.EvictEntities("User:2", "User:3"), []string{"b"})
+	results, entities := cache.Len()
+	is.Equal(results, 1)
+	is.Equal(entities, 1)
+}
@@ -0,0 +1,64 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrorReporter receives the operations that failed, to forward them to
+// an error tracker such as Sentry or Rollbar.
+type ErrorReporter func(ctx context.Context, req *Request, err error)
+
+// redactedHeaders are the request headers whose values are never passed
+// to error reporters.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Auth-Token"}
+
+// WithErrorReporter calls report for every failed operation, except those
+// cancelled by the caller. report receives a redacted copy of the
+// request: variable values are replaced by their type and size, as in the
+// slow query log, and credential headers by "[REDACTED]". The context
+// carries the Operation, so reports can be tagged with its name and
+// labels.
+//
+//	gographql.WithErrorReporter(func(ctx context.Context, req *gographql.Request, err error) {
+//	    op, _ := gographql.OperationFromContext(ctx)
+//	    sentry.WithScope(func(scope *sentry.Scope) {
+//	        scope.SetTag("graphql.operation", op.Name)
+//	        scope.SetExtra("variables", req.Vars())
+//	        sentry.CaptureException(err)
+//	    })
+//	})
+func WithErrorReporter(report ErrorReporter) ClientOption {
+	return func(client *Client) {
+		client.reporter = report
+	}
+}
+
+func (c *Client) reportError(ctx context.Context, req *Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	c.reporter(ctx, redactRequest(req), err)
+}
+
+// redactRequest returns a copy of req safe to send to third parties.
+func redactRequest(req *Request) *Request {
+	redacted := NewRequest(req.q)
+	for key, value := range req.vars {
+		redacted.Var(key, summarizeValue(value))
+	}
+	redacted.Header = req.Header.Clone()
+	if redacted.Header == nil {
+		redacted.Header = make(http.Header)
+	}
+	for key := range redacted.Header {
+		for _, name := range redactedHeaders {
+			if strings.EqualFold(key, name) {
+				redacted.Header[key] = []string{"[REDACTED]"}
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,47 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestErrorReporter(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"errors":[{"message":"forbidden"}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var reported []*Request
+	var ops []string
+	client := NewClient(srv.URL, WithErrorReporter(func(ctx context.Context, req *Request, err error) {
+		is.Equal(err.Error(), "graphql: forbidden")
+		op, _ := OperationFromContext(ctx)
+		ops = append(ops, op.Name)
+		reported = append(reported, req)
+	}))
+	req := NewRequest("mutation SetPassword($password: String!) { a }")
+	req.Var("password", "hunter2")
+	req.SetHeader("Authorization", "Bearer secret")
+	req.SetHeader("X-Request-Id", "r1")
+	is.True(client.Run(ctx, req, nil) != nil)
+
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	is.True(client.Run(cancelled, req, nil) != nil) // not reported
+
+	is.Equal(len(reported), 1)
+	is.Equal(ops, []string{"SetPassword"})
+	is.Equal(reported[0].Vars(), map[string]interface{}{"password": "string(7)"})
+	is.Equal(reported[0].Header.Get("Authorization"), "[REDACTED]")
+	is.Equal(reported[0].Header.Get("X-Request-Id"), "r1")
+	is.Equal(req.Header.Get("Authorization"), "Bearer secret") // the request is untouched
+}
@@ -1,15 +1,40 @@
 package gographql
 
 import (
+	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // Request is a GraphQL request.
+//
+// A Request may be shared between goroutines: its methods are safe for
+// concurrent use, and Client.Run works on a snapshot taken when it starts,
+// so modifying a Request never affects runs already in flight. The Header
+// field is a plain map, so concurrent code should use SetHeader and
+// AddHeader rather than modifying it directly.
 type Request struct {
+	mu    sync.RWMutex
 	q     string
 	vars  map[string]interface{}
 	files []File
+	// progress reports the progress of file uploads.
+	progress UploadProgress
+	// method overrides the HTTP method of the client.
+	method string
+	// subMiddleware wraps the events of subscriptions.
+	subMiddleware []SubscriptionMiddleware
+	// partialData returns partial data errors as *PartialDataError.
+	partialData bool
+	// batch holds the operation bodies of a batch sent by RunBatch.
+	batch []map[string]interface{}
 
 	// Header represent any request headers that will be set
 	// when the request is made.
@@ -27,31 +52,68 @@ func NewRequest(q string) *Request {
 
 // Var sets a variable.
 func (req *Request) Var(key string, value interface{}) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
 	if req.vars == nil {
 		req.vars = make(map[string]interface{})
 	}
 	req.vars[key] = value
 }
 
-// Vars gets the variables for this Request.
+// Vars gets a copy of the variables for this Request.
 func (req *Request) Vars() map[string]interface{} {
-	return req.vars
+	req.mu.RLock()
+	defer req.mu.RUnlock()
+	if req.vars == nil {
+		return nil
+	}
+	vars := make(map[string]interface{}, len(req.vars))
+	for key, value := range req.vars {
+		vars[key] = value
+	}
+	return vars
 }
 
 // Files gets the files in this request.
 func (req *Request) Files() []File {
-	return req.files
+	req.mu.RLock()
+	defer req.mu.RUnlock()
+	return append([]File(nil), req.files...)
 }
 
 // Query gets the query string of this request.
 func (req *Request) Query() string {
+	req.mu.RLock()
+	defer req.mu.RUnlock()
 	return req.q
 }
 
+// SetHeader sets the header key to value, replacing any existing values.
+func (req *Request) SetHeader(key, value string) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set(key, value)
+}
+
+// AddHeader adds value to the header key.
+func (req *Request) AddHeader(key, value string) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Add(key, value)
+}
+
 // File sets a file to upload.
 // Files are only supported with a Client that was created with
-// the UseMultipartForm option.
+// the UseMultipartForm or UseMultipartRequestSpec option.
 func (req *Request) File(fieldname, filename string, r io.Reader) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
 	req.files = append(req.files, File{
 		Field: fieldname,
 		Name:  filename,
@@ -59,9 +121,140 @@ func (req *Request) File(fieldname, filename string, r io.Reader) {
 	})
 }
 
+// FileFunc sets a file to upload whose content is produced by open. A
+// new reader is opened every time the request is sent, so the request
+// can be run again or resent after a redirect, which is not possible
+// with the one-shot reader given to File.
+// Files are only supported with a Client that was created with
+// the UseMultipartForm or UseMultipartRequestSpec option.
+func (req *Request) FileFunc(fieldname, filename string, open BodyFactory) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.files = append(req.files, File{
+		Field: fieldname,
+		Name:  filename,
+		Open:  open,
+	})
+}
+
+// FileFromPath sets the file at path to upload, with its base name as
+// file name. The part's Content-Type is contentType, or detected from
+// the file extension and content when empty. The file is opened every
+// time the request is sent.
+// Files are only supported with a Client that was created with
+// the UseMultipartForm or UseMultipartRequestSpec option.
+func (req *Request) FileFromPath(fieldname, path, contentType string) error {
+	f, err := statFile(filepath.Base(path), contentType, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}, func() (fs.FileInfo, error) {
+		return os.Stat(path)
+	})
+	if err != nil {
+		return err
+	}
+	f.Field = fieldname
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.files = append(req.files, f)
+	return nil
+}
+
+// FileFromFS is like FileFromPath for the file called name in fsys.
+func (req *Request) FileFromFS(fieldname string, fsys fs.FS, name, contentType string) error {
+	f, err := statFile(path.Base(name), contentType, func() (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}, func() (fs.FileInfo, error) {
+		return fs.Stat(fsys, name)
+	})
+	if err != nil {
+		return err
+	}
+	f.Field = fieldname
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.files = append(req.files, f)
+	return nil
+}
+
+// statFile describes the file opened by open, detecting its content type
+// if contentType is empty.
+func statFile(name, contentType string, open BodyFactory, stat func() (fs.FileInfo, error)) (File, error) {
+	info, err := stat()
+	if err != nil {
+		return File{}, err
+	}
+	if info.IsDir() {
+		return File{}, fmt.Errorf("%s is a directory", name)
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		r, err := open()
+		if err != nil {
+			return File{}, err
+		}
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(r, head)
+		r.Close()
+		contentType = http.DetectContentType(head[:n])
+	}
+	return File{Name: name, Open: open, ContentType: contentType, Size: info.Size()}, nil
+}
+
+// Clone returns a copy of the request that can be modified independently.
+// Variable values and file readers are shared, not copied.
+func (req *Request) Clone() *Request {
+	req.mu.RLock()
+	defer req.mu.RUnlock()
+	clone := &Request{
+		q:             req.q,
+		files:         append([]File(nil), req.files...),
+		progress:      req.progress,
+		method:        req.method,
+		subMiddleware: append([]SubscriptionMiddleware(nil), req.subMiddleware...),
+		partialData:   req.partialData,
+		batch:         req.batch,
+		Header:        req.Header.Clone(),
+	}
+	if clone.Header == nil {
+		clone.Header = make(http.Header)
+	}
+	if req.vars != nil {
+		clone.vars = make(map[string]interface{}, len(req.vars))
+		for key, value := range req.vars {
+			clone.vars[key] = value
+		}
+	}
+	return clone
+}
+
+// BodyFactory opens a new reader over the same content every time it is
+// called.
+type BodyFactory func() (io.ReadCloser, error)
+
 // File represents a file to upload.
 type File struct {
 	Field string
 	Name  string
 	R     io.Reader
+	// Open, if set, is used instead of R to read the content.
+	Open BodyFactory
+	// ContentType is the Content-Type of the part, application/octet-stream
+	// when empty.
+	ContentType string
+	// Size is the size of the content in bytes, if known.
+	Size int64
+}
+
+// reader returns the content of the file. The returned reader must be
+// closed.
+func (f File) reader() (io.ReadCloser, error) {
+	if f.Open != nil {
+		return f.Open()
+	}
+	if f.R == nil {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return io.NopCloser(f.R), nil
 }
@@ -0,0 +1,108 @@
+package gographql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRequestConcurrentUse(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	req := NewRequest("query {}")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			req.Var(fmt.Sprintf("v%d", i), i)
+			req.SetHeader("X-Index", fmt.Sprint(i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			is.NoErr(client.Run(ctx, req, nil))
+		}()
+	}
+	wg.Wait()
+	is.Equal(len(req.Vars()), 10)
+}
+
+func TestRequestClone(t *testing.T) {
+	is := is.New(t)
+	req := NewRequest("query {}")
+	req.Var("a", 1)
+	req.Header.Set("X-A", "1")
+	clone := req.Clone()
+	clone.Var("b", 2)
+	clone.Header.Set("X-A", "2")
+	is.Equal(req.Vars(), map[string]interface{}{"a": 1})
+	is.Equal(req.Header.Get("X-A"), "1")
+	is.Equal(clone.Vars(), map[string]interface{}{"a": 1, "b": 2})
+
+	vars := req.Vars()
+	vars["c"] = 3
+	is.Equal(len(req.Vars()), 1) // Vars returns a copy
+}
+
+func TestFileFromPathAndFS(t *testing.T) {
+	is := is.New(t)
+	type part struct{ name, contentType, content string }
+	var parts []part
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(r.ParseMultipartForm(1 << 20))
+		for _, field := range []string{"csv", "doc", "raw"} {
+			h := r.MultipartForm.File[field][0]
+			f, err := h.Open()
+			is.NoErr(err)
+			b, err := io.ReadAll(f)
+			is.NoErr(err)
+			parts = append(parts, part{h.Filename, h.Header.Get("Content-Type"), string(b)})
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "data.csv"), []byte("a,b\n1,2\n"), 0o600))
+	fsys := fstest.MapFS{
+		"docs/page":  {Data: []byte("<html><body>hi</body></html>")},
+		"docs/blob":  {Data: []byte{0, 1, 2}},
+		"docs/empty": {Mode: fs.ModeDir},
+	}
+
+	req := NewRequest("query {}")
+	is.NoErr(req.FileFromPath("csv", filepath.Join(dir, "data.csv"), ""))
+	is.NoErr(req.FileFromFS("doc", fsys, "docs/page", ""))
+	is.NoErr(req.FileFromFS("raw", fsys, "docs/blob", "application/x-custom"))
+	is.True(req.FileFromPath("missing", filepath.Join(dir, "missing.txt"), "") != nil)
+	is.True(req.FileFromFS("dir", fsys, "docs/empty", "") != nil)
+	is.Equal(req.Files()[0].Size, int64(8))
+
+	client := NewClient(srv.URL, UseMultipartForm())
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(parts, []part{
+		{"data.csv", "text/csv; charset=utf-8", "a,b\n1,2\n"},
+		{"page", "text/html; charset=utf-8", "<html><body>hi</body></html>"},
+		{"blob", "application/x-custom", "\x00\x01\x02"},
+	})
+}
@@ -0,0 +1,146 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CacheStatus reports whether a result was served from the client cache.
+type CacheStatus int
+
+const (
+	// CacheBypass the client has no cache.
+	CacheBypass CacheStatus = iota
+	// CacheMiss the result was fetched from the server.
+	CacheMiss
+	// CacheHit the result was served from the cache, e.g. by the kill
+	// switch in OperationServeCached mode.
+	CacheHit
+)
+
+func (s CacheStatus) String() string {
+	switch s {
+	case CacheMiss:
+		return "miss"
+	case CacheHit:
+		return "hit"
+	}
+	return "bypass"
+}
+
+// Result is the outcome of an operation run with Execute: the decoded
+// data along with everything else known about the response.
+type Result[T any] struct {
+	// Data is the decoded data, possibly partial when Errors is set.
+	Data T
+	// Errors are the GraphQL errors of the response.
+	Errors GraphQLErrors
+	// Extensions is the extensions field of the response.
+	Extensions map[string]interface{}
+	// StatusCode and Header are those of the HTTP response, zero when
+	// none was received.
+	StatusCode int
+	Header     http.Header
+	// Duration is the time the operation took.
+	Duration time.Duration
+	// Timings is the latency breakdown, with WithLatencyBreakdown.
+	Timings     *Timings
+	CacheStatus CacheStatus
+}
+
+// Execute runs req with c and returns its result decoded into T. The
+// result is returned whenever a response was received, even if err is
+// not nil, so GraphQL errors and HTTP metadata can be inspected.
+//
+//	res, err := gographql.Execute[struct {
+//	    User User `json:"user"`
+//	}](ctx, client, req)
+//	if err != nil {
+//	    return err
+//	}
+//	log.Printf("cost: %v", res.Extensions["cost"])
+func Execute[T any](ctx context.Context, c *Client, req *Request) (*Result[T], error) {
+	res := &Result[T]{}
+	received, err := res.run(ctx, c, req, &res.Data)
+	if !received {
+		return nil, err
+	}
+	return res, err
+}
+
+// Response is the result of Client.RunWithResponse, whose Data is the
+// response object it was given.
+type Response = Result[interface{}]
+
+// RunWithResponse is like Run but also returns everything known about
+// the response: the GraphQL errors, extensions, HTTP status and headers,
+// and duration. Like Execute, the result is returned whenever a response
+// was received, even if err is not nil.
+//
+//	var data struct{ User User }
+//	res, err := client.RunWithResponse(ctx, req, &data)
+//	if res != nil {
+//	    log.Printf("cost: %v, cache-control: %s", res.Extensions["cost"], res.Header.Get("Cache-Control"))
+//	}
+func (c *Client) RunWithResponse(ctx context.Context, req *Request, resp interface{}) (*Response, error) {
+	res := &Response{Data: resp}
+	received, err := res.run(ctx, c, req, resp)
+	if !received {
+		return nil, err
+	}
+	return res, err
+}
+
+// run runs req, decoding its data into data, and fills res. It reports
+// whether a response was received.
+func (res *Result[T]) run(ctx context.Context, c *Client, req *Request, data interface{}) (bool, error) {
+	var meta responseMeta
+	start := time.Now()
+	err := c.run(ctx, req, data, &meta)
+	res.Duration = time.Since(start)
+	res.Extensions = meta.extensions
+	res.StatusCode = meta.statusCode
+	res.Header = meta.header
+	res.Timings = meta.timings
+	errors.As(err, &res.Errors)
+	switch {
+	case meta.cached:
+		res.CacheStatus = CacheHit
+	case c.cache != nil:
+		res.CacheStatus = CacheMiss
+	}
+	return meta.statusCode != 0 || meta.cached, err
+}
+
+// Query runs the query req with c and returns its data decoded into T,
+// for callers that only need the data. The data is returned along with
+// GraphQL errors, so it may be partial.
+//
+//	user, err := gographql.Query[struct {
+//	    User User `json:"user"`
+//	}](ctx, client, req)
+func Query[T any](ctx context.Context, c *Client, req *Request) (T, error) {
+	return executeData[T](ctx, c, req, "query")
+}
+
+// Mutate is like Query for the mutation req.
+func Mutate[T any](ctx context.Context, c *Client, req *Request) (T, error) {
+	return executeData[T](ctx, c, req, "mutation")
+}
+
+// executeData executes req, which must be an operation of type kind, and
+// returns its data.
+func executeData[T any](ctx context.Context, c *Client, req *Request, kind string) (T, error) {
+	var zero T
+	if got, _ := operationInfo(req.Query()); got != kind {
+		return zero, fmt.Errorf("%s: operation is a %s", kind, got)
+	}
+	res, err := Execute[T](ctx, c, req)
+	if res == nil {
+		return zero, err
+	}
+	return res.Data, err
+}
@@ -0,0 +1,128 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestExecute(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "r1")
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"},"friends":null},"errors":[{"message":"friends unavailable","path":["friends"]}],"extensions":{"cost":12}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	type data struct {
+		User struct{ Name string }
+	}
+	res, err := Execute[data](ctx, NewClient(srv.URL), NewRequest("{ user { name } friends }"))
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+	is.Equal(res.Data.User.Name, "Mat")
+	is.Equal(len(res.Errors), 1)
+	is.Equal(res.Errors[0].Message, "friends unavailable")
+	is.Equal(res.Extensions["cost"], float64(12))
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.Equal(res.Header.Get("X-Request-Id"), "r1")
+	is.True(res.Duration > 0)
+	is.Equal(res.CacheStatus, CacheBypass)
+}
+
+func TestExecuteCacheStatus(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"feed":"fresh"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	k := NewKillSwitch(nil)
+	client := NewClient(srv.URL, WithKillSwitch(k), WithCache(NewCache()))
+	feed := NewRequest("query Feed { feed }")
+	res, err := Execute[struct{ Feed string }](ctx, client, feed)
+	is.NoErr(err)
+	is.Equal(res.CacheStatus, CacheMiss)
+
+	k.Disable("Feed", OperationServeCached)
+	res, err = Execute[struct{ Feed string }](ctx, client, feed)
+	is.NoErr(err)
+	is.Equal(res.Data.Feed, "fresh")
+	is.Equal(res.CacheStatus, CacheHit)
+	is.Equal(res.StatusCode, 0)
+}
+
+func TestExecuteTransportError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+	res, err := Execute[struct{}](context.Background(), NewClient(srv.URL), NewRequest("{ a }"))
+	is.True(err != nil)
+	is.Equal(res, nil)
+}
+
+func TestQueryMutate(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}},"errors":[{"message":"partial"}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	type userData struct {
+		User struct{ Name string }
+	}
+	data, err := Query[userData](ctx, client, NewRequest(`query { user { name } }`))
+	is.Equal(err.Error(), "graphql: partial")
+	is.Equal(data.User.Name, "Mat")
+
+	data, err = Mutate[userData](ctx, client, NewRequest(`mutation { rename { name } }`))
+	is.Equal(data.User.Name, "Mat")
+	is.True(err != nil)
+
+	_, err = Query[userData](ctx, client, NewRequest(`mutation { rename { name } }`))
+	is.Equal(err.Error(), "query: operation is a mutation")
+	_, err = Mutate[userData](ctx, client, NewRequest(`{ user { name } }`))
+	is.Equal(err.Error(), "mutation: operation is a query")
+}
+
+func TestRunWithResponse(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}},"errors":[{"message":"partial"}],"extensions":{"cost":3}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var data struct {
+		User struct{ Name string }
+	}
+	res, err := NewClient(srv.URL).RunWithResponse(ctx, NewRequest(`{ user { name } }`), &data)
+	is.Equal(err.Error(), "graphql: partial")
+	is.Equal(data.User.Name, "Mat")
+	is.Equal(res.Data, &data)
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.Equal(res.Header.Get("Cache-Control"), "max-age=60")
+	is.Equal(res.Extensions["cost"], 3.0)
+	is.Equal(len(res.Errors), 1)
+	is.True(res.Duration > 0)
+
+	srv.Close()
+	res, err = NewClient(srv.URL).RunWithResponse(ctx, NewRequest(`{ user { name } }`), nil)
+	is.True(err != nil)
+	is.True(res == nil)
+}
@@ -0,0 +1,67 @@
+package gographql
+
+import (
+	"context"
+	"sync"
+)
+
+// Job is a request run by RunAll, decoding into Resp, which may be nil.
+type Job struct {
+	Req  *Request
+	Resp interface{}
+}
+
+// RunAllOption configures Client.RunAll.
+type RunAllOption func(*runAll)
+
+type runAll struct {
+	concurrency int
+}
+
+// WithConcurrency limits RunAll to n requests in flight, 8 by default.
+func WithConcurrency(n int) RunAllOption {
+	return func(r *runAll) {
+		r.concurrency = n
+	}
+}
+
+// RunAll runs the jobs concurrently, each in its own HTTP request, and
+// returns the error of every job at the same index, nil for the jobs that
+// succeeded. Once ctx is done the jobs not started yet are not run and
+// report ctx.Err().
+//
+//	errs := client.RunAll(ctx, []gographql.Job{
+//	    {Req: userReq, Resp: &user},
+//	    {Req: ordersReq, Resp: &orders},
+//	}, gographql.WithConcurrency(4))
+func (c *Client) RunAll(ctx context.Context, jobs []Job, opts ...RunAllOption) []error {
+	r := &runAll{concurrency: 8}
+	for _, optionFunc := range opts {
+		optionFunc(r)
+	}
+	if r.concurrency <= 0 {
+		r.concurrency = 1
+	}
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(jobs); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = c.Run(ctx, job.Req, job.Resp)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
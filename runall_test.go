@@ -0,0 +1,80 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRunAll(t *testing.T) {
+	is := is.New(t)
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		var body struct{ Variables map[string]int }
+		json.NewDecoder(r.Body).Decode(&body)
+		n = int32(body.Variables["n"])
+		if n == 3 {
+			io.WriteString(w, `{"data":null,"errors":[{"message":"boom"}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"n":%d}}`, n)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	jobs := make([]Job, 10)
+	resps := make([]struct{ N int }, len(jobs))
+	for i := range jobs {
+		req := NewRequest(`query ($n: Int!) { n(n: $n) }`)
+		req.Var("n", i)
+		jobs[i] = Job{Req: req, Resp: &resps[i]}
+	}
+	errs := client.RunAll(ctx, jobs, WithConcurrency(3))
+	is.Equal(len(errs), len(jobs))
+	is.True(maxInFlight.Load() <= 3)
+	for i := range jobs {
+		if i == 3 {
+			is.Equal(errs[i].Error(), "graphql: boom")
+			continue
+		}
+		is.NoErr(errs[i])
+		is.Equal(resps[i].N, i)
+	}
+}
+
+func TestRunAllCanceled(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	jobs := []Job{{Req: NewRequest(`{ a }`)}, {Req: NewRequest(`{ b }`)}}
+	errs := client.RunAll(ctx, jobs, WithConcurrency(1))
+	is.True(errs[0] != nil)
+	is.True(errors.Is(errs[1], context.DeadlineExceeded))
+}
@@ -0,0 +1,127 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSchedule the interval or maximum backoff given to Client.Every
+// is not positive, or its jitter is outside [0, 1).
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// ScheduleOption configures Client.Every.
+type ScheduleOption func(*schedule)
+
+type schedule struct {
+	jitter     float64
+	maxBackoff time.Duration
+	immediate  bool
+}
+
+// WithJitter spreads runs by up to frac of the interval either way (0.1
+// for ±10%), so many agents started together don't hit the server at the
+// same instant. frac must be at least 0 and less than 1.
+func WithJitter(frac float64) ScheduleOption {
+	return func(s *schedule) {
+		s.jitter = frac
+	}
+}
+
+// WithMaxBackoff caps the delay after consecutive failures, which doubles
+// from the interval with every failure. It is 10 times the interval by
+// default.
+func WithMaxBackoff(d time.Duration) ScheduleOption {
+	return func(s *schedule) {
+		s.maxBackoff = d
+	}
+}
+
+// RunImmediately makes the first run start right away instead of after
+// one interval.
+func RunImmediately() ScheduleOption {
+	return func(s *schedule) {
+		s.immediate = true
+	}
+}
+
+// Every runs req periodically until ctx is done or stop is called, and
+// calls handler with the data or error of every run, for agents that sync
+// data from a GraphQL API. Runs never overlap: the next run is scheduled
+// once the previous one and its handler have returned, so slow runs delay
+// later ones instead of piling up. After a failure the delay backs off
+// exponentially, and returns to the interval after the next success.
+// stop waits for a run in progress to finish. An interval or maximum
+// backoff that is not positive, or a jitter outside [0, 1), is reported
+// to handler as ErrInvalidSchedule, and nothing runs.
+//
+//	stop := client.Every(ctx, time.Minute, req, func(data json.RawMessage, err error) {
+//	    ...
+//	}, gographql.WithJitter(0.1))
+//	defer stop()
+func (c *Client) Every(ctx context.Context, interval time.Duration, req *Request, handler func(data json.RawMessage, err error), opts ...ScheduleOption) (stop func()) {
+	s := &schedule{maxBackoff: 10 * interval}
+	for _, optionFunc := range opts {
+		optionFunc(s)
+	}
+	if interval <= 0 || s.maxBackoff <= 0 || !(s.jitter >= 0 && s.jitter < 1) {
+		// runs would follow each other without pause
+		handler(nil, fmt.Errorf("%w: interval %v, max backoff %v, jitter %v", ErrInvalidSchedule, interval, s.maxBackoff, s.jitter))
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		failures := 0
+		delay := s.delay(interval, 0)
+		if s.immediate {
+			delay = 0
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			var data json.RawMessage
+			err := c.Run(ctx, req, &data)
+			if ctx.Err() != nil {
+				return
+			}
+			handler(data, err)
+			if err != nil {
+				failures++
+			} else {
+				failures = 0
+			}
+			timer.Reset(s.delay(interval, failures))
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// delay returns the time to wait before the next run.
+func (s *schedule) delay(interval time.Duration, failures int) time.Duration {
+	d := interval
+	for i := 0; i < failures && d < s.maxBackoff; i++ {
+		d *= 2
+	}
+	if failures > 0 && d > s.maxBackoff {
+		d = s.maxBackoff
+	}
+	if s.jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * s.jitter * float64(d))
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestEvery(t *testing.T) {
+	is := is.New(t)
+	var calls, inFlight, overlaps int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&inFlight, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		defer atomic.AddInt32(&inFlight, -1)
+		n := atomic.AddInt32(&calls, 1)
+		time.Sleep(5 * time.Millisecond) // longer than the interval
+		if n == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, `{"data":{"n":1}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var results []string
+	done := make(chan struct{})
+	stop := NewClient(srv.URL).Every(ctx, time.Millisecond, NewRequest("{ n }"), func(data json.RawMessage, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			results = append(results, "error")
+		} else {
+			results = append(results, string(data))
+		}
+		if len(results) == 3 {
+			close(done)
+		}
+	}, RunImmediately(), WithJitter(0.5))
+	<-done
+	stop()
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(results[:3], []string{`{"n":1}`, "error", `{"n":1}`})
+	is.Equal(atomic.LoadInt32(&overlaps), int32(0))
+}
+
+func TestScheduleBackoff(t *testing.T) {
+	is := is.New(t)
+	s := &schedule{maxBackoff: 5 * time.Second}
+	is.Equal(s.delay(time.Second, 0), time.Second)
+	is.Equal(s.delay(time.Second, 2), 4*time.Second)
+	is.Equal(s.delay(time.Second, 10), 5*time.Second)
+}
+
+func TestEveryInvalidInterval(t *testing.T) {
+	is := is.New(t)
+	client := NewClient("http://127.0.0.1:1")
+	for _, interval := range []time.Duration{0, -time.Second} {
+		var got error
+		stop := client.Every(context.Background(), interval, NewRequest("{ a }"), func(data json.RawMessage, err error) {
+			got = err
+		})
+		stop()
+		is.True(errors.Is(got, ErrInvalidSchedule))
+	}
+}
+
+func TestEveryInvalidJitter(t *testing.T) {
+	is := is.New(t)
+	client := NewClient("http://127.0.0.1:1")
+	for _, frac := range []float64{-0.1, 1, 2, math.NaN()} {
+		var got error
+		stop := client.Every(context.Background(), time.Minute, NewRequest("{ a }"), func(data json.RawMessage, err error) {
+			got = err
+		}, WithJitter(frac))
+		stop()
+		is.True(errors.Is(got, ErrInvalidSchedule))
+	}
+}
@@ -0,0 +1,72 @@
+package gographql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithSlowQueryLog logs a warning for every operation taking threshold
+// or longer, with its name, duration and a summary of its variables,
+// whether or not debug logging is enabled. Variable values are not
+// logged, only their types and sizes, so the log is safe to keep on in
+// production.
+func WithSlowQueryLog(threshold time.Duration) ClientOption {
+	return func(client *Client) {
+		if threshold <= 0 {
+			client.invalidOption("WithSlowQueryLog: threshold must be positive, got %v", threshold)
+		}
+		client.slowQuery = threshold
+	}
+}
+
+func (c *Client) logSlowQuery(op *Operation, req *Request, d time.Duration, err error) {
+	name := op.Name
+	if name == "" {
+		name = "(anonymous)"
+	}
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	c.log.Warnf("slow %s %s took %s (%s) variables: %s", op.Type, name, d.Round(time.Millisecond), status, summarizeVars(req.vars))
+}
+
+// summarizeVars describes variables by type and size, e.g.
+// "{first: int, ids: [3]string, name: string(12)}".
+func summarizeVars(vars map[string]interface{}) string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + summarizeValue(vars[key])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func summarizeValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "null"
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("string(%d)", rv.Len())
+	case reflect.Slice, reflect.Array:
+		return fmt.Sprintf("[%d]%s", rv.Len(), rv.Type().Elem())
+	case reflect.Map:
+		return fmt.Sprintf("object(%d)", rv.Len())
+	}
+	return rv.Type().String()
+}
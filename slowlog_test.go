@@ -0,0 +1,43 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), "Slow") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var logs bytes.Buffer
+	client := NewClient(srv.URL, WithSlowQueryLog(10*time.Millisecond))
+	client.SetLogger(NewLogger(&logs, "", 0))
+	req := NewRequest("query Slow($ids: [ID!], $name: String, $first: Int) { a }")
+	req.Var("ids", []string{"1", "2"})
+	req.Var("name", "secret")
+	req.Var("first", 10)
+	is.NoErr(client.Run(ctx, req, nil))
+	is.NoErr(client.Run(ctx, NewRequest("query Fast { a }"), nil))
+	out := logs.String()
+	is.True(strings.HasPrefix(out, "WARN [req] slow query Slow took "))
+	is.True(strings.Contains(out, "(ok) variables: {first: int, ids: [2]string, name: string(6)}"))
+	is.True(!strings.Contains(out, "secret"))
+	is.True(!strings.Contains(out, "Fast"))
+}
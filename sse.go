@@ -0,0 +1,125 @@
+package gographql
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// eventStreamContentType is the media type of Server-Sent Events.
+const eventStreamContentType = "text/event-stream"
+
+// subscribeSSE starts the subscription req over Server-Sent Events, with
+// the distinct connections mode of the GraphQL over SSE protocol: the
+// operation is posted to the endpoint, which streams its results as
+// "next" events until a "complete" event.
+func (c *Client) subscribeSSE(ctx context.Context, req *Request) (<-chan SubscriptionPayload, <-chan error, error) {
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	body := getBodyBuffer()
+	defer body.release()
+	payload := map[string]interface{}{"query": req.q}
+	if req.vars != nil {
+		payload["variables"] = req.vars
+	}
+	if _, name := operationInfo(req.q); name != "" {
+		payload["operationName"] = name
+	}
+	if err := c.encodeBody(&body.Buffer, payload); err != nil {
+		return nil, nil, errors.Join(ErrEncodingRequestBody, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r, err := newBodyRequest(ctx, endpoint, body)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Accept", eventStreamContentType)
+	c.setHeaders(ctx, r, req)
+	res, err := c.wsHTTPClient().Do(r)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); res.StatusCode != http.StatusOK || mediaType != eventStreamContentType {
+		res.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("%w; statuscode: %v", ErrGraphqlServerError, res.StatusCode)
+	}
+	sub := newSubscription(ctx, cancel, req)
+	sub.handler = c.subscriptionHandler(req, sub)
+	go func() {
+		defer res.Body.Close()
+		sub.end(readEvents(sub, res.Body))
+	}()
+	go func() {
+		// unblock the read once the subscription is stopped
+		<-ctx.Done()
+		res.Body.Close()
+	}()
+	return sub.payloads, sub.errs, nil
+}
+
+// readEvents delivers the results of the event stream r to sub, and
+// returns the reason it ended early, if any.
+func readEvents(sub *subscription, r io.Reader) error {
+	br := bufio.NewReader(r)
+	var event string
+	var data strings.Builder
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			if sub.ctx.Err() != nil {
+				return nil
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+			continue
+		}
+		// a blank line dispatches the event
+		switch event {
+		case "next", "":
+			if data.Len() == 0 {
+				break
+			}
+			var p SubscriptionPayload
+			if err := json.Unmarshal([]byte(data.String()), &p); err != nil {
+				return errors.Join(ErrDecodingResponse, err)
+			}
+			if sub.handler == nil {
+				sub.deliver(p)
+			} else if err := sub.handler(sub.ctx, p); err != nil {
+				return err
+			}
+		case "complete":
+			return nil
+		}
+		event = ""
+		data.Reset()
+	}
+}
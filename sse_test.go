@@ -0,0 +1,31 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadEvents(t *testing.T) {
+	is := is.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := newSubscription(ctx, cancel, nil)
+	defer sub.end(nil)
+	stream := ": keep-alive\r\n\r\n" +
+		"event: next\r\ndata: {\"data\":\r\ndata: {\"a\":1}}\r\n\r\n" +
+		"data: {\"data\":{\"a\":2}}\n\n" +
+		"event: complete\n\n" +
+		"data: {\"data\":{\"a\":3}}\n\n"
+	is.NoErr(readEvents(sub, strings.NewReader(stream)))
+	is.Equal(string((<-sub.payloads).Data), "{\"a\":1}")
+	is.Equal(string((<-sub.payloads).Data), `{"a":2}`)
+
+	// a stream cut before the complete event
+	err := readEvents(sub, strings.NewReader("data: {\"data\":{}}\n\n"))
+	is.True(errors.Is(err, io.ErrUnexpectedEOF))
+}
@@ -0,0 +1,58 @@
+package gographql
+
+import "context"
+
+// SubscriptionHandler handles an event of a subscription. Returning an
+// error ends the subscription with it.
+type SubscriptionHandler func(ctx context.Context, p SubscriptionPayload) error
+
+// SubscriptionMiddleware wraps the delivery of subscription events. It
+// may drop an event by not calling next, or transform it before passing
+// it on:
+//
+//	dropHeartbeats := func(next gographql.SubscriptionHandler) gographql.SubscriptionHandler {
+//	    return func(ctx context.Context, p gographql.SubscriptionPayload) error {
+//	        if string(p.Data) == `{"heartbeat":true}` {
+//	            return nil
+//	        }
+//	        return next(ctx, p)
+//	    }
+//	}
+type SubscriptionMiddleware func(next SubscriptionHandler) SubscriptionHandler
+
+// WithSubscriptionMiddleware registers middleware wrapping the events of
+// every subscription, outside of the middleware of each request. The
+// first middleware is the outermost.
+func WithSubscriptionMiddleware(mw ...SubscriptionMiddleware) ClientOption {
+	return func(client *Client) {
+		client.subMiddleware = append(client.subMiddleware, mw...)
+	}
+}
+
+// UseSubscriptionMiddleware registers middleware wrapping the events of
+// the subscription started with this request. The first middleware is
+// the outermost.
+func (req *Request) UseSubscriptionMiddleware(mw ...SubscriptionMiddleware) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.subMiddleware = append(req.subMiddleware, mw...)
+}
+
+// subscriptionHandler chains the middleware of the client and of req
+// around the delivery to sub, or returns nil when there is none.
+func (c *Client) subscriptionHandler(req *Request, sub *subscription) SubscriptionHandler {
+	if len(c.subMiddleware) == 0 && len(req.subMiddleware) == 0 {
+		return nil
+	}
+	h := SubscriptionHandler(func(ctx context.Context, p SubscriptionPayload) error {
+		sub.deliver(p)
+		return nil
+	})
+	for i := len(req.subMiddleware) - 1; i >= 0; i-- {
+		h = req.subMiddleware[i](h)
+	}
+	for i := len(c.subMiddleware) - 1; i >= 0; i-- {
+		h = c.subMiddleware[i](h)
+	}
+	return h
+}
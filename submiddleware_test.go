@@ -0,0 +1,72 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSubscriptionMiddleware(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		for _, data := range []string{`{"n":1}`, `{"heartbeat":true}`, `{"n":2}`, `{"n":-1}`} {
+			writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":`+data+`}}`)
+		}
+		msg, _ = readWSMessage(conn)
+		is.Equal(msg.Type, "complete") // stopped by the middleware error
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var order []string
+	trace := func(name string) SubscriptionMiddleware {
+		return func(next SubscriptionHandler) SubscriptionHandler {
+			return func(ctx context.Context, p SubscriptionPayload) error {
+				order = append(order, name)
+				return next(ctx, p)
+			}
+		}
+	}
+	errNegative := errors.New("negative")
+	client := NewClient(srv.URL, WithSubscriptionMiddleware(trace("client")))
+	req := NewRequest("subscription { n }")
+	req.UseSubscriptionMiddleware(trace("request"), func(next SubscriptionHandler) SubscriptionHandler {
+		return func(ctx context.Context, p SubscriptionPayload) error {
+			var event struct {
+				N         int
+				Heartbeat bool
+			}
+			if err := json.Unmarshal(p.Data, &event); err != nil {
+				return err
+			}
+			switch {
+			case event.Heartbeat:
+				return nil
+			case event.N < 0:
+				return errNegative
+			}
+			p.Data = json.RawMessage(fmt.Sprintf(`{"n":%d}`, event.N*10))
+			return next(ctx, p)
+		}
+	})
+	payloads, errs, err := client.Subscribe(ctx, req)
+	is.NoErr(err)
+	var got []string
+	for p := range payloads {
+		got = append(got, string(p.Data))
+	}
+	is.Equal(got, []string{`{"n":10}`, `{"n":20}`})
+	is.True(errors.Is(<-errs, errNegative))
+	is.Equal(order[:2], []string{"client", "request"})
+}
@@ -0,0 +1,815 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrConnectionAckTimeout the server did not acknowledge the subscription
+// connection in time.
+var ErrConnectionAckTimeout = errors.New("connection ack timeout")
+
+// ErrKeepAliveTimeout the server did not answer keepalive pings in time.
+var ErrKeepAliveTimeout = errors.New("subscription keepalive timeout")
+
+var errSubConnClosed = errors.New("subscription connection closed")
+
+// Subscription protocols, identified by their WebSocket subprotocol names.
+const (
+	// GraphQLTransportWS is the graphql-transport-ws protocol of the
+	// graphql-ws library.
+	GraphQLTransportWS = "graphql-transport-ws"
+	// SubscriptionsTransportWS is the legacy protocol of the
+	// subscriptions-transport-ws library, spoken by older servers such
+	// as Hasura before 2.0.
+	SubscriptionsTransportWS = "graphql-ws"
+)
+
+// subProtocol names the messages of a subscription protocol.
+type subProtocol struct {
+	subscribe, next, stop string
+	// terminate is sent before closing the connection, if set.
+	terminate string
+	// ping is sent to keep the connection alive. Without it, WebSocket
+	// ping frames are sent.
+	ping string
+}
+
+var subProtocols = map[string]*subProtocol{
+	GraphQLTransportWS:       {subscribe: "subscribe", next: "next", stop: "complete", ping: "ping"},
+	SubscriptionsTransportWS: {subscribe: "start", next: "data", stop: "stop", terminate: "connection_terminate"},
+}
+
+// DefaultConnectionAckTimeout bounds the wait for connection_ack when
+// WithConnectionAckTimeout is not used.
+const DefaultConnectionAckTimeout = 10 * time.Second
+
+// SubscriptionPayload is one result of a subscription.
+type SubscriptionPayload struct {
+	Data       json.RawMessage        `json:"data"`
+	Errors     GraphQLErrors          `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	// Revision and Patch are set by servers sending live query results
+	// as patches of the previous revision, see Client.Live.
+	Revision int             `json:"revision,omitempty"`
+	Patch    json.RawMessage `json:"patch,omitempty"`
+}
+
+// WithSubscriptionEndpoint sets the WebSocket URL used by Subscribe. By
+// default it is the client endpoint with the ws or wss scheme.
+func WithSubscriptionEndpoint(url string) ClientOption {
+	return func(client *Client) {
+		client.subscriptionEndpoint = url
+	}
+}
+
+// WithSubscriptionProtocols sets the subscription protocols offered to
+// the server, in order of preference, among GraphQLTransportWS and
+// SubscriptionsTransportWS. The server picks one in the WebSocket
+// handshake; if it does not say which, the first is used. Both are
+// offered by default, so the same client works with both generations of
+// servers.
+func WithSubscriptionProtocols(protocols ...string) ClientOption {
+	return func(client *Client) {
+		for _, p := range protocols {
+			if subProtocols[p] == nil {
+				client.invalidOption("WithSubscriptionProtocols: unknown protocol %q", p)
+			}
+		}
+		client.subscriptionProtocols = protocols
+	}
+}
+
+// WithConnectionInitPayload sets the payload of the connection_init
+// message opening subscription connections, for servers expecting
+// credentials there rather than in the HTTP headers of the handshake:
+//
+//	gographql.WithConnectionInitPayload(map[string]interface{}{
+//	    "headers": map[string]string{"Authorization": "Bearer " + token},
+//	})
+func WithConnectionInitPayload(payload interface{}) ClientOption {
+	return func(client *Client) {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			client.invalidOption("WithConnectionInitPayload: %v", err)
+			return
+		}
+		client.connectionInit = b
+	}
+}
+
+// WithConnectionAckTimeout sets how long to wait for the server to
+// acknowledge a subscription connection, DefaultConnectionAckTimeout by
+// default.
+func WithConnectionAckTimeout(d time.Duration) ClientOption {
+	return func(client *Client) {
+		client.connectionAckTimeout = d
+	}
+}
+
+// WithSubscriptionKeepAlive pings the server every interval on idle
+// subscription connections, and drops connections on which nothing was
+// received for two intervals, ending their subscriptions with
+// ErrKeepAliveTimeout, or reconnecting with WithSubscriptionReconnect.
+// This detects connections silently dropped by proxies and load
+// balancers.
+func WithSubscriptionKeepAlive(interval time.Duration) ClientOption {
+	return func(client *Client) {
+		client.keepAlive = interval
+	}
+}
+
+// Subscribe starts the subscription req over a WebSocket connection using
+// the graphql-transport-ws protocol, or the legacy
+// subscriptions-transport-ws one if the server prefers it (see
+// WithSubscriptionProtocols), and returns the channel of its
+// results and a channel receiving at most one error, the reason the
+// subscription ended early. Both channels are closed when the
+// subscription ends: when the server completes it, on error, or when ctx
+// is done. Results are queued for each subscription until they are
+// received, so a slow subscriber does not hold back the others sharing
+// its connection, but its queue grows as long as it is not read.
+//
+// With WithTransportFallback, subscriptions whose WebSocket handshake is
+// refused are made over Server-Sent Events instead.
+//
+// Subscriptions with the same URL and headers share one connection,
+// opened by the first one and closed once the last one ends. Each is
+// completed independently. The connection is opened with the client's
+// default headers and the request headers, so the usual authentication
+// works.
+//
+//	payloads, errs, err := client.Subscribe(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	for p := range payloads {
+//	    ...
+//	}
+//	if err := <-errs; err != nil {
+//	    return err
+//	}
+func (c *Client) Subscribe(ctx context.Context, req *Request) (<-chan SubscriptionPayload, <-chan error, error) {
+	req = req.Clone()
+	if c.impersonation != nil {
+		opCtx, _ := c.operation(ctx, req)
+		if err := c.impersonation.audit(opCtx, req); err != nil {
+			return nil, nil, err
+		}
+	}
+	for {
+		if c.appSync == nil && !c.useTransport(ctx, req, transportWebSocket) {
+			return c.subscribeSSE(ctx, req)
+		}
+		conn, err := c.subscriptionConn(ctx, req)
+		if err != nil {
+			if c.appSync == nil && c.fallBack(ctx, req, transportWebSocket, 0, err) {
+				return c.subscribeSSE(ctx, req)
+			}
+			return nil, nil, err
+		}
+		c.transportWorked(ctx, req, transportWebSocket)
+		sub, err := conn.subscribe(ctx, req)
+		if errors.Is(err, errSubConnClosed) {
+			continue // closed since, dial another
+		}
+		if err != nil {
+			conn.closeIfIdle()
+			return nil, nil, err
+		}
+		return sub.payloads, sub.errs, nil
+	}
+}
+
+// subscriptionURL returns the WebSocket URL for subscriptions.
+func (c *Client) subscriptionURL(ctx context.Context, req *Request) (string, error) {
+	if c.subscriptionEndpoint != "" {
+		return c.subscriptionEndpoint, nil
+	}
+	endpoint, err := c.endpoint(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if c.appSync != nil {
+		return appSyncRealtimeURL(endpoint)
+	}
+	switch {
+	case strings.HasPrefix(endpoint, "http://"):
+		return "ws://" + endpoint[len("http://"):], nil
+	case strings.HasPrefix(endpoint, "https://"):
+		return "wss://" + endpoint[len("https://"):], nil
+	}
+	return endpoint, nil
+}
+
+// wsHTTPClient returns the http.Client used for WebSocket handshakes: the
+// client's own if it is an *http.Client, without its timeout, which would
+// cut long-lived connections.
+func (c *Client) wsHTTPClient() *http.Client {
+	base := c.httpClient
+	if h, ok := base.(*harClient); ok {
+		base = h.next
+	}
+	if h, ok := base.(*http3Client); ok {
+		base = h.next
+	}
+	hc, ok := base.(*http.Client)
+	if !ok {
+		return &http.Client{}
+	}
+	clone := *hc
+	clone.Timeout = 0
+	return &clone
+}
+
+// subscriptionConn returns an open connection for req, shared with the
+// other subscriptions to the same URL with the same headers.
+func (c *Client) subscriptionConn(ctx context.Context, req *Request) (*subConn, error) {
+	url, err := c.subscriptionURL(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	c.setHeaders(ctx, r, req)
+	return c.subConns.get(ctx, subConnKey(url, r.Header), func() (*subConn, error) {
+		if c.appSync != nil {
+			connectURL, err := c.appSyncConnectURL(ctx, req, url)
+			if err != nil {
+				return nil, err
+			}
+			return c.dialSubscriptions(ctx, connectURL, r.Header)
+		}
+		return c.dialSubscriptions(ctx, url, r.Header)
+	})
+}
+
+// subConnKey identifies the connections that can be shared. Trace
+// headers, which differ for every operation, are left out.
+func subConnKey(url string, header http.Header) string {
+	header = header.Clone()
+	for _, name := range []string{"Traceparent", "Tracestate", "Baggage"} {
+		header.Del(name)
+	}
+	var b strings.Builder
+	b.WriteString(url + "\n")
+	header.Write(&b)
+	return b.String()
+}
+
+// subConnPool holds the open subscription connections of a Client.
+type subConnPool struct {
+	mu      sync.Mutex
+	conns   map[string]*subConn
+	dialing map[string]*subDial
+}
+
+// subDial is a connection being dialed.
+type subDial struct {
+	done chan struct{}
+	err  error
+}
+
+// get returns the open connection for key, or dials one. Concurrent
+// callers wait for a single dial.
+func (pool *subConnPool) get(ctx context.Context, key string, dial func() (*subConn, error)) (*subConn, error) {
+	for {
+		pool.mu.Lock()
+		if conn := pool.conns[key]; conn != nil && !conn.isClosed() && !conn.draining.Load() {
+			pool.mu.Unlock()
+			return conn, nil
+		}
+		if d := pool.dialing[key]; d != nil {
+			pool.mu.Unlock()
+			select {
+			case <-d.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if d.err != nil && !errors.Is(d.err, context.Canceled) {
+				return nil, d.err
+			}
+			continue
+		}
+		d := &subDial{done: make(chan struct{})}
+		if pool.dialing == nil {
+			pool.conns = make(map[string]*subConn)
+			pool.dialing = make(map[string]*subDial)
+		}
+		pool.dialing[key] = d
+		pool.mu.Unlock()
+
+		conn, err := dial()
+		pool.mu.Lock()
+		delete(pool.dialing, key)
+		if err == nil {
+			pool.conns[key] = conn
+		} else {
+			delete(pool.conns, key)
+		}
+		pool.mu.Unlock()
+		d.err = err
+		close(d.done)
+		return conn, err
+	}
+}
+
+// dialSubscriptions opens a connection and waits for connection_ack.
+func (c *Client) dialSubscriptions(ctx context.Context, url string, header http.Header) (*subConn, error) {
+	protocols := c.subscriptionProtocols
+	if c.appSync != nil {
+		protocols = []string{SubscriptionsTransportWS}
+	} else if len(protocols) == 0 {
+		protocols = []string{GraphQLTransportWS, SubscriptionsTransportWS}
+	}
+	ws, err := dialWebSocket(ctx, c.wsHTTPClient(), url, header, protocols)
+	if err != nil {
+		return nil, err
+	}
+	proto := subProtocols[ws.protocol]
+	if proto == nil {
+		if ws.protocol != "" || subProtocols[protocols[0]] == nil {
+			ws.close(1002, "")
+			return nil, fmt.Errorf("%w: unsupported subprotocol %q", ErrWebSocketHandshake, ws.protocol)
+		}
+		proto = subProtocols[protocols[0]]
+	}
+	if c.appSync != nil {
+		proto = appSyncProtocol
+	}
+	// the query of AppSync URLs carries the authorization
+	bareURL, _, _ := strings.Cut(url, "?")
+	conn := &subConn{client: c, ws: ws, proto: proto, url: bareURL, subs: make(map[string]*subscription)}
+	if err := conn.init(ctx); err != nil {
+		ws.close(1000, "")
+		return nil, err
+	}
+	c.emit(Event{Type: EventConnectionOpened, URL: bareURL})
+	conn.done = make(chan struct{})
+	go conn.readLoop()
+	if c.keepAlive > 0 {
+		go conn.keepAlive(c.keepAlive)
+	}
+	if c.idleTimeout > 0 {
+		go conn.watchIdle(c.idleTimeout)
+	}
+	if c.authRefresh != nil {
+		go conn.refreshAuth(c.authRefresh)
+	}
+	return conn, nil
+}
+
+// wsMessage is a message of the subscription protocols.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// subConn is a subscription protocol connection carrying any number of
+// subscriptions.
+type subConn struct {
+	client *Client
+	ws     *wsConn
+	proto  *subProtocol
+	url    string
+
+	mu     sync.Mutex
+	subs   map[string]*subscription
+	nextID int
+	closed bool
+	// timedOut is set when keepalive pings went unanswered.
+	timedOut atomic.Bool
+	// idle is set when nothing was read for the stream idle timeout.
+	idle atomic.Bool
+	// draining is set while the subscriptions move to a new connection,
+	// which must not be this one.
+	draining atomic.Bool
+	// done is closed once the connection failed or was closed.
+	done chan struct{}
+}
+
+func (conn *subConn) send(msg wsMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.ws.writeMessage(b)
+}
+
+// init sends connection_init and waits for connection_ack.
+func (conn *subConn) init(ctx context.Context) error {
+	payload, err := conn.initPayload(ctx)
+	if err != nil {
+		return err
+	}
+	if err := conn.send(wsMessage{Type: "connection_init", Payload: payload}); err != nil {
+		return err
+	}
+	acked := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := conn.read()
+			if err != nil {
+				acked <- err
+				return
+			}
+			switch msg.Type {
+			case "connection_ack":
+				acked <- nil
+				return
+			case "ping":
+				conn.send(wsMessage{Type: "pong"})
+			}
+		}
+	}()
+	timeout := conn.client.connectionAckTimeout
+	if timeout <= 0 {
+		timeout = DefaultConnectionAckTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-acked:
+		return err
+	case <-timer.C:
+		conn.ws.close(4408, "Connection initialisation timeout")
+		return ErrConnectionAckTimeout
+	case <-ctx.Done():
+		conn.ws.close(1000, "")
+		return ctx.Err()
+	}
+}
+
+func (conn *subConn) read() (wsMessage, error) {
+	var msg wsMessage
+	b, err := conn.ws.readMessage()
+	if err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return msg, errors.Join(ErrDecodingResponse, err)
+	}
+	return msg, nil
+}
+
+// subscribe starts req on the connection.
+func (conn *subConn) subscribe(ctx context.Context, req *Request) (*subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := newSubscription(ctx, cancel, req)
+	sub.handler = conn.client.subscriptionHandler(req, sub)
+	if err := conn.start(sub); err != nil {
+		cancel()
+		sub.end(nil)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// start sends the operation of sub to the server under a new id.
+func (conn *subConn) start(sub *subscription) error {
+	ids, err := conn.register(sub)
+	if err != nil {
+		return err
+	}
+	if err := conn.sendSubscribe(sub.ctx, ids[0], sub.req); err != nil {
+		conn.remove(ids[0])
+		return err
+	}
+	return nil
+}
+
+// register adds subs to the connection under new ids, all or none, and
+// stops each one on the server once its context is done.
+func (conn *subConn) register(subs ...*subscription) ([]string, error) {
+	conn.mu.Lock()
+	if conn.closed {
+		conn.mu.Unlock()
+		return nil, errSubConnClosed
+	}
+	ids := make([]string, len(subs))
+	for i, sub := range subs {
+		conn.nextID++
+		ids[i] = strconv.Itoa(conn.nextID)
+		conn.subs[ids[i]] = sub
+	}
+	conn.mu.Unlock()
+	for i, sub := range subs {
+		go func(id string, sub *subscription) {
+			<-sub.ctx.Done()
+			if conn.remove(id) {
+				conn.send(wsMessage{ID: id, Type: conn.proto.stop})
+			}
+			sub.end(nil)
+			conn.closeIfIdle()
+		}(ids[i], sub)
+	}
+	return ids, nil
+}
+
+func (conn *subConn) sendSubscribe(ctx context.Context, id string, req *Request) error {
+	payload := map[string]interface{}{"query": req.q}
+	if req.vars != nil {
+		payload["variables"] = req.vars
+	}
+	if _, name := operationInfo(req.q); name != "" {
+		payload["operationName"] = name
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	if conn.proto == appSyncProtocol {
+		if b, err = conn.client.appSyncStart(ctx, req, b); err != nil {
+			return err
+		}
+	}
+	return conn.send(wsMessage{ID: id, Type: conn.proto.subscribe, Payload: b})
+}
+
+// remove forgets the subscription id and reports whether it was active.
+func (conn *subConn) remove(id string) bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	_, ok := conn.subs[id]
+	delete(conn.subs, id)
+	return ok
+}
+
+func (conn *subConn) lookup(id string) *subscription {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.subs[id]
+}
+
+// closeIfIdle closes the connection once it carries no subscription.
+func (conn *subConn) closeIfIdle() {
+	conn.mu.Lock()
+	idle := len(conn.subs) == 0 && !conn.closed
+	if idle {
+		conn.closed = true
+	}
+	conn.mu.Unlock()
+	if idle {
+		conn.shutdown()
+	}
+}
+
+func (conn *subConn) isClosed() bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.closed
+}
+
+func (conn *subConn) close() {
+	conn.mu.Lock()
+	conn.closed = true
+	conn.mu.Unlock()
+	conn.shutdown()
+}
+
+// shutdown ends the protocol session and closes the connection.
+func (conn *subConn) shutdown() {
+	if conn.proto.terminate != "" {
+		conn.send(wsMessage{Type: conn.proto.terminate})
+	}
+	conn.ws.close(1000, "")
+}
+
+// readLoop dispatches server messages to the subscriptions until the
+// connection fails or is closed, then ends the remaining subscriptions.
+func (conn *subConn) readLoop() {
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			if conn.timedOut.Load() {
+				err = ErrKeepAliveTimeout
+			} else if conn.idle.Load() {
+				err = ErrIdleTimeout
+			}
+			conn.fail(err)
+			return
+		}
+		switch msg.Type {
+		case "ping":
+			conn.send(wsMessage{Type: "pong"})
+		case conn.proto.next:
+			if sub := conn.lookup(msg.ID); sub != nil {
+				var p SubscriptionPayload
+				if err := json.Unmarshal(msg.Payload, &p); err != nil {
+					conn.finish(msg.ID, errors.Join(ErrDecodingResponse, err), true)
+					continue
+				}
+				if sub.handler == nil {
+					sub.deliver(p)
+				} else if err := sub.handler(sub.ctx, p); err != nil {
+					conn.finish(msg.ID, err, true)
+				}
+			}
+		case "error":
+			conn.finish(msg.ID, payloadErrors(msg.Payload), false)
+		case "connection_error":
+			// legacy protocol: the connection is unusable
+			conn.ws.rwc.Close()
+			conn.fail(payloadErrors(msg.Payload))
+			return
+		case "complete":
+			conn.finish(msg.ID, nil, false)
+		}
+	}
+}
+
+// keepAlive pings the server every interval until the connection is
+// closed, and closes it once nothing was read for two intervals.
+func (conn *subConn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+		if time.Since(time.Unix(0, conn.ws.lastRead.Load())) >= 2*interval {
+			conn.timedOut.Store(true)
+			conn.ws.rwc.Close()
+			return
+		}
+		if conn.proto.ping != "" {
+			conn.send(wsMessage{Type: conn.proto.ping})
+		} else {
+			conn.ws.writeFrame(wsPing, nil)
+		}
+	}
+}
+
+// fail closes the connection and ends every subscription with err,
+// unless the connection was closed on purpose, or they are replayed on a
+// new connection with WithSubscriptionReconnect.
+func (conn *subConn) fail(err error) {
+	conn.mu.Lock()
+	closed := conn.closed
+	conn.closed = true
+	subs := conn.subs
+	conn.subs = make(map[string]*subscription)
+	conn.mu.Unlock()
+	conn.ws.rwc.Close()
+	close(conn.done)
+	if closed {
+		err = nil
+	}
+	conn.client.emit(Event{Type: EventConnectionClosed, URL: conn.url, Err: err})
+	if err != nil && len(subs) > 0 && conn.client.reconnect != nil && reconnectable(err) {
+		go conn.client.resubscribe(subs, err)
+		return
+	}
+	for _, sub := range subs {
+		sub.end(err)
+	}
+}
+
+// payloadErrors decodes the payload of an error message, a list of
+// GraphQL errors or, in the legacy protocol, possibly a single one, or
+// with AppSync an object with the list in its errors field.
+func payloadErrors(payload json.RawMessage) error {
+	var errs GraphQLErrors
+	if err := json.Unmarshal(payload, &errs); err == nil && len(errs) > 0 {
+		return errs
+	}
+	var wrapped struct {
+		Errors GraphQLErrors `json:"errors"`
+	}
+	if err := json.Unmarshal(payload, &wrapped); err == nil && len(wrapped.Errors) > 0 {
+		return wrapped.Errors
+	}
+	var single GraphQLError
+	if err := json.Unmarshal(payload, &single); err == nil && single.Message != "" {
+		return GraphQLErrors{single}
+	}
+	return GraphQLErrors{{Message: fmt.Sprintf("subscription error: %s", payload)}}
+}
+
+// finish ends the subscription id, telling the server if it is still
+// running there.
+func (conn *subConn) finish(id string, err error, notify bool) {
+	sub := conn.lookup(id)
+	if sub == nil || !conn.remove(id) {
+		return
+	}
+	if notify {
+		conn.send(wsMessage{ID: id, Type: conn.proto.stop})
+	}
+	sub.end(err)
+}
+
+// subscription is one operation running on a subConn.
+type subscription struct {
+	req      *Request
+	ctx      context.Context
+	cancel   context.CancelFunc
+	payloads chan SubscriptionPayload
+	errs     chan error
+	// handler runs the subscription middleware, if any.
+	handler SubscriptionHandler
+
+	mu    sync.Mutex
+	ended bool
+	// queue holds the payloads the subscriber has not received yet, so
+	// the connection is never held back by a slow subscriber.
+	queue  []SubscriptionPayload
+	endErr error
+	// wake tells the delivery goroutine that the queue changed.
+	wake chan struct{}
+}
+
+// newSubscription returns a subscription to req, delivering its payloads
+// until it ends.
+func newSubscription(ctx context.Context, cancel context.CancelFunc, req *Request) *subscription {
+	s := &subscription{
+		req:      req,
+		ctx:      ctx,
+		cancel:   cancel,
+		payloads: make(chan SubscriptionPayload),
+		errs:     make(chan error, 1),
+		wake:     make(chan struct{}, 1),
+	}
+	go s.run()
+	return s
+}
+
+// deliver queues p for the subscriber, unless the subscription ended.
+func (s *subscription) deliver(p SubscriptionPayload) {
+	s.mu.Lock()
+	if !s.ended {
+		s.queue = append(s.queue, p)
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// end closes the channels of the subscription once the queued payloads
+// were received, after sending err, if any, and then cancels its
+// context.
+func (s *subscription) end(err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended, s.endErr = true, err
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *subscription) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued payloads to the subscriber, dropping them once the
+// context is done, and closes the channels when the subscription ends.
+func (s *subscription) run() {
+	defer s.cancel()
+	for {
+		s.mu.Lock()
+		if s.ctx.Err() != nil {
+			s.queue = nil
+		}
+		if len(s.queue) == 0 {
+			ended, err := s.ended, s.endErr
+			s.mu.Unlock()
+			if ended {
+				if err != nil {
+					s.errs <- err
+				}
+				close(s.payloads)
+				close(s.errs)
+				return
+			}
+			select {
+			case <-s.wake:
+			case <-s.ctx.Done():
+				// wait for the end
+				<-s.wake
+			}
+			continue
+		}
+		p := s.queue[0]
+		s.queue[0] = SubscriptionPayload{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		select {
+		case s.payloads <- p:
+		case <-s.ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// wsServer starts a WebSocket server running handle for every connection.
+func wsServer(t *testing.T, protocols []string, handle func(r *http.Request, conn *wsConn)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := acceptWebSocket(w, r, protocols)
+		if err != nil {
+			t.Log(err)
+			return
+		}
+		defer conn.rwc.Close()
+		handle(r, conn)
+	}))
+}
+
+func readWSMessage(conn *wsConn) (wsMessage, error) {
+	var msg wsMessage
+	b, err := conn.readMessage()
+	if err != nil {
+		return msg, err
+	}
+	return msg, json.Unmarshal(b, &msg)
+}
+
+func writeWSMessage(conn *wsConn, msg string) error {
+	return conn.writeMessage([]byte(msg))
+}
+
+func TestSubscribe(t *testing.T) {
+	is := is.New(t)
+	completed := make(chan string, 1)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		is.Equal(conn.protocol, GraphQLTransportWS)
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(msg.Type, "connection_init")
+		writeWSMessage(conn, `{"type":"ping"}`)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err = readWSMessage(conn)
+		is.NoErr(err)
+		if msg.Type == "pong" {
+			msg, err = readWSMessage(conn)
+			is.NoErr(err)
+		}
+		is.Equal(msg.Type, "subscribe")
+		var payload struct {
+			Query         string
+			Variables     map[string]interface{}
+			OperationName string
+		}
+		is.NoErr(json.Unmarshal(msg.Payload, &payload))
+		is.Equal(payload.OperationName, "OnMessage")
+		is.Equal(payload.Variables["room"], "general")
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"message":"hello"}}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"message":"world"},"errors":[{"message":"partial"}]}}`)
+		msg, err = readWSMessage(conn)
+		is.NoErr(err)
+		completed <- msg.Type
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithDefaultHeaders(http.Header{"Authorization": {"Bearer token"}}))
+	req := NewRequest("subscription OnMessage($room: String!) { message(room: $room) }")
+	req.Var("room", "general")
+	subCtx, stop := context.WithCancel(ctx)
+	payloads, errs, err := client.Subscribe(subCtx, req)
+	is.NoErr(err)
+	p := <-payloads
+	is.Equal(string(p.Data), `{"message":"hello"}`)
+	p = <-payloads
+	is.Equal(string(p.Data), `{"message":"world"}`)
+	is.Equal(p.Errors.Error(), "graphql: partial")
+	stop()
+	_, ok := <-payloads
+	is.True(!ok)
+	is.NoErr(<-errs)
+	is.Equal(<-completed, "complete")
+}
+
+func TestSubscribeServerError(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, _ := readWSMessage(conn)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"error","payload":[{"message":"unknown field"}]}`)
+		readWSMessage(conn) // until closed
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	payloads, errs, err := NewClient(srv.URL).Subscribe(ctx, NewRequest("subscription { nope }"))
+	is.NoErr(err)
+	_, ok := <-payloads
+	is.True(!ok)
+	err = <-errs
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+	is.Equal(gqlErrs[0].Message, "unknown field")
+}
+
+func TestSubscribeConnectionClosed(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		readWSMessage(conn)
+		conn.close(4403, "Forbidden")
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	_, errs, err := NewClient(srv.URL).Subscribe(ctx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	var closeErr *WebSocketCloseError
+	is.True(errors.As(<-errs, &closeErr))
+	is.Equal(closeErr.Code, 4403)
+	is.Equal(closeErr.Reason, "Forbidden")
+}
+
+func TestSubscribeHandshakeRejected(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	_, _, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { a }"))
+	is.True(errors.Is(err, ErrWebSocketHandshake))
+}
+
+func TestSubscribeLegacyProtocol(t *testing.T) {
+	is := is.New(t)
+	done := make(chan []string, 1)
+	srv := wsServer(t, []string{SubscriptionsTransportWS}, func(r *http.Request, conn *wsConn) {
+		is.Equal(conn.protocol, SubscriptionsTransportWS)
+		var types []string
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		types = append(types, msg.Type)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		writeWSMessage(conn, `{"type":"ka"}`)
+		msg, err = readWSMessage(conn)
+		is.NoErr(err)
+		types = append(types, msg.Type)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"data","payload":{"data":{"n":1}}}`)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"error","payload":{"message":"boom"}}`)
+		for {
+			msg, err := readWSMessage(conn)
+			if err != nil {
+				break
+			}
+			types = append(types, msg.Type)
+		}
+		done <- types
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	payloads, errs, err := NewClient(srv.URL).Subscribe(ctx, NewRequest("subscription { n }"))
+	is.NoErr(err)
+	p := <-payloads
+	is.Equal(string(p.Data), `{"n":1}`)
+	_, ok := <-payloads
+	is.True(!ok)
+	var gqlErrs GraphQLErrors
+	is.True(errors.As(<-errs, &gqlErrs))
+	is.Equal(gqlErrs[0].Message, "boom")
+	is.Equal(<-done, []string{"connection_init", "start", "connection_terminate"})
+}
+
+func TestSubscriptionProtocols(t *testing.T) {
+	is := is.New(t)
+	_, err := NewClientE("http://localhost", WithSubscriptionProtocols("graphql-sse"))
+	is.True(errors.Is(err, ErrInvalidOption))
+}
+
+func TestSubscribeMultiplexed(t *testing.T) {
+	is := is.New(t)
+	var connections atomic.Int32
+	completed := make(chan string, 3)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		connections.Add(1)
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		for {
+			msg, err := readWSMessage(conn)
+			if err != nil {
+				return
+			}
+			switch msg.Type {
+			case "subscribe":
+				writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":{"id":"`+msg.ID+`"}}}`)
+			case "complete":
+				completed <- msg.ID
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	var stops []context.CancelFunc
+	var streams []<-chan SubscriptionPayload
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subCtx, stop := context.WithCancel(ctx)
+			payloads, _, err := client.Subscribe(subCtx, NewRequest("subscription { id }"))
+			is.NoErr(err)
+			mu.Lock()
+			stops = append(stops, stop)
+			streams = append(streams, payloads)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	ids := map[string]bool{}
+	for _, payloads := range streams {
+		p := <-payloads
+		ids[string(p.Data)] = true
+	}
+	is.Equal(len(ids), 3) // one id per subscription
+	is.Equal(connections.Load(), int32(1))
+
+	stops[0]()
+	<-completed
+	_, ok := <-streams[0]
+	is.True(!ok)
+	stops[1]()
+	stops[2]()
+	<-completed
+	<-completed
+}
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	is := is.New(t)
+	pong := make(chan struct{})
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		var ids []string
+		for {
+			msg, err := readWSMessage(conn)
+			if err != nil {
+				return
+			}
+			switch msg.Type {
+			case "subscribe":
+				ids = append(ids, msg.ID)
+				if len(ids) < 2 {
+					continue
+				}
+				for i := 0; i < 5; i++ {
+					for _, id := range ids {
+						writeWSMessage(conn, `{"id":"`+id+`","type":"next","payload":{"data":{"n":`+strconv.Itoa(i)+`}}}`)
+					}
+				}
+				writeWSMessage(conn, `{"type":"ping"}`)
+			case "pong":
+				close(pong)
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+	_, _, err := client.Subscribe(ctx, NewRequest("subscription { n }"))
+	is.NoErr(err)
+	active, _, err := client.Subscribe(ctx, NewRequest("subscription { n }"))
+	is.NoErr(err)
+	for i := 0; i < 5; i++ {
+		select {
+		case p := <-active:
+			is.Equal(string(p.Data), `{"n":`+strconv.Itoa(i)+`}`)
+		case <-ctx.Done():
+			t.Fatal("active subscription held back by the stalled one")
+		}
+	}
+	select {
+	case <-pong:
+	case <-ctx.Done():
+		t.Fatal("ping not answered")
+	}
+}
+
+func TestSubscriptionConnectionInit(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(string(msg.Payload), `{"headers":{"Authorization":"Bearer token"}}`)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, _ = readWSMessage(conn)
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"complete"}`)
+		readWSMessage(conn)
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithConnectionInitPayload(map[string]interface{}{
+		"headers": map[string]string{"Authorization": "Bearer token"},
+	}))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	for range payloads {
+	}
+	is.NoErr(<-errs)
+}
+
+func TestSubscriptionAckTimeout(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		readWSMessage(conn) // never ack
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithConnectionAckTimeout(20*time.Millisecond))
+	_, _, err := client.Subscribe(ctx, NewRequest("subscription { a }"))
+	is.True(errors.Is(err, ErrConnectionAckTimeout))
+}
+
+func TestSubscriptionKeepAlive(t *testing.T) {
+	is := is.New(t)
+	pings := make(chan string, 10)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		for {
+			msg, err := readWSMessage(conn)
+			if err != nil {
+				return
+			}
+			if msg.Type == "ping" {
+				pings <- msg.Type // never answered
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithSubscriptionKeepAlive(20*time.Millisecond))
+	payloads, errs, err := client.Subscribe(ctx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	for range payloads {
+	}
+	is.True(errors.Is(<-errs, ErrKeepAliveTimeout))
+	is.Equal(<-pings, "ping")
+}
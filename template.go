@@ -0,0 +1,199 @@
+package gographql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var nameRe = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// TemplateFuncs are the GraphQL-aware helpers available to templates
+// rendered by NewRequestFromTemplate:
+//
+//	gqlName   validates and writes a name (field, type, enum value, ...)
+//	gqlFields validates and writes a list of field names
+//	gqlString writes a quoted and escaped string literal
+//	gqlValue  writes any Go value as a GraphQL input value literal
+//
+// Values should still be passed as variables whenever possible; the
+// helpers exist for the parts of a document that variables cannot express.
+var TemplateFuncs = template.FuncMap{
+	"gqlName":   gqlName,
+	"gqlFields": gqlFields,
+	"gqlString": gqlString,
+	"gqlValue":  gqlValue,
+}
+
+// NewRequestFromTemplate makes a new Request from a text/template
+// document, for the rare cases where the structure of the document must
+// vary at runtime:
+//
+//	req, err := gographql.NewRequestFromTemplate(`
+//	    query ($id: ID!) {
+//	        user(id: $id) { {{ gqlFields .Fields }} }
+//	    }
+//	`, map[string]interface{}{"Fields": []string{"id", "name"}})
+//
+// Interpolating data with plain actions writes it verbatim; use the
+// helpers in TemplateFuncs to have it validated or escaped.
+func NewRequestFromTemplate(tpl string, data interface{}) (*Request, error) {
+	t, err := template.New("graphql").Funcs(TemplateFuncs).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return NewRequest(b.String()), nil
+}
+
+func gqlName(name string) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid GraphQL name %q", name)
+	}
+	return name, nil
+}
+
+func gqlFields(fields []string) (string, error) {
+	for _, field := range fields {
+		if _, err := gqlName(field); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+func gqlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func gqlValue(v interface{}) (string, error) {
+	var b strings.Builder
+	if err := writeValue(&b, reflect.ValueOf(v)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeValue(b *strings.Builder, v reflect.Value) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("null")
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("cannot render %v as a GraphQL value", f)
+		}
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	case reflect.String:
+		b.WriteString(gqlString(v.String()))
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeValue(b, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot render map with %s keys", v.Type().Key())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, key := range keys {
+			if _, err := gqlName(key); err != nil {
+				return err
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(key)
+			b.WriteString(": ")
+			if err := writeValue(b, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case reflect.Struct:
+		b.WriteByte('{')
+		first := true
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				tagName, opts, _ := strings.Cut(tag, ",")
+				if tagName == "-" {
+					continue
+				}
+				if hasTagOption(opts, "omitempty") && v.Field(i).IsZero() {
+					continue
+				}
+				if tagName != "" {
+					name = tagName
+				}
+			}
+			if _, err := gqlName(name); err != nil {
+				return err
+			}
+			if !first {
+				b.WriteString(", ")
+			}
+			first = false
+			b.WriteString(name)
+			b.WriteString(": ")
+			if err := writeValue(b, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot render %s as a GraphQL value", v.Type())
+	}
+	return nil
+}
+
+// hasTagOption reports whether the comma separated options of a struct
+// tag include option.
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
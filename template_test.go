@@ -0,0 +1,41 @@
+package gographql
+
+import (
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewRequestFromTemplate(t *testing.T) {
+	is := is.New(t)
+	req, err := NewRequestFromTemplate(
+		`query { user(name: {{ gqlString .Name }}, filter: {{ gqlValue .Filter }}) { {{ gqlFields .Fields }} } }`,
+		map[string]interface{}{
+			"Name":   `Mat "the" Ryer`,
+			"Fields": []string{"id", "name"},
+			"Filter": struct {
+				Active bool     `json:"active"`
+				Tags   []string `json:"tags"`
+				Limit  *int     `json:"limit,omitempty"`
+				Offset int      `json:"offset,string,omitempty"`
+			}{Active: true, Tags: []string{"a"}},
+		},
+	)
+	is.NoErr(err)
+	is.Equal(req.Query(), `query { user(name: "Mat \"the\" Ryer", filter: {active: true, tags: ["a"]}) { id name } }`)
+}
+
+func TestNewRequestFromTemplateInvalidName(t *testing.T) {
+	is := is.New(t)
+	_, err := NewRequestFromTemplate(`query { {{ gqlFields .Fields }} }`, map[string]interface{}{
+		"Fields": []string{"id", "name } mutation { drop"},
+	})
+	is.True(err != nil)
+	_, err = NewRequestFromTemplate(`query { {{ .Missing }} }`, map[string]interface{}{})
+	is.True(err != nil)
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err = NewRequestFromTemplate(`query { a(f: {{ gqlValue .F }}) }`, map[string]interface{}{"F": f})
+		is.True(err != nil)
+	}
+}
@@ -0,0 +1,97 @@
+// Package testgraphql provides helpers for testing code that uses the
+// gographql client.
+package testgraphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vikramarsid/gographql"
+)
+
+// graphQLErrors extracts the GraphQL errors from err, failing t if there
+// are none.
+func graphQLErrors(t testing.TB, err error) (gographql.GraphQLErrors, bool) {
+	t.Helper()
+	var errs gographql.GraphQLErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		t.Errorf("expected GraphQL errors, got %v", err)
+		return nil, false
+	}
+	return errs, true
+}
+
+// AssertErrorCode checks that err contains a GraphQL error whose
+// extensions code is code.
+//
+//	testgraphql.AssertErrorCode(t, err, "FORBIDDEN")
+func AssertErrorCode(t testing.TB, err error, code string) {
+	t.Helper()
+	errs, ok := graphQLErrors(t, err)
+	if !ok {
+		return
+	}
+	var codes []string
+	for _, e := range errs {
+		got, _ := e.Extensions["code"].(string)
+		if got == code {
+			return
+		}
+		codes = append(codes, fmt.Sprintf("%q", got))
+	}
+	t.Errorf("expected a GraphQL error with code %q, got codes %s", code, strings.Join(codes, ", "))
+}
+
+// AssertErrorPath checks that err contains a GraphQL error at the
+// response path given by path, made of field names and list indexes.
+//
+//	testgraphql.AssertErrorPath(t, err, "user", "email")
+//	testgraphql.AssertErrorPath(t, err, "users", 2, "email")
+func AssertErrorPath(t testing.TB, err error, path ...interface{}) {
+	t.Helper()
+	errs, ok := graphQLErrors(t, err)
+	if !ok {
+		return
+	}
+	want := formatPath(path)
+	var paths []string
+	for _, e := range errs {
+		got := formatPath(e.Path)
+		if got == want {
+			return
+		}
+		paths = append(paths, got)
+	}
+	t.Errorf("expected a GraphQL error at %s, got errors at %s", want, strings.Join(paths, ", "))
+}
+
+// AssertErrorMessage checks that err contains a GraphQL error whose
+// message contains substr.
+func AssertErrorMessage(t testing.TB, err error, substr string) {
+	t.Helper()
+	errs, ok := graphQLErrors(t, err)
+	if !ok {
+		return
+	}
+	for _, e := range errs {
+		if strings.Contains(e.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("expected a GraphQL error containing %q, got %v", substr, errs)
+}
+
+// formatPath renders a response path, whose indexes may have been
+// decoded from JSON as float64, as "users.2.email".
+func formatPath(path []interface{}) string {
+	if len(path) == 0 {
+		return "(no path)"
+	}
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,48 @@
+package testgraphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/vikramarsid/gographql"
+)
+
+// recorder is a testing.TB recording failures instead of failing.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	is := is.New(t)
+	var errs gographql.GraphQLErrors
+	is.NoErr(json.Unmarshal([]byte(`[
+		{"message":"not allowed","path":["users",2,"email"],"extensions":{"code":"FORBIDDEN"}},
+		{"message":"not found","path":["user"]}
+	]`), &errs))
+	err := fmt.Errorf("load users: %w", errs)
+
+	r := &recorder{TB: t}
+	AssertErrorCode(r, err, "FORBIDDEN")
+	AssertErrorPath(r, err, "users", 2, "email")
+	AssertErrorPath(r, err, "user")
+	AssertErrorMessage(r, err, "not found")
+	is.Equal(r.failures, nil)
+
+	AssertErrorCode(r, err, "UNAUTHENTICATED")
+	AssertErrorPath(r, err, "user", "email")
+	AssertErrorMessage(r, fmt.Errorf("timeout"), "x")
+	is.Equal(r.failures, []string{
+		`expected a GraphQL error with code "UNAUTHENTICATED", got codes "FORBIDDEN", ""`,
+		`expected a GraphQL error at user.email, got errors at users.2.email, user`,
+		`expected GraphQL errors, got timeout`,
+	})
+}
@@ -0,0 +1,85 @@
+package testgraphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/vikramarsid/gographql"
+)
+
+// StaticResponse returns a RoundTripper answering every request with body
+// and a 200 status, after reading the request body, so benchmarks measure
+// the client rather than the network.
+func StaticResponse(body []byte) http.RoundTripper {
+	return staticResponse(body)
+}
+
+type staticResponse []byte
+
+func (s staticResponse) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Body != nil {
+		io.Copy(io.Discard, r.Body)
+		r.Body.Close()
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(s)),
+		ContentLength: int64(len(s)),
+		Request:       r,
+	}, nil
+}
+
+// NewBenchClient returns a client whose requests are answered in memory
+// with response, see StaticResponse.
+func NewBenchClient(response []byte, opts ...gographql.ClientOption) *gographql.Client {
+	opts = append([]gographql.ClientOption{
+		gographql.WithHTTPClient(&http.Client{Transport: StaticResponse(response)}),
+	}, opts...)
+	client := gographql.NewClient("http://bench.invalid/graphql", opts...)
+	client.SetLogger(nil)
+	return client
+}
+
+// BenchmarkRun runs newReq b.N times with client, decoding into the
+// value returned by newResp, which may be nil, and reports allocations.
+// With NewBenchClient, large variables or files benchmark encoding and
+// multipart bodies, and large responses benchmark decoding.
+//
+//	func BenchmarkSearch(b *testing.B) {
+//	    client := testgraphql.NewBenchClient(recordedSearchResponse)
+//	    testgraphql.BenchmarkRun(b, client, func() *gographql.Request {
+//	        return gographql.NewRequest(searchQuery)
+//	    }, func() interface{} { return new(SearchResult) })
+//	}
+func BenchmarkRun(b *testing.B, client *gographql.Client, newReq func() *gographql.Request, newResp func() interface{}) {
+	b.Helper()
+	b.ReportAllocs()
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		var resp interface{}
+		if newResp != nil {
+			resp = newResp()
+		}
+		if err := client.Run(ctx, newReq(), resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// AssertMaxAllocs checks that fn allocates at most max times on average,
+// measured with testing.AllocsPerRun, to guard against allocation
+// regressions in CI. It must not be used in parallel tests.
+//
+//	testgraphql.AssertMaxAllocs(t, 150, func() {
+//	    client.Run(ctx, req, &resp)
+//	})
+func AssertMaxAllocs(t testing.TB, max float64, fn func()) {
+	t.Helper()
+	if got := testing.AllocsPerRun(20, fn); got > max {
+		t.Errorf("expected at most %v allocations per run, got %v", max, got)
+	}
+}
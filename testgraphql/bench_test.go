@@ -0,0 +1,95 @@
+package testgraphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/vikramarsid/gographql"
+)
+
+// largeResponse is a response with n users.
+func largeResponse(n int) []byte {
+	users := make([]string, n)
+	for i := range users {
+		users[i] = fmt.Sprintf(`{"id":"%d","name":"user %d","email":"user%d@example.com"}`, i, i, i)
+	}
+	return []byte(`{"data":{"users":[` + strings.Join(users, ",") + `]}}`)
+}
+
+type usersResponse struct {
+	Users []struct {
+		ID    string
+		Name  string
+		Email string
+	}
+}
+
+func TestAssertMaxAllocs(t *testing.T) {
+	is := is.New(t)
+	client := NewBenchClient([]byte(`{"data":{"ok":true}}`))
+	ctx := context.Background()
+	run := func() {
+		is.NoErr(client.Run(ctx, gographql.NewRequest(`{ ok }`), nil))
+	}
+	r := &recorder{TB: t}
+	AssertMaxAllocs(r, 1000, run)
+	is.Equal(len(r.failures), 0)
+	AssertMaxAllocs(r, 1, run)
+	is.Equal(len(r.failures), 1)
+	is.True(strings.HasPrefix(r.failures[0], "expected at most 1 allocations per run, got "))
+}
+
+func BenchmarkRunEncode(b *testing.B) {
+	client := NewBenchClient([]byte(`{"data":{"saveUsers":true}}`))
+	input := make([]map[string]interface{}, 1000)
+	for i := range input {
+		input[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("user %d", i)}
+	}
+	BenchmarkRun(b, client, func() *gographql.Request {
+		req := gographql.NewRequest(`mutation ($users: [UserInput!]!) { saveUsers(users: $users) }`)
+		req.Var("users", input)
+		return req
+	}, nil)
+}
+
+func BenchmarkRunDecode(b *testing.B) {
+	client := NewBenchClient(largeResponse(1000))
+	BenchmarkRun(b, client, func() *gographql.Request {
+		return gographql.NewRequest(`{ users { id name email } }`)
+	}, func() interface{} { return new(usersResponse) })
+}
+
+func BenchmarkRunMultipart(b *testing.B) {
+	client := NewBenchClient([]byte(`{"data":{"upload":true}}`), gographql.UseMultipartRequestSpec())
+	content := strings.Repeat("x", 1<<20)
+	BenchmarkRun(b, client, func() *gographql.Request {
+		req := gographql.NewRequest(`mutation ($file: Upload!) { upload(file: $file) }`)
+		req.File("file", "file.txt", strings.NewReader(content))
+		return req
+	}, nil)
+}
+
+func BenchmarkRunSmall(b *testing.B) {
+	client := NewBenchClient([]byte(`{"data":{"user":{"id":"1","name":"Mat"}}}`))
+	BenchmarkRun(b, client, func() *gographql.Request {
+		req := gographql.NewRequest(`query ($id: ID!) { user(id: $id) { id name } }`)
+		req.Var("id", "1")
+		return req
+	}, func() interface{} { return new(struct{ User struct{ ID, Name string } }) })
+}
+
+func BenchmarkRunCompressed(b *testing.B) {
+	client := NewBenchClient([]byte(`{"data":{"saveUsers":true}}`), gographql.WithRequestCompression(0))
+	input := make([]map[string]interface{}, 100)
+	for i := range input {
+		input[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("user %d", i)}
+	}
+	BenchmarkRun(b, client, func() *gographql.Request {
+		req := gographql.NewRequest(`mutation ($users: [UserInput!]!) { saveUsers(users: $users) }`)
+		req.Var("users", input)
+		return req
+	}, nil)
+}
@@ -0,0 +1,55 @@
+package testgraphql
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const introspection = `{"data":{"__schema":{
+	"queryType":{"name":"Query"},
+	"mutationType":null,
+	"subscriptionType":null,
+	"types":[
+		{"kind":"OBJECT","name":"Query","fields":[
+			{"name":"user","type":{"kind":"NON_NULL","ofType":{"kind":"OBJECT","name":"User"}}},
+			{"name":"node","type":{"kind":"INTERFACE","name":"Node"}},
+			{"name":"search","type":{"kind":"NON_NULL","ofType":{"kind":"LIST","ofType":{"kind":"NON_NULL","ofType":{"kind":"UNION","name":"Result"}}}}}
+		]},
+		{"kind":"OBJECT","name":"User","fields":[
+			{"name":"id","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"ID"}}},
+			{"name":"name","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"String"}}},
+			{"name":"age","type":{"kind":"SCALAR","name":"Int"}},
+			{"name":"admin","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"Boolean"}}},
+			{"name":"role","type":{"kind":"NON_NULL","ofType":{"kind":"ENUM","name":"Role"}}}
+		]},
+		{"kind":"OBJECT","name":"Post","fields":[
+			{"name":"title","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"String"}}}
+		]},
+		{"kind":"INTERFACE","name":"Node","possibleTypes":[{"name":"User"}]},
+		{"kind":"UNION","name":"Result","possibleTypes":[{"name":"Post"},{"name":"User"}]},
+		{"kind":"ENUM","name":"Role","enumValues":[{"name":"ADMIN"},{"name":"MEMBER"}]}
+	]
+}}}`
+
+func TestFixture(t *testing.T) {
+	is := is.New(t)
+	schema, err := ParseSchema([]byte(introspection))
+	is.NoErr(err)
+
+	data, err := Fixture(schema, `
+		query Q {
+			me: user { id ...UserFields age }
+			node { id }
+			search { __typename ... on Post { title } ... on User { id } }
+		}
+		fragment UserFields on User { name admin role }
+	`, "")
+	is.NoErr(err)
+	is.Equal(string(data), `{"me":{"id":"1","name":"","admin":false,"role":"ADMIN","age":null},"node":null,"search":[{"__typename":"Post","title":""}]}`)
+
+	_, err = Fixture(schema, `{ user { email } }`, "")
+	is.Equal(err.Error(), "user: type User has no field email")
+	_, err = Fixture(schema, `mutation { a }`, "")
+	is.True(err != nil)
+}
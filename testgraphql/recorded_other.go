@@ -0,0 +1,13 @@
+//go:build !unix
+
+package testgraphql
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is not supported: files are streamed instead.
+func mapFile(f *os.File) ([]byte, func(), error) {
+	return nil, nil, errors.New("memory mapping not supported")
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package testgraphql
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapFile maps f into memory, read only.
+func mapFile(f *os.File) ([]byte, func(), error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		return nil, nil, errors.New("empty file")
+	}
+	b, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, func() { syscall.Munmap(b) }, nil
+}
@@ -0,0 +1,166 @@
+package testgraphql
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vikramarsid/gographql"
+)
+
+// Latency is a distribution of response latencies.
+type Latency func() time.Duration
+
+// FixedLatency always delays responses by d.
+func FixedLatency(d time.Duration) Latency {
+	return func() time.Duration { return d }
+}
+
+// UniformLatency delays responses by a duration uniformly distributed
+// between min and max.
+func UniformLatency(min, max time.Duration) Latency {
+	return func() time.Duration {
+		return min + time.Duration(rand.Int64N(int64(max-min)+1))
+	}
+}
+
+// NormalLatency delays responses by a normally distributed duration,
+// never negative.
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return func() time.Duration {
+		return max(0, mean+time.Duration(rand.NormFloat64()*float64(stddev)))
+	}
+}
+
+// Profile describes how the Server answers an operation.
+type Profile struct {
+	// Latency delays the responses, if set.
+	Latency Latency
+	// ErrorRate is the probability, between 0 and 1, of failing the
+	// operation.
+	ErrorRate float64
+	// StatusCode is the HTTP status of failures. When zero, failures are
+	// GraphQL errors with a 200 status.
+	StatusCode int
+	// Error is the GraphQL error of failures, "injected error" with the
+	// INTERNAL_SERVER_ERROR code when empty.
+	Error gographql.GraphQLError
+	// ResponseFile is the path of a recorded response body answering the
+	// operation instead of a generated fixture, for replaying large
+	// exports. It is memory-mapped where supported.
+	ResponseFile string
+}
+
+// Server is a mock GraphQL server answering operations with fixtures
+// generated from a schema, see Fixture, with the latency and failures of
+// per operation profiles.
+type Server struct {
+	*httptest.Server
+	schema *Schema
+
+	mu       sync.Mutex
+	profiles map[string]Profile
+}
+
+// NewServer starts a Server for schema, closed when the test ends.
+//
+//	srv := testgraphql.NewServer(t, schema)
+//	srv.SetProfile("Search", testgraphql.Profile{
+//	    Latency:   testgraphql.NormalLatency(200*time.Millisecond, 50*time.Millisecond),
+//	    ErrorRate: 0.05,
+//	})
+//	client := gographql.NewClient(srv.URL)
+func NewServer(t testing.TB, schema *Schema) *Server {
+	s := &Server{schema: schema, profiles: make(map[string]Profile)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// SetProfile sets the profile of the operations called operationName, or
+// the default profile of operations without their own when operationName
+// is empty.
+func (s *Server) SetProfile(operationName string, p Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[operationName] = p
+}
+
+func (s *Server) profile(operationName string) Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.profiles[operationName]; ok {
+		return p
+	}
+	return s.profiles[""]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if r.Method == http.MethodGet {
+		body.Query = r.URL.Query().Get("query")
+		body.OperationName = r.URL.Query().Get("operationName")
+	} else {
+		b, err := io.ReadAll(r.Body)
+		if err != nil || json.Unmarshal(b, &body) != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	name := body.OperationName
+	if name == "" {
+		if doc, err := gographql.ParseDocument(body.Query); err == nil {
+			if op := doc.Operation(""); op != nil {
+				name = op.Name
+			}
+		}
+	}
+	p := s.profile(name)
+	if p.Latency != nil {
+		select {
+		case <-time.After(p.Latency()):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if p.ErrorRate > 0 && rand.Float64() < p.ErrorRate {
+		gqlErr := p.Error
+		if gqlErr.Message == "" {
+			gqlErr = gographql.GraphQLError{
+				Message:    "injected error",
+				Extensions: map[string]interface{}{"code": "INTERNAL_SERVER_ERROR"},
+			}
+		}
+		if p.StatusCode != 0 {
+			w.WriteHeader(p.StatusCode)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":   nil,
+			"errors": []gographql.GraphQLError{gqlErr},
+		})
+		return
+	}
+	if p.ResponseFile != "" {
+		serveFile(w, p.ResponseFile)
+		return
+	}
+	data, err := Fixture(s.schema, body.Query, body.OperationName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": []map[string]string{{"message": err.Error()}},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]json.RawMessage{"data": data})
+}
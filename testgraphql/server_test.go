@@ -0,0 +1,82 @@
+package testgraphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/vikramarsid/gographql"
+)
+
+func TestServer(t *testing.T) {
+	is := is.New(t)
+	schema, err := ParseSchema([]byte(introspection))
+	is.NoErr(err)
+	srv := NewServer(t, schema)
+	srv.SetProfile("Slow", Profile{Latency: FixedLatency(50 * time.Millisecond)})
+	srv.SetProfile("Broken", Profile{ErrorRate: 1, Error: gographql.GraphQLError{Message: "down"}})
+	srv.SetProfile("Gone", Profile{ErrorRate: 1, StatusCode: 503})
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := gographql.NewClient(srv.URL)
+	var resp struct {
+		User struct{ Name string }
+	}
+	is.NoErr(client.Run(ctx, gographql.NewRequest(`query Fast { user { name } }`), &resp))
+
+	start := time.Now()
+	is.NoErr(client.Run(ctx, gographql.NewRequest(`query Slow { user { name } }`), &resp))
+	is.True(time.Since(start) >= 50*time.Millisecond)
+
+	err = client.Run(ctx, gographql.NewRequest(`query Broken { user { name } }`), &resp)
+	is.Equal(err.Error(), "graphql: down")
+
+	err = client.Run(ctx, gographql.NewRequest(`query Gone { user { name } }`), &resp)
+	var gqlErrs gographql.GraphQLErrors
+	is.True(errors.As(err, &gqlErrs))
+	is.Equal(gqlErrs[0].Extensions["code"], "INTERNAL_SERVER_ERROR")
+}
+
+func TestLatencyDistributions(t *testing.T) {
+	is := is.New(t)
+	for range 100 {
+		d := UniformLatency(10*time.Millisecond, 20*time.Millisecond)()
+		is.True(d >= 10*time.Millisecond && d <= 20*time.Millisecond)
+		is.True(NormalLatency(0, time.Second)() >= 0)
+	}
+}
+
+func TestServerResponseFile(t *testing.T) {
+	is := is.New(t)
+	schema, err := ParseSchema([]byte(introspection))
+	is.NoErr(err)
+	path := filepath.Join(t.TempDir(), "export.json")
+	var body strings.Builder
+	body.WriteString(`{"data":{"users":[`)
+	for i := range 10000 {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		fmt.Fprintf(&body, `{"name":"user %d"}`, i)
+	}
+	body.WriteString(`]}}`)
+	is.NoErr(os.WriteFile(path, []byte(body.String()), 0o600))
+	srv := NewServer(t, schema)
+	srv.SetProfile("Export", Profile{ResponseFile: path})
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp struct {
+		Users []struct{ Name string }
+	}
+	is.NoErr(gographql.NewClient(srv.URL).Run(ctx, gographql.NewRequest(`query Export { users { name } }`), &resp))
+	is.Equal(len(resp.Users), 10000)
+	is.Equal(resp.Users[9999].Name, "user 9999")
+}
@@ -0,0 +1,119 @@
+package gographql
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks down the latency of an HTTP exchange, to tell network
+// slowness from server slowness. Phases that did not happen, such as DNS
+// and TLS on a reused connection, are zero.
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from sending the request to the first response
+	// byte, mostly server processing time.
+	TTFB time.Duration
+	// BodyRead is the time spent reading the response body, which
+	// includes decoding the envelope as it is read.
+	BodyRead time.Duration
+	// Total is the time from the start of the request to the end of the
+	// body.
+	Total time.Duration
+	// Reused reports whether an idle connection was reused.
+	Reused bool
+}
+
+// WithLatencyBreakdown measures the DNS, connect, TLS, time to first byte
+// and body read durations of every request, and sets them on the
+// Operation passed to the Metrics recorder. The measurements rely on
+// net/http client tracing, so they are zero with HTTP clients not built
+// on net/http.
+func WithLatencyBreakdown() ClientOption {
+	return func(client *Client) {
+		client.latencyBreakdown = true
+	}
+}
+
+// timingTrace collects Timings from httptrace hooks, which may be called
+// concurrently.
+type timingTrace struct {
+	mu                                   sync.Mutex
+	start, dnsStart, connStart, tlsStart time.Time
+	wroteRequest                         time.Time
+	t                                    Timings
+}
+
+func newTimingTrace(ctx context.Context) (context.Context, *timingTrace) {
+	tt := &timingTrace{start: time.Now()}
+	since := func(start time.Time) time.Duration {
+		if start.IsZero() {
+			return 0
+		}
+		return time.Since(start)
+	}
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			tt.mu.Lock()
+			tt.t.Reused = info.Reused
+			tt.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tt.mu.Lock()
+			tt.dnsStart = time.Now()
+			tt.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tt.mu.Lock()
+			tt.t.DNS = since(tt.dnsStart)
+			tt.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			tt.mu.Lock()
+			if tt.connStart.IsZero() {
+				tt.connStart = time.Now()
+			}
+			tt.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			tt.mu.Lock()
+			tt.t.Connect = since(tt.connStart)
+			tt.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			tt.mu.Lock()
+			tt.tlsStart = time.Now()
+			tt.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tt.mu.Lock()
+			tt.t.TLS = since(tt.tlsStart)
+			tt.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			tt.mu.Lock()
+			tt.wroteRequest = time.Now()
+			tt.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			tt.mu.Lock()
+			tt.t.TTFB = since(tt.wroteRequest)
+			tt.mu.Unlock()
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), tt
+}
+
+// bodyRead completes the timings once the response body, whose reading
+// began at start, was read.
+func (tt *timingTrace) bodyRead(start time.Time) Timings {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.t.BodyRead = time.Since(start)
+	tt.t.Total = time.Since(tt.start)
+	return tt.t
+}
@@ -0,0 +1,40 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestLatencyBreakdown(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var timings []*Timings
+	client := NewClient(srv.URL, WithHTTPClient(srv.Client()), WithLatencyBreakdown(),
+		WithMetrics(metricsFunc(func(ctx context.Context, op *Operation, duration time.Duration, err error) {
+			timings = append(timings, op.Timings)
+		})))
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.NoErr(client.Run(ctx, NewRequest("{ a }"), nil))
+	is.Equal(len(timings), 2)
+	first, second := timings[0], timings[1]
+	is.True(first.Connect > 0)
+	is.True(first.TLS > 0)
+	is.True(!first.Reused)
+	is.True(first.TTFB >= 20*time.Millisecond)
+	is.True(first.Total >= first.TTFB)
+	is.True(second.Reused)
+	is.Equal(second.TLS, time.Duration(0))
+}
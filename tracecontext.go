@@ -0,0 +1,95 @@
+package gographql
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var traceParentRe = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// TraceContext carries W3C Trace Context and Baggage values, so traces
+// can be stitched together without depending on a tracing library.
+type TraceContext struct {
+	// TraceParent is the traceparent header value,
+	// e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+	TraceParent string
+	// TraceState is the optional tracestate header value.
+	TraceState string
+	// Baggage is the baggage header value.
+	Baggage string
+}
+
+// TraceID returns the trace ID part of the traceparent, or an empty
+// string if the traceparent is missing or malformed.
+func (tc TraceContext) TraceID() string {
+	m := traceParentRe.FindStringSubmatch(tc.TraceParent)
+	if m == nil || m[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	return m[1]
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx carrying tc.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the TraceContext carried by ctx.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// TraceContextFromHeader extracts the trace context from incoming request
+// headers, typically in a server handler before calling the client.
+func TraceContextFromHeader(h http.Header) TraceContext {
+	return TraceContext{
+		TraceParent: h.Get("traceparent"),
+		TraceState:  h.Get("tracestate"),
+		Baggage:     h.Get("baggage"),
+	}
+}
+
+// ContextWithBaggage returns a copy of ctx with the baggage member
+// key=value appended to its trace context.
+func ContextWithBaggage(ctx context.Context, key, value string) context.Context {
+	tc, _ := TraceContextFromContext(ctx)
+	member := url.PathEscape(key) + "=" + url.PathEscape(value)
+	if tc.Baggage == "" {
+		tc.Baggage = member
+	} else {
+		tc.Baggage += "," + member
+	}
+	return ContextWithTraceContext(ctx, tc)
+}
+
+// WithTraceContextPropagation sends the traceparent, tracestate and
+// baggage headers taken from the TraceContext in the request context.
+// Headers set explicitly on the Request take precedence, and malformed
+// traceparent values are not sent.
+func WithTraceContextPropagation() ClientOption {
+	return func(client *Client) {
+		client.propagateTrace = true
+	}
+}
+
+func setTraceHeaders(ctx context.Context, h http.Header) {
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	if tc.TraceID() != "" && h.Get("traceparent") == "" {
+		h.Set("traceparent", tc.TraceParent)
+		if tc.TraceState != "" && h.Get("tracestate") == "" {
+			h.Set("tracestate", tc.TraceState)
+		}
+	}
+	if tc.Baggage != "" && h.Get("baggage") == "" {
+		h.Set("baggage", tc.Baggage)
+	}
+}
@@ -0,0 +1,44 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	is := is.New(t)
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	ctx = ContextWithTraceContext(ctx, TraceContext{TraceParent: traceParent, TraceState: "vendor=1"})
+	ctx = ContextWithBaggage(ctx, "user id", "42")
+	ctx = ContextWithBaggage(ctx, "tenant", "acme")
+	tc, _ := TraceContextFromContext(ctx)
+	is.Equal(tc.TraceID(), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	client := NewClient(srv.URL, WithTraceContextPropagation())
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), nil))
+	is.Equal(header.Get("traceparent"), traceParent)
+	is.Equal(header.Get("tracestate"), "vendor=1")
+	is.Equal(header.Get("baggage"), "user%20id=42,tenant=acme")
+
+	ctx = ContextWithTraceContext(ctx, TraceContext{TraceParent: "garbage"})
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), nil))
+	is.Equal(header.Get("traceparent"), "")
+
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest("query {}"), nil))
+	is.Equal(header.Get("baggage"), "") // propagation is opt-in
+}
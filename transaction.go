@@ -0,0 +1,83 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionFailed a mutation of a transaction failed. Whether the
+// other mutations were applied depends on the server: only servers
+// executing the document in one database transaction roll them back.
+var ErrTransactionFailed = errors.New("transaction failed; atomicity depends on the server")
+
+// Transaction composes mutations into a single document, which servers
+// such as Hasura and Dgraph execute in one database transaction: either
+// every mutation is applied or none is. Atomicity depends on the server:
+// others execute the mutations one after the other, keeping those applied
+// before one failed. Make one with Client.Transaction.
+type Transaction struct {
+	cp *Composer
+}
+
+// MutationResult is the outcome of a mutation of a transaction.
+type MutationResult struct {
+	// Data is the data of the mutation, nil if the transaction failed.
+	Data json.RawMessage
+	// Errors are the errors reported for the mutation.
+	Errors GraphQLErrors
+}
+
+// Transaction returns an empty Transaction running with c.
+//
+//	tx := client.Transaction()
+//	tx.Add(debit, &debited)
+//	tx.Add(credit, &credited)
+//	results, err := tx.Commit(ctx)
+//	if errors.Is(err, gographql.ErrTransactionFailed) {
+//	    for i, r := range results {
+//	        log.Printf("mutation %d: %v", i, r.Errors)
+//	    }
+//	}
+func (c *Client) Transaction() *Transaction {
+	return &Transaction{cp: c.Compose()}
+}
+
+// Add adds the mutation req, whose data is decoded into resp once the
+// transaction is committed, and returns its index in the results.
+func (tx *Transaction) Add(req *Request, resp interface{}) int {
+	return tx.cp.Add(req, resp)
+}
+
+// Commit sends the mutations as one request. When any of them fails, the
+// responses are left untouched and the error wraps ErrTransactionFailed
+// and the *ComposeError; the results report which mutations failed.
+func (tx *Transaction) Commit(ctx context.Context) ([]MutationResult, error) {
+	for i, part := range tx.cp.parts {
+		if kind, _ := operationInfo(part.req.Query()); kind != "mutation" {
+			return nil, fmt.Errorf("transaction: request %d is a %s, not a mutation", i, kind)
+		}
+	}
+	data, composeErr, err := tx.cp.send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]MutationResult, len(tx.cp.parts))
+	if composeErr != nil {
+		for i := range results {
+			results[i].Errors = composeErr.Errors[i]
+		}
+		return results, fmt.Errorf("%w: %w", ErrTransactionFailed, composeErr)
+	}
+	for i := range results {
+		if data[i] != nil {
+			b, err := json.Marshal(data[i])
+			if err != nil {
+				return nil, errors.Join(ErrDecodingResponse, err)
+			}
+			results[i].Data = b
+		}
+	}
+	return results, tx.cp.decode(data)
+}
@@ -0,0 +1,68 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTransaction(t *testing.T) {
+	is := is.New(t)
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Query string }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.True(strings.HasPrefix(body.Query, "mutation Composed("))
+		if fail {
+			io.WriteString(w, `{"data":null,"errors":[{"message":"insufficient funds","path":["r0_debit"]}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"r0_debit":{"balance":90},"r1_credit":{"balance":110}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	newTx := func(debited, credited interface{}) *Transaction {
+		tx := client.Transaction()
+		debit := NewRequest(`mutation ($id: ID!) { debit(id: $id, amount: 10) { balance } }`)
+		debit.Var("id", "a")
+		credit := NewRequest(`mutation ($id: ID!) { credit(id: $id, amount: 10) { balance } }`)
+		credit.Var("id", "b")
+		is.Equal(tx.Add(debit, debited), 0)
+		is.Equal(tx.Add(credit, credited), 1)
+		return tx
+	}
+	var debited, credited struct {
+		Debit, Credit struct{ Balance int }
+	}
+	results, err := newTx(&debited, &credited).Commit(ctx)
+	is.NoErr(err)
+	is.Equal(debited.Debit.Balance, 90)
+	is.Equal(credited.Credit.Balance, 110)
+	is.Equal(string(results[1].Data), `{"credit":{"balance":110}}`)
+
+	fail = true
+	debited.Debit.Balance = 0
+	results, err = newTx(&debited, &credited).Commit(ctx)
+	is.True(errors.Is(err, ErrTransactionFailed))
+	var composeErr *ComposeError
+	is.True(errors.As(err, &composeErr))
+	is.Equal(results[0].Errors[0].Message, "insufficient funds")
+	is.Equal(results[1].Errors, nil)
+	is.Equal(debited.Debit.Balance, 0)
+
+	tx := client.Transaction()
+	tx.Add(NewRequest(`{ a }`), nil)
+	_, err = tx.Commit(ctx)
+	is.True(err != nil)
+}
@@ -0,0 +1,68 @@
+package gographql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// FieldTransformFunc transforms a single value of the response data.
+// path is the response path of the value, in the same form as
+// GraphQLError.Path. Numbers are passed as json.Number, so large integers
+// keep their precision.
+type FieldTransformFunc func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error)
+
+type fieldTransform struct {
+	selector string
+	segs     []pathSegment
+	fn       FieldTransformFunc
+}
+
+// WithFieldTransform registers fn to rewrite every value in the response
+// data matched by selector before the data is decoded into the response
+// object, for example to decrypt envelope-encrypted fields:
+//
+//	gographql.WithFieldTransform("users.#.ssn", decrypt)
+//
+// Selectors are dot separated keys relative to the data field, where "#"
+// matches every array element and "*" every object value. Transforms run
+// in the order they were registered. An invalid selector is reported by
+// NewClientE and makes every Run fail.
+func WithFieldTransform(selector string, fn FieldTransformFunc) ClientOption {
+	return func(client *Client) {
+		segs, err := parsePath(selector)
+		if err != nil {
+			client.invalidOption("WithFieldTransform: %v", err)
+			fn = func(context.Context, []interface{}, interface{}) (interface{}, error) {
+				return nil, err
+			}
+		}
+		client.transforms = append(client.transforms, fieldTransform{
+			selector: selector,
+			segs:     segs,
+			fn:       fn,
+		})
+	}
+}
+
+// applyTransforms runs the client's field transforms over data.
+func (c *Client) applyTransforms(ctx context.Context, data json.RawMessage) (json.RawMessage, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// numbers are kept as they are, rather than rounded through float64
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	for _, t := range c.transforms {
+		fn := func(path []interface{}, value interface{}) (interface{}, error) {
+			return t.fn(ctx, path, value)
+		}
+		var err error
+		if v, err = transformPath(v, t.segs, nil, fn); err != nil {
+			return nil, fmt.Errorf("transform %s: %w", t.selector, err)
+		}
+	}
+	return json.Marshal(v)
+}
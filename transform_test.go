@@ -0,0 +1,86 @@
+package gographql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestFieldTransform(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"users":[{"name":"a","ssn":"enc:1"},{"name":"b","ssn":"enc:2"}]}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var paths [][]interface{}
+	decrypt := func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error) {
+		paths = append(paths, path)
+		return strings.TrimPrefix(value.(string), "enc:"), nil
+	}
+	client := NewClient(srv.URL, WithFieldTransform("users.#.ssn", decrypt))
+	var resp struct {
+		Users []struct {
+			Name string
+			SSN  string
+		}
+	}
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), &resp))
+	is.Equal(resp.Users[0].SSN, "1")
+	is.Equal(resp.Users[1].SSN, "2")
+	is.Equal(resp.Users[1].Name, "b")
+	is.Equal(paths, [][]interface{}{{"users", 0, "ssn"}, {"users", 1, "ssn"}})
+}
+
+func TestFieldTransformError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"ssn":"enc:1"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	errKey := errors.New("no key")
+	client := NewClient(srv.URL, WithFieldTransform("user.ssn", func(context.Context, []interface{}, interface{}) (interface{}, error) {
+		return nil, errKey
+	}))
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	is.True(errors.Is(err, errKey))
+
+	client = NewClient(srv.URL, WithFieldTransform("user..ssn", nil))
+	err = client.Run(ctx, NewRequest("query {}"), nil)
+	is.True(err != nil)
+}
+
+func TestFieldTransformLargeNumbers(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"id":9007199254740993,"ssn":"enc:1"}}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithFieldTransform("user.ssn", func(ctx context.Context, path []interface{}, value interface{}) (interface{}, error) {
+		return strings.TrimPrefix(value.(string), "enc:"), nil
+	}))
+	var resp struct {
+		User struct {
+			ID  int64
+			SSN string
+		}
+	}
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), &resp))
+	is.Equal(resp.User.ID, int64(9007199254740993))
+	is.Equal(resp.User.SSN, "1")
+}
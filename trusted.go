@@ -0,0 +1,112 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUntrustedDocument the query is not one of the client's trusted
+// documents.
+var ErrUntrustedDocument = errors.New("query is not a trusted document")
+
+// TrustedDocuments are pre-registered documents sent by ID instead of
+// text, for servers that only execute known operations.
+type TrustedDocuments struct {
+	// Documents maps document IDs to document text, see
+	// LoadTrustedDocuments.
+	Documents map[string]string
+	// PersistedQuery sends the ID as extensions.persistedQuery.sha256Hash,
+	// as Apollo servers expect, instead of documentId.
+	PersistedQuery bool
+	// AllowUnknown sends queries missing from Documents as text. By
+	// default Run fails with ErrUntrustedDocument.
+	AllowUnknown bool
+}
+
+// WithTrustedDocuments sends the queries found in docs by ID. Queries are
+// matched by text, ignoring differences in whitespace. Requests sent with
+// UseMultipartForm or with files still carry the document text.
+func WithTrustedDocuments(docs TrustedDocuments) ClientOption {
+	return func(client *Client) {
+		client.trusted = newTrustedIndex(docs)
+	}
+}
+
+// LoadTrustedDocuments reads a persisted operations manifest: either an
+// Apollo manifest, with an operations list of id and body entries, or a
+// JSON object mapping IDs to documents, as generated by Relay and
+// GraphQL Codegen.
+func LoadTrustedDocuments(r io.Reader) (map[string]string, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	var apollo struct {
+		Format     string `json:"format"`
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(raw, &apollo); err == nil && apollo.Format != "" {
+		docs := make(map[string]string, len(apollo.Operations))
+		for _, op := range apollo.Operations {
+			docs[op.ID] = op.Body
+		}
+		return docs, nil
+	}
+	var docs map[string]string
+	if err := json.Unmarshal(raw, &docs); err != nil {
+		return nil, fmt.Errorf("unknown manifest format: %w", err)
+	}
+	return docs, nil
+}
+
+// trustedIndex finds the IDs of trusted documents.
+type trustedIndex struct {
+	TrustedDocuments
+	ids map[string]string
+}
+
+func newTrustedIndex(docs TrustedDocuments) *trustedIndex {
+	idx := &trustedIndex{TrustedDocuments: docs, ids: make(map[string]string, len(docs.Documents))}
+	for id, doc := range docs.Documents {
+		idx.ids[normalizeWhitespace(doc)] = id
+	}
+	return idx
+}
+
+func (t *trustedIndex) lookup(query string) (string, bool) {
+	id, ok := t.ids[normalizeWhitespace(query)]
+	return id, ok
+}
+
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// runTrusted sends req as the trusted document id.
+func (c *Client) runTrusted(ctx context.Context, req *Request, id string, resp interface{}, meta *responseMeta) error {
+	body := map[string]interface{}{"variables": req.vars}
+	if c.trusted.PersistedQuery {
+		body["extensions"] = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": id},
+		}
+	} else {
+		body["documentId"] = id
+	}
+	buf := getBodyBuffer()
+	defer buf.release()
+	if err := json.NewEncoder(buf).Encode(body); err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	if c.debug(ctx) {
+		c.log.Debugf("variables: %+v", req.vars)
+		c.log.Debugf("document id: %s", id)
+	}
+	return c.postJSON(ctx, req, buf, resp, meta)
+}
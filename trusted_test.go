@@ -0,0 +1,67 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTrustedDocuments(t *testing.T) {
+	is := is.New(t)
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		io.WriteString(w, `{"data":{"a":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	docs := map[string]string{"abc123": "query A($id: ID) {\n  a(id: $id)\n}"}
+	client := NewClient(srv.URL, WithTrustedDocuments(TrustedDocuments{Documents: docs}))
+	req := NewRequest("query A($id: ID) { a(id: $id) }")
+	req.Var("id", "1")
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(bodies[0]["documentId"], "abc123")
+	is.Equal(bodies[0]["query"], nil)
+	is.Equal(bodies[0]["variables"], map[string]interface{}{"id": "1"})
+
+	err := client.Run(ctx, NewRequest("query { b }"), nil)
+	is.Equal(err, ErrUntrustedDocument)
+	is.Equal(len(bodies), 1)
+
+	client = NewClient(srv.URL, WithTrustedDocuments(TrustedDocuments{Documents: docs, PersistedQuery: true, AllowUnknown: true}))
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(bodies[1]["extensions"], map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": "abc123"},
+	})
+	is.NoErr(client.Run(ctx, NewRequest("query { b }"), nil))
+	is.Equal(bodies[2]["query"], "query { b }")
+}
+
+func TestLoadTrustedDocuments(t *testing.T) {
+	is := is.New(t)
+	docs, err := LoadTrustedDocuments(strings.NewReader(`{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [{"id": "h1", "name": "A", "type": "query", "body": "query A { a }"}]
+	}`))
+	is.NoErr(err)
+	is.Equal(docs, map[string]string{"h1": "query A { a }"})
+
+	docs, err = LoadTrustedDocuments(strings.NewReader(`{"h2": "query B { b }"}`))
+	is.NoErr(err)
+	is.Equal(docs, map[string]string{"h2": "query B { b }"})
+
+	_, err = LoadTrustedDocuments(strings.NewReader(`[1]`))
+	is.True(err != nil)
+}
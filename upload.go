@@ -0,0 +1,134 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+// ErrUploadsUnsupported is returned for requests with files when Probe
+// found that the server does not accept multipart uploads.
+var ErrUploadsUnsupported = errors.New("server does not support multipart uploads")
+
+// UseMultipartRequestSpec sends requests with files following the GraphQL
+// multipart request specification
+// (https://github.com/jaydenseric/graphql-multipart-request-spec), as
+// expected by Apollo Server, GraphQL Yoga and Absinthe for Upload
+// scalars. The field of each file is the path of its variable, such as
+// "file" or "input.attachments.0"; the variable is sent as null and
+// mapped to the file part. Requests without files are sent as JSON.
+//
+//	req := gographql.NewRequest(`mutation ($file: Upload!) { upload(file: $file) { id } }`)
+//	req.File("file", "report.pdf", f)
+func UseMultipartRequestSpec() ClientOption {
+	return func(client *Client) {
+		client.uploadSpec = true
+	}
+}
+
+// runWithUploads sends req as a multipart request with operations, map
+// and one numbered part per file.
+func (c *Client) runWithUploads(ctx context.Context, req *Request, resp interface{}, meta *responseMeta) error {
+	vars, err := uploadVariables(req)
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	operations, err := json.Marshal(map[string]interface{}{"query": req.q, "variables": vars})
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+	fileMap := make(map[string][]string, len(req.files))
+	for i, f := range req.files {
+		fileMap[strconv.Itoa(i)] = []string{"variables." + uploadPath(f.Field)}
+	}
+	mapField, err := json.Marshal(fileMap)
+	if err != nil {
+		return errors.Join(ErrEncodingRequestBody, err)
+	}
+
+	if c.debug(ctx) {
+		c.log.Debugf("operations: %s", operations)
+		c.log.Debugf("map: %s", mapField)
+	}
+	return c.postMultipart(ctx, req, func(writer *multipart.Writer) error {
+		if err := writer.WriteField("operations", string(operations)); err != nil {
+			return fmt.Errorf("write operations field error: %w", err)
+		}
+		if err := writer.WriteField("map", string(mapField)); err != nil {
+			return fmt.Errorf("write map field error: %w", err)
+		}
+		for i := range req.files {
+			if err := writeFile(writer, strconv.Itoa(i), req.files[i], req.progress); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, resp, meta)
+}
+
+// uploadPath returns the path of a file field relative to the variables.
+func uploadPath(field string) string {
+	return strings.TrimPrefix(field, "variables.")
+}
+
+// uploadVariables returns the variables of req as JSON values, with the
+// variables of its files set to null.
+func uploadVariables(req *Request) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	if len(req.vars) > 0 {
+		b, err := json.Marshal(req.vars)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &vars); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range req.files {
+		if err := setNull(vars, strings.Split(uploadPath(f.Field), ".")); err != nil {
+			return nil, fmt.Errorf("file %q: %w", f.Field, err)
+		}
+	}
+	return vars, nil
+}
+
+// setNull sets the value at path in v to null, creating the missing
+// objects and growing lists as needed.
+func setNull(v map[string]interface{}, path []string) error {
+	key := path[0]
+	if len(path) == 1 {
+		v[key] = nil
+		return nil
+	}
+	next := v[key]
+	if index, err := strconv.Atoi(path[1]); err == nil && index >= 0 {
+		list, _ := next.([]interface{})
+		for len(list) <= index {
+			list = append(list, nil)
+		}
+		v[key] = list
+		if len(path) == 2 {
+			list[index] = nil
+			return nil
+		}
+		obj, ok := list[index].(map[string]interface{})
+		if !ok {
+			obj = map[string]interface{}{}
+			list[index] = obj
+		}
+		return setNull(obj, path[2:])
+	}
+	obj, ok := next.(map[string]interface{})
+	if !ok {
+		if next != nil {
+			return fmt.Errorf("%s is not an object", key)
+		}
+		obj = map[string]interface{}{}
+		v[key] = obj
+	}
+	return setNull(obj, path[1:])
+}
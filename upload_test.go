@@ -0,0 +1,71 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestMultipartRequestSpec(t *testing.T) {
+	is := is.New(t)
+	var operations, fileMap string
+	files := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(r.ParseMultipartForm(1 << 20))
+		operations = r.FormValue("operations")
+		fileMap = r.FormValue("map")
+		for field, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			is.NoErr(err)
+			b, err := io.ReadAll(f)
+			is.NoErr(err)
+			files[field] = headers[0].Filename + ":" + string(b)
+		}
+		io.WriteString(w, `{"data":{"upload":"ok"}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseMultipartRequestSpec())
+	req := NewRequest(`mutation ($file: Upload!, $input: Input!) { upload(file: $file, input: $input) }`)
+	req.Var("input", map[string]interface{}{"title": "docs", "attachments": []interface{}{}})
+	req.File("file", "a.txt", strings.NewReader("A"))
+	req.File("input.attachments.1", "b.txt", strings.NewReader("B"))
+	var resp struct{ Upload string }
+	is.NoErr(client.Run(ctx, req, &resp))
+	is.Equal(resp.Upload, "ok")
+
+	var ops struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	is.NoErr(json.Unmarshal([]byte(operations), &ops))
+	is.Equal(ops.Variables, map[string]interface{}{
+		"file":  nil,
+		"input": map[string]interface{}{"title": "docs", "attachments": []interface{}{nil, nil}},
+	})
+	is.Equal(fileMap, `{"0":["variables.file"],"1":["variables.input.attachments.1"]}`)
+	is.Equal(files, map[string]string{"0": "a.txt:A", "1": "b.txt:B"})
+}
+
+func TestMultipartRequestSpecWithoutFiles(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.True(strings.HasPrefix(r.Header.Get("Content-Type"), "application/json"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, UseMultipartRequestSpec())
+	is.NoErr(client.Run(ctx, NewRequest("query { a }"), nil))
+}
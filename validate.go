@@ -0,0 +1,62 @@
+package gographql
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultValidateOnlyHeader is the request header used by ValidateRemote,
+// with WithValidateOnlyHeader, to ask the server to validate an operation
+// without executing it.
+const DefaultValidateOnlyHeader = "X-GraphQL-Validate-Only"
+
+var skipDirectiveRe = regexp.MustCompile(`@skip\s*\([^)]*\)`)
+
+// WithValidateOnlyHeader makes ValidateRemote send the operation as it is
+// with the header name, DefaultValidateOnlyHeader when empty, set to
+// "true", for servers supporting validation-only execution. Only use it
+// with such servers: one ignoring the header executes the operation,
+// mutations included.
+func WithValidateOnlyHeader(name string) ClientOption {
+	return func(client *Client) {
+		if name == "" {
+			name = DefaultValidateOnlyHeader
+		}
+		client.validateHeader = name
+	}
+}
+
+// ValidateRemote asks the server to validate req without side effects,
+// for example to check operations against a staging schema in CI.
+// Validation failures are returned as GraphQLErrors.
+//
+// By default req is sent with @skip(if: true) added to every top level
+// selection: the server then validates the whole document, variables
+// included, but executes no fields, which works with any spec compliant
+// server. With WithValidateOnlyHeader, req is sent as it is with the
+// validation-only header set instead.
+func (c *Client) ValidateRemote(ctx context.Context, req *Request) error {
+	req = req.Clone()
+	if c.validateHeader == "" {
+		doc, err := ParseDocument(req.q)
+		if err != nil {
+			return err
+		}
+		for _, def := range doc.Definitions {
+			if def.Kind == "fragment" {
+				continue
+			}
+			for _, sel := range def.Selections {
+				sel.Directives = skipDirectiveRe.ReplaceAllString(sel.Directives, "")
+				if sel.Directives != "" {
+					sel.Directives += " "
+				}
+				sel.Directives += "@skip(if: true)"
+			}
+		}
+		req.q = doc.String()
+	} else {
+		req.Header.Set(c.validateHeader, "true")
+	}
+	return c.Run(ctx, req, nil)
+}
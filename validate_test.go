@@ -0,0 +1,52 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestValidateRemoteHeader(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("X-GraphQL-Validate-Only"), "true")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"errors":[{"message":"Cannot query field \"nope\" on type \"Query\"."}]}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL, WithValidateOnlyHeader(""))
+	req := NewRequest("{ nope }")
+	err := client.ValidateRemote(ctx, req)
+	_, ok := err.(GraphQLErrors)
+	is.True(ok)
+	is.Equal(req.Header.Get("X-GraphQL-Validate-Only"), "") // caller's request untouched
+}
+
+func TestValidateRemoteSkip(t *testing.T) {
+	is := is.New(t)
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Query string }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		query = body.Query
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	// mutations are skipped by default
+	client := NewClient(srv.URL)
+	err := client.ValidateRemote(ctx, NewRequest(`mutation ($x: Boolean!) { a @skip(if: $x) { id } b @include(if: $x) }`))
+	is.NoErr(err)
+	is.Equal(query, "mutation ($x: Boolean!) {\n  a @skip(if: true) {\n    id\n  }\n  b @include(if: $x) @skip(if: true)\n}\n")
+}
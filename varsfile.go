@@ -0,0 +1,98 @@
+package gographql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envReference matches the ${NAME} references interpolated in variables
+// files.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// VarsFromFile sets the variables of the JSON or YAML object in the file
+// at path, chosen by its .json, .yaml or .yml extension, replacing
+// variables of the same name. References to environment variables in
+// strings, written ${NAME}, are replaced by their values, so secrets can
+// stay out of the file:
+//
+//	# vars.yaml
+//	id: 42
+//	token: ${API_TOKEN}
+//
+// It is an error for a referenced environment variable not to be set.
+func (req *Request) VarsFromFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var vars map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err = dec.Decode(&vars)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &vars)
+	default:
+		return fmt.Errorf("%s: unsupported variables file extension %q", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for key, value := range vars {
+		if vars[key], err = expandEnv(value); err != nil {
+			return fmt.Errorf("%s: variable %s: %w", path, key, err)
+		}
+	}
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if req.vars == nil {
+		req.vars = make(map[string]interface{}, len(vars))
+	}
+	for key, value := range vars {
+		req.vars[key] = value
+	}
+	return nil
+}
+
+// expandEnv replaces the environment variable references in the strings
+// of value.
+func expandEnv(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var err error
+		s := envReference.ReplaceAllStringFunc(v, func(ref string) string {
+			name := envReference.FindStringSubmatch(ref)[1]
+			env, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", name)
+			}
+			return env
+		})
+		return s, err
+	case map[string]interface{}:
+		for key, elem := range v {
+			expanded, err := expandEnv(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+	case []interface{}:
+		for i, elem := range v {
+			expanded, err := expandEnv(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	}
+	return value, nil
+}
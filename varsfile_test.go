@@ -0,0 +1,43 @@
+package gographql
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestVarsFromFile(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	t.Setenv("GOGRAPHQL_TOKEN", "secret")
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		is.NoErr(os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	req := NewRequest(`query ($id: ID!, $filter: Filter) { users { id } }`)
+	req.Var("limit", 10)
+	is.NoErr(req.VarsFromFile(write("vars.yaml", "id: 12345678901\nfilter:\n  token: Bearer ${GOGRAPHQL_TOKEN}\n  tags: [a, '${GOGRAPHQL_TOKEN}']\n")))
+	is.Equal(req.Vars(), map[string]interface{}{
+		"limit": 10,
+		"id":    12345678901,
+		"filter": map[string]interface{}{
+			"token": "Bearer secret",
+			"tags":  []interface{}{"a", "secret"},
+		},
+	})
+
+	req = NewRequest(`query ($id: ID!) { user(id: $id) { id } }`)
+	is.NoErr(req.VarsFromFile(write("vars.json", `{"id": 12345678901234567890, "price": "$5"}`)))
+	is.Equal(req.Vars(), map[string]interface{}{"id": json.Number("12345678901234567890"), "price": "$5"})
+
+	err := req.VarsFromFile(write("missing.json", `{"token": "${GOGRAPHQL_MISSING}"}`))
+	is.True(err != nil)
+	is.Equal(err.Error(), filepath.Join(dir, "missing.json")+": variable token: environment variable GOGRAPHQL_MISSING is not set")
+	is.True(req.VarsFromFile(write("vars.toml", `id = 1`)) != nil)
+	is.True(req.VarsFromFile(write("list.json", `[1]`)) != nil)
+}
@@ -0,0 +1,77 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// WarningHandler receives the warnings of a response to req.
+type WarningHandler func(ctx context.Context, req *Request, warnings GraphQLErrors)
+
+// IsWarning reports whether a response error is a warning: an error with
+// extensions.severity or extensions.level set to "warning", in any case.
+func IsWarning(err GraphQLError) bool {
+	for _, key := range []string{"severity", "level"} {
+		if s, ok := err.Extensions[key].(string); ok && strings.EqualFold(s, "warning") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithWarnings delivers warnings to handler instead of failing the Run.
+// Warnings are the response errors for which isWarning returns true
+// (IsWarning when nil), and the entries of extensions.warnings. A response
+// whose errors are all warnings succeeds.
+func WithWarnings(handler WarningHandler, isWarning func(GraphQLError) bool) ClientOption {
+	return func(client *Client) {
+		if handler == nil {
+			client.invalidOption("WithWarnings: handler must not be nil")
+		}
+		if isWarning == nil {
+			isWarning = IsWarning
+		}
+		client.warnings = &warningFilter{handler: handler, isWarning: isWarning}
+	}
+}
+
+type warningFilter struct {
+	handler   WarningHandler
+	isWarning func(GraphQLError) bool
+}
+
+// filter passes the warnings in errs and extensions to the handler and
+// returns the remaining errors.
+func (w *warningFilter) filter(ctx context.Context, req *Request, errs GraphQLErrors, extensions map[string]interface{}) GraphQLErrors {
+	var warnings, remaining GraphQLErrors
+	for _, err := range errs {
+		if w.isWarning(err) {
+			warnings = append(warnings, err)
+		} else {
+			remaining = append(remaining, err)
+		}
+	}
+	if list, ok := extensions["warnings"].([]interface{}); ok {
+		for _, entry := range list {
+			warnings = append(warnings, extensionWarning(entry))
+		}
+	}
+	if len(warnings) > 0 {
+		w.handler(ctx, req, warnings)
+	}
+	return remaining
+}
+
+// extensionWarning converts an entry of extensions.warnings, a message or
+// an error object, to a GraphQLError.
+func extensionWarning(entry interface{}) GraphQLError {
+	if msg, ok := entry.(string); ok {
+		return GraphQLError{Message: msg}
+	}
+	var warning GraphQLError
+	if b, err := json.Marshal(entry); err == nil {
+		json.Unmarshal(b, &warning)
+	}
+	return warning
+}
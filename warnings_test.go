@@ -0,0 +1,46 @@
+package gographql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWarnings(t *testing.T) {
+	is := is.New(t)
+	body := `{"data":{"a":"ok"},"errors":[
+		{"message":"field b is deprecated","extensions":{"severity":"WARNING"}}
+	],"extensions":{"warnings":["rate limit at 90%",{"message":"slow resolver","extensions":{"code":"SLOW"}}]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var got GraphQLErrors
+	client := NewClient(srv.URL, WithWarnings(func(ctx context.Context, req *Request, warnings GraphQLErrors) {
+		got = append(got, warnings...)
+	}, nil))
+	var resp struct{ A string }
+	is.NoErr(client.Run(ctx, NewRequest("query { a b }"), &resp))
+	is.Equal(resp.A, "ok")
+	is.Equal(len(got), 3)
+	is.Equal(got[0].Message, "field b is deprecated")
+	is.Equal(got[1].Message, "rate limit at 90%")
+	is.Equal(got[2].Extensions["code"], "SLOW")
+
+	body = `{"data":null,"errors":[
+		{"message":"careful","extensions":{"level":"warning"}},
+		{"message":"boom"}
+	]}`
+	got = nil
+	err := client.Run(ctx, NewRequest("query { a }"), nil)
+	is.Equal(err.Error(), "graphql: boom")
+	is.Equal(len(got), 1)
+}
@@ -0,0 +1,305 @@
+package gographql
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWebSocketHandshake the server did not accept the WebSocket upgrade.
+var ErrWebSocketHandshake = errors.New("websocket handshake failed")
+
+// maxWebSocketMessage bounds the size of a received message.
+const maxWebSocketMessage = 64 << 20
+
+// WebSocket opcodes, see RFC 6455 section 5.2.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketCloseError is returned when the server closes a WebSocket
+// connection. GraphQL subscription protocols use codes from 4400 up to
+// report protocol errors, such as 4401 Unauthorized.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed: %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+}
+
+// wsConn is a minimal RFC 6455 connection, enough for the GraphQL
+// subscription protocols: text messages, ping, pong and close. Reads must
+// not be concurrent; writes may be.
+type wsConn struct {
+	rwc io.ReadWriteCloser
+	br  *bufio.Reader
+	// client connections mask the frames they send.
+	client bool
+	// protocol is the subprotocol selected by the server.
+	protocol string
+	// lastRead is the time the last frame was read, in Unix nanoseconds.
+	lastRead atomic.Int64
+
+	wmu       sync.Mutex
+	closeOnce sync.Once
+}
+
+// dialWebSocket opens a WebSocket connection to url, a ws, wss, http or
+// https URL, offering protocols.
+func dialWebSocket(ctx context.Context, hc *http.Client, url string, header http.Header, protocols []string) (*wsConn, error) {
+	switch {
+	case strings.HasPrefix(url, "ws://"):
+		url = "http://" + url[len("ws://"):]
+	case strings.HasPrefix(url, "wss://"):
+		url = "https://" + url[len("wss://"):]
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", key)
+	if len(protocols) > 0 {
+		r.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+	res, err := hc.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w; statuscode: %v", ErrWebSocketHandshake, res.StatusCode)
+	}
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if !ok {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: connection is not writable", ErrWebSocketHandshake)
+	}
+	if res.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		rwc.Close()
+		return nil, fmt.Errorf("%w: invalid Sec-WebSocket-Accept", ErrWebSocketHandshake)
+	}
+	return &wsConn{
+		rwc:      rwc,
+		br:       bufio.NewReader(rwc),
+		client:   true,
+		protocol: res.Header.Get("Sec-WebSocket-Protocol"),
+	}, nil
+}
+
+// acceptWebSocket upgrades the request to a server side WebSocket
+// connection, selecting the first of the client's protocols that is in
+// protocols.
+func acceptWebSocket(w http.ResponseWriter, r *http.Request, protocols []string) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+	var protocol string
+	for _, offered := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		offered = strings.TrimSpace(offered)
+		for _, p := range protocols {
+			if protocol == "" && offered == p {
+				protocol = p
+			}
+		}
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, ErrWebSocketHandshake
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	brw.WriteString("Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n")
+	if protocol != "" {
+		brw.WriteString("Sec-WebSocket-Protocol: " + protocol + "\r\n")
+	}
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{rwc: conn, br: brw.Reader, protocol: protocol}, nil
+}
+
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// writeMessage sends a text message.
+func (c *wsConn) writeMessage(b []byte) error {
+	return c.writeFrame(wsText, b)
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|opcode)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		for i := range payload {
+			frame[start+i] ^= mask[i%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// readMessage returns the next data message, answering pings and
+// reassembling fragments. A close frame from the peer is acknowledged and
+// returned as a *WebSocketCloseError.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			closeErr := &WebSocketCloseError{Code: 1005}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+				payload = payload[:2]
+			}
+			c.writeFrame(wsClose, payload)
+			c.rwc.Close()
+			return nil, closeErr
+		case wsText, wsBinary:
+			msg = payload
+		case wsContinuation:
+			if len(msg)+len(payload) > maxWebSocketMessage {
+				return nil, errors.New("websocket message too large")
+			}
+			msg = append(msg, payload...)
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWebSocketMessage {
+		err = errors.New("websocket message too large")
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	c.lastRead.Store(time.Now().UnixNano())
+	return
+}
+
+// close sends a close frame with code and closes the connection.
+func (c *wsConn) close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+		c.writeFrame(wsClose, append(payload, reason...))
+		err = c.rwc.Close()
+	})
+	return err
+}
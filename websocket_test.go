@@ -0,0 +1,52 @@
+package gographql
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWebSocketFrames(t *testing.T) {
+	is := is.New(t)
+	a, b := net.Pipe()
+	client := &wsConn{rwc: a, br: bufio.NewReader(a), client: true}
+	server := &wsConn{rwc: b, br: bufio.NewReader(b)}
+	for _, n := range []int{0, 125, 126, 70000} {
+		want := bytes.Repeat([]byte("x"), n)
+		go client.writeMessage(want)
+		got, err := server.readMessage()
+		is.NoErr(err)
+		is.Equal(got, want)
+	}
+
+	// fragments and pings are handled transparently
+	go func() {
+		server.writeFrame(wsText, nil)
+		server.rwc.Write([]byte{0x01, 3, 'h', 'e', 'l'}) // text, not final
+		server.writeFrame(wsPing, []byte("p"))
+		server.rwc.Write([]byte{0x80, 2, 'l', 'o'}) // final continuation
+	}()
+	msg, err := client.readMessage()
+	is.NoErr(err)
+	is.Equal(string(msg), "") // the empty message
+	pong := make(chan []byte)
+	go func() {
+		_, opcode, payload, _ := server.readFrame()
+		is.Equal(opcode, byte(wsPong))
+		pong <- payload
+	}()
+	msg, err = client.readMessage()
+	is.NoErr(err)
+	is.Equal(string(msg), "hello")
+	is.Equal(string(<-pong), "p")
+
+	go server.close(4401, "Unauthorized")
+	_, err = client.readMessage()
+	var closeErr *WebSocketCloseError
+	is.True(errors.As(err, &closeErr))
+	is.Equal(closeErr.Code, 4401)
+}
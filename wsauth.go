@@ -0,0 +1,150 @@
+package gographql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WithConnectionInitFunc builds the payload of the connection_init
+// message of every subscription connection with fn, so it can carry
+// fresh credentials from an auth provider. ctx is the context of the
+// subscription opening the connection. It replaces the payload of
+// WithConnectionInitPayload.
+//
+//	gographql.WithConnectionInitFunc(func(ctx context.Context) (interface{}, error) {
+//	    token, err := tokens.Token(ctx)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return map[string]string{"authToken": token}, nil
+//	})
+func WithConnectionInitFunc(fn func(ctx context.Context) (interface{}, error)) ClientOption {
+	return func(client *Client) {
+		client.connectionInitFunc = fn
+	}
+}
+
+// SubscriptionAuthRefresh renews the authentication of open subscription
+// connections, for servers whose sessions expire with their token.
+type SubscriptionAuthRefresh struct {
+	// Interval is how often connections are re-authenticated.
+	Interval time.Duration
+	// MessageType, if set, sends a fresh connection_init payload over the
+	// open connection in a message of this type, for servers accepting
+	// re-authentication in band. Otherwise the subscriptions are moved to
+	// a new connection, initialised with a fresh payload and handshake
+	// headers, and the old connection is closed. Events published while
+	// they move may be delivered twice.
+	MessageType string
+}
+
+// WithSubscriptionAuthRefresh re-authenticates open subscription
+// connections every refresh.Interval, with the payload built by the
+// function given to WithConnectionInitFunc.
+func WithSubscriptionAuthRefresh(refresh SubscriptionAuthRefresh) ClientOption {
+	return func(client *Client) {
+		if refresh.Interval <= 0 {
+			client.invalidOption("WithSubscriptionAuthRefresh: interval must be positive, got %v", refresh.Interval)
+			return
+		}
+		client.authRefresh = &refresh
+	}
+}
+
+// initPayload returns the payload of the connection_init message.
+func (conn *subConn) initPayload(ctx context.Context) (json.RawMessage, error) {
+	if fn := conn.client.connectionInitFunc; fn != nil {
+		payload, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(payload)
+	}
+	payload := conn.client.connectionInit
+	if payload == nil && conn.proto != appSyncProtocol {
+		payload = json.RawMessage("{}")
+	}
+	return payload, nil
+}
+
+// refreshAuth re-authenticates the connection every interval until it is
+// closed.
+func (conn *subConn) refreshAuth(refresh *SubscriptionAuthRefresh) {
+	ticker := time.NewTicker(refresh.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+		var err error
+		if refresh.MessageType != "" {
+			err = conn.reauthenticate(refresh.MessageType)
+		} else if err = conn.migrate(); err == nil {
+			return
+		}
+		if err != nil {
+			conn.client.log.Warnf("subscription auth refresh failed: %v", err)
+		}
+	}
+}
+
+// reauthenticate sends a fresh payload in a message of type typ.
+func (conn *subConn) reauthenticate(typ string) error {
+	ctx := context.Background()
+	if sub := conn.anySubscription(); sub != nil {
+		ctx = sub.ctx
+	}
+	payload, err := conn.initPayload(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.send(wsMessage{Type: typ, Payload: payload})
+}
+
+// migrate moves the subscriptions to a new connection and closes this
+// one.
+func (conn *subConn) migrate() error {
+	sub := conn.anySubscription()
+	if sub == nil {
+		return nil
+	}
+	conn.draining.Store(true)
+	next, err := conn.client.subscriptionConn(sub.ctx, sub.req)
+	if err != nil {
+		conn.draining.Store(false)
+		return err
+	}
+	conn.mu.Lock()
+	subs := conn.subs
+	conn.subs = make(map[string]*subscription)
+	conn.mu.Unlock()
+	live := liveSubscriptions(subs)
+	if err := next.resume(live); err != nil {
+		conn.mu.Lock()
+		for id, sub := range subs {
+			conn.subs[id] = sub
+		}
+		conn.mu.Unlock()
+		conn.draining.Store(false)
+		return err
+	}
+	for id := range subs {
+		conn.send(wsMessage{ID: id, Type: conn.proto.stop})
+	}
+	conn.close()
+	return nil
+}
+
+func (conn *subConn) anySubscription() *subscription {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	for _, sub := range conn.subs {
+		if sub.ctx.Err() == nil {
+			return sub
+		}
+	}
+	return nil
+}
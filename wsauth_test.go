@@ -0,0 +1,91 @@
+package gographql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// tokens returns a connection_init function handing out increasing
+// tokens.
+func tokens() func(ctx context.Context) (interface{}, error) {
+	var mu sync.Mutex
+	n := 0
+	return func(ctx context.Context) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return map[string]string{"token": fmt.Sprint(n)}, nil
+	}
+}
+
+func TestSubscriptionAuthRefreshMigrates(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		payload := string(msg.Payload)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err = readWSMessage(conn)
+		is.NoErr(err)
+		is.Equal(msg.Type, "subscribe")
+		writeWSMessage(conn, `{"id":"`+msg.ID+`","type":"next","payload":{"data":`+payload+`}}`)
+		for {
+			if _, err := readWSMessage(conn); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL,
+		WithConnectionInitFunc(tokens()),
+		WithSubscriptionAuthRefresh(SubscriptionAuthRefresh{Interval: 50 * time.Millisecond}))
+	subCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	payloads, _, err := client.Subscribe(subCtx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	is.Equal(string((<-payloads).Data), `{"token":"1"}`)
+	is.Equal(string((<-payloads).Data), `{"token":"2"}`)
+	is.Equal(string((<-payloads).Data), `{"token":"3"}`)
+}
+
+func TestSubscriptionAuthRefreshInBand(t *testing.T) {
+	is := is.New(t)
+	srv := wsServer(t, []string{GraphQLTransportWS}, func(r *http.Request, conn *wsConn) {
+		readWSMessage(conn)
+		writeWSMessage(conn, `{"type":"connection_ack"}`)
+		msg, err := readWSMessage(conn)
+		is.NoErr(err)
+		id := msg.ID
+		for {
+			msg, err := readWSMessage(conn)
+			if err != nil {
+				return
+			}
+			if msg.Type == "authenticate" {
+				writeWSMessage(conn, `{"id":"`+id+`","type":"next","payload":{"data":`+string(msg.Payload)+`}}`)
+			}
+		}
+	})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL,
+		WithConnectionInitFunc(tokens()),
+		WithSubscriptionAuthRefresh(SubscriptionAuthRefresh{Interval: 50 * time.Millisecond, MessageType: "authenticate"}))
+	subCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	payloads, _, err := client.Subscribe(subCtx, NewRequest("subscription { a }"))
+	is.NoErr(err)
+	is.Equal(string((<-payloads).Data), `{"token":"2"}`)
+	is.Equal(string((<-payloads).Data), `{"token":"3"}`)
+}